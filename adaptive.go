@@ -0,0 +1,164 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveRateConfig configures an AdaptiveRateLimiter. Rates are in requests per second and
+// zero values are replaced with the defaults noted on each field.
+type AdaptiveRateConfig struct {
+	// InitialRate is the starting rate, which defaults to MaxRate
+	InitialRate float64
+	// MinRate is the lowest rate that throttling responses will reduce to, which defaults to 1
+	MinRate float64
+	// MaxRate is the highest rate that recovery will increase to, which defaults to 100
+	MaxRate float64
+	// Increase is added to the rate for each RecoveryInterval of successful responses, which defaults to 1
+	Increase float64
+	// RecoveryInterval is the minimum time between increases of the rate, which defaults to one second
+	RecoveryInterval time.Duration
+	// DecreaseFactor multiplies the rate upon a throttling response, which defaults to 0.5
+	DecreaseFactor float64
+}
+
+// AdaptiveRateLimiter paces requests using additive-increase/multiplicative-decrease (AIMD).
+// A 429 Too Many Requests or 503 Service Unavailable response multiplicatively decreases the rate and
+// subsequent successful responses gradually increase it again. This suits shared-tenant APIs where a
+// static rate limit is either too conservative or too aggressive.
+//
+// Use the Intercept method as the Interceptor, such as
+//
+//	client.AddInterceptor(limiter.Intercept)
+type AdaptiveRateLimiter struct {
+	config AdaptiveRateConfig
+
+	mu           sync.Mutex
+	rate         float64
+	nextSlot     time.Time
+	lastIncrease time.Time
+}
+
+// NewAdaptiveRateLimiter creates an AdaptiveRateLimiter with the given configuration
+func NewAdaptiveRateLimiter(config AdaptiveRateConfig) *AdaptiveRateLimiter {
+	if config.MinRate <= 0 {
+		config.MinRate = 1
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = 100
+	}
+	if config.MaxRate < config.MinRate {
+		config.MaxRate = config.MinRate
+	}
+	if config.InitialRate <= 0 || config.InitialRate > config.MaxRate {
+		config.InitialRate = config.MaxRate
+	}
+	if config.InitialRate < config.MinRate {
+		config.InitialRate = config.MinRate
+	}
+	if config.Increase <= 0 {
+		config.Increase = 1
+	}
+	if config.RecoveryInterval <= 0 {
+		config.RecoveryInterval = time.Second
+	}
+	if config.DecreaseFactor <= 0 || config.DecreaseFactor >= 1 {
+		config.DecreaseFactor = 0.5
+	}
+	return &AdaptiveRateLimiter{
+		config: config,
+		rate:   config.InitialRate,
+	}
+}
+
+// Rate returns the current rate in requests per second
+func (l *AdaptiveRateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Intercept is an Interceptor that waits for the request's turn according to the current rate and
+// adjusts the rate based on the response status
+func (l *AdaptiveRateLimiter) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	delay := l.reserve()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		l.decrease()
+	} else if resp.StatusCode < 500 {
+		l.increase()
+	}
+	return resp, nil
+}
+
+// reserve allocates the next request slot and returns how long to wait until it
+func (l *AdaptiveRateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	slot := l.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	l.nextSlot = slot.Add(time.Duration(float64(time.Second) / l.rate))
+	return slot.Sub(now)
+}
+
+func (l *AdaptiveRateLimiter) decrease() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate *= l.config.DecreaseFactor
+	if l.rate < l.config.MinRate {
+		l.rate = l.config.MinRate
+	}
+	// recovery starts over from the point of throttling
+	l.lastIncrease = time.Now()
+}
+
+func (l *AdaptiveRateLimiter) increase() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastIncrease) < l.config.RecoveryInterval {
+		return
+	}
+	l.lastIncrease = now
+	l.rate += l.config.Increase
+	if l.rate > l.config.MaxRate {
+		l.rate = l.config.MaxRate
+	}
+}
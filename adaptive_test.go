@@ -0,0 +1,53 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleAdaptiveRateLimiter() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	limiter := restclient.NewAdaptiveRateLimiter(restclient.AdaptiveRateConfig{
+		MinRate: 5,
+		MaxRate: 40,
+	})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(limiter.Intercept)
+
+	fmt.Println(limiter.Rate())
+	for i := 0; i < 3; i++ {
+		err := client.Exchange("GET", "/busy", nil, nil, nil)
+		fmt.Println(err, limiter.Rate())
+	}
+	// Output:
+	// 40
+	// 429 Too Many Requests body=[] 20
+	// 429 Too Many Requests body=[] 10
+	// 429 Too Many Requests body=[] 5
+}
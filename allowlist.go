@@ -0,0 +1,62 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUrlNotAllowed is matched, via errors.Is, by errors of exchanges whose URL, or the URL of a
+// redirect, is not allowed by the client's AllowedHosts or RequireHTTPS
+var ErrUrlNotAllowed = errors.New("URL not allowed")
+
+func (c *Client) restrictsUrls() bool {
+	return len(c.AllowedHosts) > 0 || c.RequireHTTPS
+}
+
+func (c *Client) checkUrlAllowed(u *url.URL) error {
+	if c.RequireHTTPS && !strings.EqualFold(u.Scheme, "https") {
+		return fmt.Errorf("%w: %s is not https", ErrUrlNotAllowed, DefaultRedactionPolicy.RedactUrl(u))
+	}
+	if len(c.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range c.AllowedHosts {
+		if hostMatches(allowed, u) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %s is not in the allowed hosts", ErrUrlNotAllowed, u.Host)
+}
+
+// hostMatches determines if the URL's host matches the allowed host, which may include a port and
+// may start with "*." to match any subdomain
+func hostMatches(allowed string, u *url.URL) bool {
+	allowedUrl := &url.URL{Scheme: u.Scheme, Host: allowed}
+	if allowedUrl.Port() != "" && allowedUrl.Port() != effectivePort(u) {
+		return false
+	}
+	hostname := strings.ToLower(u.Hostname())
+	allowedName := strings.ToLower(allowedUrl.Hostname())
+	if strings.HasPrefix(allowedName, "*.") {
+		return strings.HasSuffix(hostname, allowedName[1:])
+	}
+	return hostname == allowedName
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+func ExampleClient_AllowedHosts() {
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV elsewhere")
+	}))
+	defer elsewhere.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path)
+		if r.URL.Path == "/next" {
+			http.Redirect(w, r, elsewhere.URL, http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+	tsUrl, _ := url.Parse(ts.URL)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AllowedHosts = []string{tsUrl.Host}
+
+	// such as paths or links provided by users or other APIs
+	fmt.Println(client.Exchange("GET", "/servers", nil, nil, nil))
+	err := client.Exchange("GET", elsewhere.URL+"/servers", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrUrlNotAllowed))
+	err = client.Exchange("GET", "/next", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrUrlNotAllowed))
+	// Output:
+	// RECV /servers
+	// <nil>
+	// true
+	// RECV /next
+	// true
+}
+
+func ExampleClient_RequireHTTPS() {
+	client := restclient.NewClient()
+	client.SetBaseUrl("http://localhost:8080")
+	client.RequireHTTPS = true
+
+	err := client.Exchange("GET", "/servers", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// URL not allowed: http://localhost:8080/servers is not https
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a mutating request, those other than GET, HEAD, OPTIONS, and TRACE
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Principal identifies who made the request, as given by the PrincipalAttribute of the request's
+	// context or determined by AuditConfig.Principal
+	Principal string `json:"principal,omitempty"`
+	// Operation is the name given by WithOperationName, if any
+	Operation string `json:"operation,omitempty"`
+	Method    string `json:"method"`
+	// Url is the URL of the request, redacted by the DefaultRedactionPolicy
+	Url string `json:"url"`
+	// StatusCode is the status of the response or zero if the request failed to be sent
+	StatusCode int           `json:"statusCode,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	// RequestHash is the hex-encoded SHA-256 of the request body, which is empty when the body
+	// could not be read without consuming it
+	RequestHash string `json:"requestHash,omitempty"`
+}
+
+// AuditSink receives the records of an Audit interceptor, such as to write them to a file, syslog,
+// or a collection service
+type AuditSink interface {
+	Audit(ctx context.Context, record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink
+type AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+func (f AuditSinkFunc) Audit(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// AuditConfig configures the Audit interceptor
+type AuditConfig struct {
+	// Sink receives the records and is required
+	Sink AuditSink
+	// Principal, if set, determines who made the request, such as the username of the tool's
+	// operator, when the request's context has no PrincipalAttribute
+	Principal func(req *http.Request) string
+	// OnError, if set, is called when the Sink fails to receive a record. The request is unaffected.
+	OnError func(record AuditRecord, err error)
+	// Clock determines the time of records, which defaults to SystemClock
+	Clock Clock
+}
+
+// Audit creates an Interceptor that emits an AuditRecord to the configured sink for each
+// mutating request once its response, or failure, is known. It should be added after
+// interceptors that modify the request, such as authentication, so the record reflects the
+// request that was sent.
+func Audit(config AuditConfig) Interceptor {
+	clock := config.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		if !isMutating(req.Method) {
+			return next(req)
+		}
+
+		record := AuditRecord{
+			Time:        clock.Now(),
+			Operation:   OperationFromContext(req.Context()),
+			Method:      req.Method,
+			Url:         DefaultRedactionPolicy.RedactUrl(req.URL).String(),
+			RequestHash: hashRequestBody(req),
+		}
+		if principal, ok := stringAttribute(req.Context(), PrincipalAttribute); ok {
+			record.Principal = principal
+		} else if config.Principal != nil {
+			record.Principal = config.Principal(req)
+		}
+
+		resp, err := next(req)
+		record.Duration = clock.Now().Sub(record.Time)
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.StatusCode = resp.StatusCode
+		}
+
+		if sinkErr := config.Sink.Audit(req.Context(), record); sinkErr != nil && config.OnError != nil {
+			config.OnError(record, sinkErr)
+		}
+		return resp, err
+	}
+}
+
+// hashRequestBody hashes a copy of a replayable body. Since the copy may share a reader with the
+// request's own body, such as an os.File, the request's body is then reset from another copy.
+func hashRequestBody(req *http.Request) string {
+	hash := sha256.New()
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return ""
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return ""
+		}
+		_, err = io.Copy(hash, body)
+		_ = body.Close()
+		if err != nil {
+			return ""
+		}
+		body, err = req.GetBody()
+		if err != nil {
+			return ""
+		}
+		req.Body = body
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// JsonAuditSink writes each record as a line of JSON, such as to a file or a syslog.Writer
+type JsonAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJsonAuditSink creates a JsonAuditSink that writes to w
+func NewJsonAuditSink(w io.Writer) *JsonAuditSink {
+	return &JsonAuditSink{w: w}
+}
+
+func (s *JsonAuditSink) Audit(_ context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// HttpAuditSink creates an AuditSink that posts each record as JSON to urlIn with the given client,
+// which should be distinct from the audited client and may be relative to its BaseUrl
+func HttpAuditSink(client *Client, urlIn string) AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, record AuditRecord) error {
+		err := client.ExchangeWithContext(ctx, "POST", urlIn, nil, NewJsonEntity(record), nil)
+		if err != nil {
+			return fmt.Errorf("failed to send audit record: %w", err)
+		}
+		return nil
+	})
+}
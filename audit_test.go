@@ -0,0 +1,104 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+)
+
+func ExampleAudit() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Audit(restclient.AuditConfig{
+		// such as restclient.NewJsonAuditSink(file)
+		Sink: restclient.AuditSinkFunc(func(ctx context.Context, record restclient.AuditRecord) error {
+			fmt.Println(record.Time.Format(time.RFC3339), record.Principal, record.Method,
+				strings.TrimPrefix(record.Url, ts.URL), record.StatusCode, record.RequestHash[:8])
+			return nil
+		}),
+		Principal: func(req *http.Request) string {
+			return "operator1"
+		},
+		Clock: restclienttest.NewFakeClock(time.Date(2020, 11, 3, 8, 0, 0, 0, time.UTC)),
+	}))
+
+	// not a mutation, so not audited
+	_ = client.Exchange("GET", "/servers", nil, nil, nil)
+	_ = client.Exchange("POST", "/servers", nil, restclient.NewTextEntity("web"), nil)
+	_ = client.Exchange("DELETE", "/servers/1", nil, nil, nil)
+	// Output:
+	// 2020-11-03T08:00:00Z operator1 POST /servers 200 4b5e57f6
+	// 2020-11-03T08:00:00Z operator1 DELETE /servers/1 204 e3b0c442
+}
+
+func ExampleAudit_seekableBody() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %s\n", r.ContentLength, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("web")
+	file.Seek(0, io.SeekStart)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Audit(restclient.AuditConfig{
+		Sink: restclient.AuditSinkFunc(func(ctx context.Context, record restclient.AuditRecord) error {
+			fmt.Println(record.Method, strings.TrimPrefix(record.Url, ts.URL), record.RequestHash[:8])
+			return nil
+		}),
+	}))
+
+	// hashing a body that shares its reader, such as a seekable reader, still sends the body
+	err = client.Exchange("POST", "/servers", nil,
+		restclient.NewReaderEntity(struct{ io.ReadSeeker }{file}, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// RECV 3 web
+	// POST /servers 4b5e57f6
+}
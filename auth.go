@@ -27,3 +27,58 @@ func BasicAuth(username, password string) Interceptor {
 		return next(req)
 	}
 }
+
+// TokenHeader declares the request header that conveys an authentication token, since services
+// differ in their expectation
+type TokenHeader struct {
+	Name string
+	// Prefix precedes the token in the header value, such as "Bearer "
+	Prefix string
+}
+
+var (
+	// AuthTokenHeader conveys the token in the X-Auth-Token header, as used by Rackspace and
+	// OpenStack services
+	AuthTokenHeader = TokenHeader{Name: "X-Auth-Token"}
+	// BearerTokenHeader conveys the token as a bearer token of the Authorization header
+	BearerTokenHeader = TokenHeader{Name: "Authorization", Prefix: "Bearer "}
+	// SubjectTokenHeader conveys the token in the X-Subject-Token header
+	SubjectTokenHeader = TokenHeader{Name: "X-Subject-Token"}
+)
+
+// Set sets the header of the request to the token
+func (h TokenHeader) Set(req *http.Request, token string) {
+	req.Header.Set(h.Name, h.Prefix+token)
+}
+
+// BearerToken creates an Interceptor that sets the Authorization header to a bearer token
+func BearerToken(token string) Interceptor {
+	return TokenAuth(BearerTokenHeader, token)
+}
+
+// TokenAuth creates an Interceptor that sets the given header to the token
+func TokenAuth(header TokenHeader, token string) Interceptor {
+	return func(req *http.Request, next NextCallback) (response *http.Response, e error) {
+		header.Set(req, token)
+		return next(req)
+	}
+}
+
+// ApiKeyAuth creates an Interceptor that sets the named header, such as X-Api-Key, to the given API key
+func ApiKeyAuth(headerName, value string) Interceptor {
+	return func(req *http.Request, next NextCallback) (response *http.Response, e error) {
+		req.Header.Set(headerName, value)
+		return next(req)
+	}
+}
+
+// QueryApiKeyAuth creates an Interceptor that sets the named query parameter, such as api_key,
+// of the request URL to the given API key
+func QueryApiKeyAuth(param, value string) Interceptor {
+	return func(req *http.Request, next NextCallback) (response *http.Response, e error) {
+		query := req.URL.Query()
+		query.Set(param, value)
+		req.URL.RawQuery = query.Encode()
+		return next(req)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// FaultConfig configures the FaultInjection interceptor. Each rate is a probability from 0 to 1 that
+// the fault is applied to a given request.
+type FaultConfig struct {
+	// Seed initializes the source of randomness, so that a sequence of faults can be reproduced.
+	// A zero seed uses the current time.
+	Seed int64
+	// ErrorRate is the probability of responding with a synthesized 500 Internal Server Error
+	// without sending the request
+	ErrorRate float64
+	// ResetRate is the probability of failing with a connection reset error without sending the request
+	ResetRate float64
+	// LatencyRate is the probability of delaying the request by Latency before sending it
+	LatencyRate float64
+	Latency     time.Duration
+	// TruncateRate is the probability that the response body ends early with io.ErrUnexpectedEOF
+	TruncateRate float64
+}
+
+// FaultInjection creates an Interceptor that injects failures into exchanges according to the given
+// configuration. It is intended for resilience testing, so that services using this client can
+// exercise their handling of server errors, network failures, slow responses, and partial bodies.
+//
+// Faults are evaluated in the order of latency, reset, error, and truncation.
+func FaultInjection(config FaultConfig) Interceptor {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var mu sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	roll := func(rate float64) bool {
+		if rate <= 0 {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return random.Float64() < rate
+	}
+	intn := func(n int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return random.Intn(n)
+	}
+
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		if roll(config.LatencyRate) {
+			timer := time.NewTimer(config.Latency)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+
+		if roll(config.ResetRate) {
+			return nil, &net.OpError{
+				Op:  "read",
+				Net: "tcp",
+				Err: os.NewSyscallError("read", syscall.ECONNRESET),
+			}
+		}
+
+		if roll(config.ErrorRate) {
+			body := []byte("injected fault")
+			return &http.Response{
+				Status:        "500 Internal Server Error",
+				StatusCode:    http.StatusInternalServerError,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{headerContentType: []string{string(TextType)}},
+				Body:          ioutil.NopCloser(bytes.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Request:       req,
+			}, nil
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if roll(config.TruncateRate) {
+			limit := int64(0)
+			if resp.ContentLength > 0 {
+				limit = int64(intn(int(resp.ContentLength)))
+			}
+			resp.Body = &truncatedBody{ReadCloser: resp.Body, remaining: limit}
+		}
+		return resp, nil
+	}
+}
+
+// truncatedBody fails with io.ErrUnexpectedEOF after the remaining bytes have been read
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
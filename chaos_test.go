@@ -0,0 +1,56 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+)
+
+func ExampleFaultInjection() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.FaultInjection(restclient.FaultConfig{
+		ResetRate: 1,
+	}))
+
+	err := client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(errors.Is(err, syscall.ECONNRESET))
+
+	client = restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.FaultInjection(restclient.FaultConfig{
+		ErrorRate: 1,
+	}))
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// true
+	// 500 Internal Server Error body=[injected fault]
+}
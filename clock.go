@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"time"
+)
+
+// Clock provides the current time and timers to time dependent behavior, such as token expiration
+// and retry backoff, so that it can be tested deterministically. A fake implementation is
+// provided by restclienttest.FakeClock.
+type Clock interface {
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer is a timer of a Clock that can be stopped, such as when a wait is abandoned
+type Timer interface {
+	// C returns the channel that receives the time once the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports if it was stopped before firing
+	Stop() bool
+}
+
+// TimerClock is a Clock that provides stoppable timers, which the waits of the package, such as the
+// backoff of Retry, use so that a wait abandoned due to the end of its context releases its timer.
+// The timers of After on other clocks are only released once they fire.
+type TimerClock interface {
+	Clock
+	NewTimer(d time.Duration) Timer
+}
+
+// SystemClock is the Clock backed by the time package and is used by default
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// startTimer starts a timer of the clock, whose stop must be called once the wait is over
+func startTimer(clock Clock, d time.Duration) (<-chan time.Time, func()) {
+	if timerClock, ok := clock.(TimerClock); ok {
+		timer := timerClock.NewTimer(d)
+		return timer.C(), func() { timer.Stop() }
+	}
+	return clock.After(d), func() {}
+}
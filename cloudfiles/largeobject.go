@@ -0,0 +1,254 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudfiles
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSegmentSize is the size of the segments of a large object upload
+	DefaultSegmentSize = 64 * 1024 * 1024
+	// DefaultConcurrency is the number of segments of a large object that are uploaded at once
+	DefaultConcurrency = 4
+)
+
+type uploadOptions struct {
+	segmentSize      int64
+	concurrency      int
+	segmentContainer string
+	dynamic          bool
+}
+
+// UploadOption customizes UploadLargeObject
+type UploadOption func(o *uploadOptions)
+
+// WithSegmentSize sets the size of the segments, which defaults to DefaultSegmentSize. Since each
+// segment is held in memory while it is uploaded, the memory used is about the segment size times
+// the concurrency.
+func WithSegmentSize(size int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.segmentSize = size
+	}
+}
+
+// WithConcurrency sets the number of segments uploaded at once, which defaults to DefaultConcurrency
+func WithConcurrency(concurrency int) UploadOption {
+	return func(o *uploadOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithSegmentContainer sets the container of the segments, which defaults to the object's container
+// with the suffix "_segments"
+func WithSegmentContainer(container string) UploadOption {
+	return func(o *uploadOptions) {
+		o.segmentContainer = container
+	}
+}
+
+// WithDynamicManifest writes a dynamic large object (DLO) manifest rather than a static large
+// object (SLO) manifest. The object then consists of whatever segments exist with its prefix.
+func WithDynamicManifest() UploadOption {
+	return func(o *uploadOptions) {
+		o.dynamic = true
+	}
+}
+
+// sloSegment is an entry of a static large object manifest
+type sloSegment struct {
+	Path      string `json:"path"`
+	Etag      string `json:"etag"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// UploadLargeObject uploads the content as the object in the container, which may exceed the
+// maximum size of a single object. The content is split into segments that are uploaded concurrently
+// to the segment container and then a manifest is written as the object. Content that fits in a
+// single segment is uploaded directly as the object.
+//
+// The client's BaseUrl is the storage endpoint of the account, such as
+// https://storage101.dfw1.clouddrive.com/v1/MossoCloudFS_123/, and the client is typically
+// authenticated with restclient.IdentityV2Authenticator.
+func UploadLargeObject(ctx context.Context, client *restclient.Client, container string, object string,
+	content io.Reader, opts ...UploadOption) error {
+
+	options := uploadOptions{
+		segmentSize:      DefaultSegmentSize,
+		concurrency:      DefaultConcurrency,
+		segmentContainer: container + "_segments",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.segmentSize <= 0 {
+		options.segmentSize = DefaultSegmentSize
+	}
+	if options.concurrency <= 0 {
+		options.concurrency = DefaultConcurrency
+	}
+
+	data, err := readSegment(content, options.segmentSize)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) < options.segmentSize {
+		return client.ExchangeWithContext(ctx, "PUT", objectRef(container, object), nil,
+			restclient.NewBinaryEntity(data), nil)
+	}
+
+	err = client.ExchangeWithContext(ctx, "PUT", objectRef(options.segmentContainer, ""), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create segment container: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s/%d/", object, time.Now().UnixNano())
+	var (
+		mu       sync.Mutex
+		segments []sloSegment
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	type segmentJob struct {
+		index int
+		data  []byte
+	}
+	jobs := make(chan segmentJob)
+	var wg sync.WaitGroup
+	for i := 0; i < options.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				segment, err := uploadSegment(ctx, client, options.segmentContainer,
+					fmt.Sprintf("%s%08d", prefix, job.index), job.data)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				mu.Lock()
+				segments[job.index] = segment
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for index := 0; len(data) > 0; index++ {
+		mu.Lock()
+		segments = append(segments, sloSegment{})
+		mu.Unlock()
+		select {
+		case jobs <- segmentJob{index: index, data: data}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		if int64(len(data)) < options.segmentSize {
+			break
+		}
+		data, err = readSegment(content, options.segmentSize)
+		if err != nil {
+			fail(err)
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if options.dynamic {
+		return client.ExchangeWithContext(ctx, "PUT", objectRef(container, object), nil,
+			restclient.NewBinaryEntity([]byte{}), nil,
+			restclient.WithHeader("X-Object-Manifest", objectPath(options.segmentContainer, prefix)))
+	}
+	return client.ExchangeWithContext(ctx, "PUT", objectRef(container, object),
+		url.Values{"multipart-manifest": {"put"}}, restclient.NewJsonEntity(segments), nil)
+}
+
+func uploadSegment(ctx context.Context, client *restclient.Client, container string, name string,
+	data []byte) (sloSegment, error) {
+
+	sum := md5.Sum(data)
+	etag := hex.EncodeToString(sum[:])
+	// the ETag has the server verify the integrity of the segment
+	err := client.ExchangeWithContext(ctx, "PUT", objectRef(container, name), nil,
+		restclient.NewBinaryEntity(data), nil, restclient.WithHeader("ETag", etag))
+	if err != nil {
+		return sloSegment{}, fmt.Errorf("failed to upload segment %s: %w", name, err)
+	}
+	return sloSegment{
+		Path:      "/" + container + "/" + name,
+		Etag:      etag,
+		SizeBytes: int64(len(data)),
+	}, nil
+}
+
+// readSegment reads up to size bytes, where fewer bytes indicate the end of the content
+func readSegment(content io.Reader, size int64) ([]byte, error) {
+	var buffer bytes.Buffer
+	_, err := io.CopyN(&buffer, content, size)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// objectPath is the escaped path, relative to the account, of the object in the container or of
+// the container itself when object is empty
+func objectPath(container string, object string) string {
+	path := url.PathEscape(container)
+	if object != "" {
+		parts := strings.Split(object, "/")
+		for i, part := range parts {
+			parts[i] = url.PathEscape(part)
+		}
+		path += "/" + strings.Join(parts, "/")
+	}
+	return path
+}
+
+// objectRef is the objectPath as a reference relative to the client's BaseUrl, which is explicitly
+// relative so that a container name with a colon is not parsed as the scheme of a URL
+func objectRef(container string, object string) string {
+	return "./" + objectPath(container, object)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudfiles_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/cloudfiles"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// fakeSwift stores objects in memory and assembles static large objects from their manifest
+type fakeSwift struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeSwift) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v1/AUTH_account")
+	body, _ := ioutil.ReadAll(r.Body)
+
+	if etag := r.Header.Get("ETag"); etag != "" {
+		sum := md5.Sum(body)
+		if etag != hex.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	if r.URL.Query().Get("multipart-manifest") == "put" {
+		var manifest []struct {
+			Path string
+		}
+		_ = json.Unmarshal(body, &manifest)
+		var assembled []byte
+		for _, segment := range manifest {
+			assembled = append(assembled, s.objects[segment.Path]...)
+		}
+		fmt.Printf("manifest of %d segments for %s\n", len(manifest), path)
+		body = assembled
+	}
+	s.objects[path] = body
+	w.WriteHeader(http.StatusCreated)
+}
+
+func ExampleUploadLargeObject() {
+	swift := &fakeSwift{objects: make(map[string][]byte)}
+	ts := httptest.NewServer(swift)
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL + "/v1/AUTH_account/")
+
+	content := strings.NewReader(strings.Repeat("0123456789", 10))
+	err := cloudfiles.UploadLargeObject(context.Background(), client, "backups", "db/dump.sql", content,
+		cloudfiles.WithSegmentSize(30), cloudfiles.WithConcurrency(2))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	uploaded := swift.objects["/backups/db/dump.sql"]
+	fmt.Println(len(uploaded), string(uploaded[:25]))
+	// Output:
+	// manifest of 4 segments for /backups/db/dump.sql
+	// 100 0123456789012345678901234
+}
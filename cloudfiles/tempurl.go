@@ -0,0 +1,136 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package cloudfiles provides helpers for Rackspace Cloud Files and OpenStack Swift object storage
+that build on the restclient package, such as generating TempURLs and uploading large objects.
+
+	signed, err := cloudfiles.TempUrl("GET",
+		"https://storage101.dfw1.clouddrive.com/v1/MossoCloudFS_123/backups/db.tar.gz",
+		tempUrlKey, time.Now().Add(time.Hour))
+*/
+package cloudfiles
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Digest is the HMAC digest used to sign a TempURL. The account's tempurl middleware must allow it.
+type Digest string
+
+const (
+	SHA1   Digest = "sha1"
+	SHA256 Digest = "sha256"
+	// SHA512 signatures are sent as "sha512:" and the base64 digest, since the tempurl middleware
+	// only accepts hex for the shorter digests
+	SHA512 Digest = "sha512"
+)
+
+type tempUrlOptions struct {
+	digest   Digest
+	filename string
+	inline   bool
+}
+
+// TempUrlOption customizes the URL generated by TempUrl
+type TempUrlOption func(o *tempUrlOptions)
+
+// WithDigest sets the digest of the signature, which defaults to SHA1 as is supported by all
+// deployments
+func WithDigest(digest Digest) TempUrlOption {
+	return func(o *tempUrlOptions) {
+		o.digest = digest
+	}
+}
+
+// WithFilename overrides the filename that browsers use when downloading the object
+func WithFilename(filename string) TempUrlOption {
+	return func(o *tempUrlOptions) {
+		o.filename = filename
+	}
+}
+
+// WithInline requests that browsers display the object rather than downloading it
+func WithInline() TempUrlOption {
+	return func(o *tempUrlOptions) {
+		o.inline = true
+	}
+}
+
+// TempUrl generates a pre-signed URL that allows the given method, such as GET or PUT, on the object
+// until expires without any other authentication. The objectUrl is the full URL of the object,
+// including the account path, and key is the account's or container's Temp-URL-Key metadata.
+func TempUrl(method string, objectUrl string, key string, expires time.Time, opts ...TempUrlOption) (string, error) {
+	options := tempUrlOptions{digest: SHA1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	u, err := url.Parse(objectUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse object url: %w", err)
+	}
+	// the path must be that of an object, /v1/{account}/{container}/{object}
+	if parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4); len(parts) < 4 || parts[3] == "" {
+		return "", fmt.Errorf("%s is not the url of an object", objectUrl)
+	}
+
+	var newHash func() hash.Hash
+	switch options.digest {
+	case SHA1:
+		newHash = sha1.New
+	case SHA256:
+		newHash = sha256.New
+	case SHA512:
+		newHash = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported digest %s", options.digest)
+	}
+
+	expiresValue := strconv.FormatInt(expires.Unix(), 10)
+	mac := hmac.New(newHash, []byte(key))
+	// the signature covers the unescaped path, as seen by the tempurl middleware
+	_, _ = fmt.Fprintf(mac, "%s\n%s\n%s", strings.ToUpper(method), expiresValue, u.Path)
+	var signature string
+	if options.digest == SHA512 {
+		signature = "sha512:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	} else {
+		signature = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	query := u.Query()
+	query.Set("temp_url_sig", signature)
+	query.Set("temp_url_expires", expiresValue)
+	if options.filename != "" {
+		query.Set("filename", options.filename)
+	}
+	if options.inline {
+		query.Set("inline", "")
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudfiles_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient/cloudfiles"
+	"log"
+	"time"
+)
+
+func ExampleTempUrl() {
+	signed, err := cloudfiles.TempUrl("GET",
+		"https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/object",
+		"mykey", time.Unix(1323479485, 0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(signed)
+	// Output:
+	// https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/object?temp_url_expires=1323479485&temp_url_sig=d9fc2067e52b06598421664cf6610bfc8fc431f6
+}
+
+func ExampleWithDigest() {
+	signed, err := cloudfiles.TempUrl("PUT",
+		"https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/my%20report.pdf",
+		"mykey", time.Unix(1323479485, 0),
+		cloudfiles.WithDigest(cloudfiles.SHA256), cloudfiles.WithFilename("report.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(signed)
+	// Output:
+	// https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/my%20report.pdf?filename=report.pdf&temp_url_expires=1323479485&temp_url_sig=85da3ac3aed2f844b7817d99ba2a394ec51ad5bffb8f6de0fdd8fcc246d09aa4
+}
+
+func ExampleWithDigest_sha512() {
+	signed, err := cloudfiles.TempUrl("GET",
+		"https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/object",
+		"mykey", time.Unix(1323479485, 0), cloudfiles.WithDigest(cloudfiles.SHA512))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(signed)
+	// Output:
+	// https://storage101.dfw1.clouddrive.com/v1/AUTH_account/container/object?temp_url_expires=1323479485&temp_url_sig=sha512%3AzZD1u2V0ZLDxhhrRPFrYzGf1H0yIHc6Qv8kAhpMcy4mguNI8bP99Sv4C2HOwA1OF0wJUl7vX8Mo11hv5k223fQ%3D%3D
+}
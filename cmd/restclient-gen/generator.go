@@ -0,0 +1,707 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/racker/go-restclient/openapi"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var methodOrder = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type options struct {
+	packageName string
+	clientType  string
+	// source names the specification in the generated header
+	source string
+}
+
+type generator struct {
+	spec    *openapi.Spec
+	options options
+	imports map[string]bool
+	// names holds the type names declared so far, so that inline types don't collide
+	names map[string]bool
+	// decls holds each type declaration in the order generated
+	decls []string
+	// failures maps the name of a schema type to the name of its error type
+	failures map[string]string
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	goName   string
+	goType   string
+}
+
+// generate produces the formatted source of a client for the specification
+func generate(spec *openapi.Spec, opts options) ([]byte, error) {
+	g := &generator{
+		spec:     spec,
+		options:  opts,
+		imports:  map[string]bool{"context": true, "github.com/racker/go-restclient": true, "strings": true},
+		names:    map[string]bool{opts.clientType: true},
+		failures: make(map[string]string),
+	}
+	doc := spec.Document()
+
+	components, _ := doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	schemaNames := sortedKeys(schemas)
+	for _, name := range schemaNames {
+		g.names[goName(name)] = true
+	}
+	for _, name := range schemaNames {
+		g.declareType(goName(name), schemas[name])
+	}
+
+	var methods bytes.Buffer
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, _ := g.deref(paths[path]).(map[string]interface{})
+		for _, method := range methodOrder {
+			operation, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := g.generateOperation(&methods, path, method, item, operation); err != nil {
+				return nil, fmt.Errorf("failed to generate %s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by restclient-gen from %s. DO NOT EDIT.\n\n", opts.source)
+	fmt.Fprintf(&out, "package %s\n\n", opts.packageName)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	out.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+
+	g.writeClient(&out, doc)
+	for _, decl := range g.decls {
+		out.WriteString(decl)
+		out.WriteString("\n")
+	}
+	out.Write(methods.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) writeClient(out *bytes.Buffer, doc map[string]interface{}) {
+	info, _ := doc["info"].(map[string]interface{})
+	title, _ := info["title"].(string)
+	if title == "" {
+		title = "the API"
+	}
+	name := g.options.clientType
+
+	fmt.Fprintf(out, "// %s invokes the operations of %s. An existing restclient.Client can also be wrapped\n", name, title)
+	fmt.Fprintf(out, "// directly, as long as its BaseUrl ends with a slash.\n")
+	fmt.Fprintf(out, "type %s struct {\n\t*restclient.Client\n}\n\n", name)
+	fmt.Fprintf(out, "// New%s creates a client of the API at the given base URL, such as one of the\n", name)
+	fmt.Fprintf(out, "// servers of the specification\n")
+	fmt.Fprintf(out, "func New%s(baseUrl string) (*%s, error) {\n", name, name)
+	out.WriteString("\t// operation paths are resolved relative to the base URL\n")
+	out.WriteString("\tif !strings.HasSuffix(baseUrl, \"/\") {\n\t\tbaseUrl += \"/\"\n\t}\n")
+	out.WriteString("\tclient := restclient.NewClient()\n")
+	out.WriteString("\tif err := client.SetBaseUrl(baseUrl); err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(out, "\treturn &%s{Client: client}, nil\n}\n\n", name)
+}
+
+// declareType declares a named type for the schema
+func (g *generator) declareType(name string, node interface{}) {
+	schema, _ := node.(map[string]interface{})
+	var decl bytes.Buffer
+	writeDocComment(&decl, name, schema)
+
+	if enum, ok := schema["enum"].([]interface{}); ok && schema["type"] == "string" {
+		fmt.Fprintf(&decl, "type %s string\n\nconst (\n", name)
+		for _, value := range enum {
+			s, _ := value.(string)
+			fmt.Fprintf(&decl, "\t%s%s %s = %q\n", name, enumName(s), name, s)
+		}
+		decl.WriteString(")\n")
+	} else if isObject(schema) {
+		fmt.Fprintf(&decl, "type %s struct {\n", name)
+		g.writeFields(&decl, name, schema)
+		decl.WriteString("}\n")
+	} else {
+		fmt.Fprintf(&decl, "type %s %s\n", name, g.typeOf(schema, name+"Item"))
+	}
+	g.decls = append(g.decls, decl.String())
+}
+
+func (g *generator) writeFields(decl *bytes.Buffer, structName string, schema map[string]interface{}) {
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, member := range allOf {
+			memberSchema, _ := member.(map[string]interface{})
+			if ref, ok := memberSchema["$ref"].(string); ok {
+				fmt.Fprintf(decl, "\t%s\n", refName(ref))
+			} else {
+				g.writeFields(decl, structName, memberSchema)
+			}
+		}
+	}
+
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, r := range list {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, property := range sortedKeys(properties) {
+		propertySchema, _ := properties[property].(map[string]interface{})
+		fieldName := goName(property)
+		fieldType := g.typeOf(propertySchema, structName+fieldName)
+		tag := property
+		if !required[property] {
+			tag += ",omitempty"
+			if g.isStruct(propertySchema) {
+				fieldType = "*" + fieldType
+			}
+		}
+		if description, ok := propertySchema["description"].(string); ok {
+			writeComment(decl, "\t", description)
+		}
+		fmt.Fprintf(decl, "\t%s %s `json:%q`\n", fieldName, fieldType, tag)
+	}
+}
+
+// typeOf maps the schema to a Go type, declaring a type named by the hint for inline object schemas
+func (g *generator) typeOf(schema map[string]interface{}, hint string) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return refName(ref)
+	}
+	switch schema["type"] {
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if schema["format"] == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema["format"] == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return "[]" + g.typeOf(items, hint+"Item")
+	}
+
+	if isObject(schema) {
+		name := g.uniqueName(hint)
+		g.declareType(name, schema)
+		return name
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+		return "map[string]" + g.typeOf(additional, hint+"Value")
+	}
+	if schema["type"] == "object" {
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// isStruct determines if the schema maps to a struct, directly or by reference
+func (g *generator) isStruct(schema map[string]interface{}) bool {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, _ := g.spec.Resolve(ref).(map[string]interface{})
+		return isObject(resolved)
+	}
+	return isObject(schema)
+}
+
+func isObject(schema map[string]interface{}) bool {
+	if _, ok := schema["properties"]; ok {
+		return true
+	}
+	_, ok := schema["allOf"]
+	return ok
+}
+
+func (g *generator) uniqueName(hint string) string {
+	name := hint
+	for i := 2; g.names[name]; i++ {
+		name = hint + strconv.Itoa(i)
+	}
+	g.names[name] = true
+	return name
+}
+
+func (g *generator) generateOperation(out *bytes.Buffer, path string, method string,
+	item map[string]interface{}, operation map[string]interface{}) error {
+
+	name := operationName(path, method, operation)
+	params := g.parameters(name, item, operation)
+
+	var args []string
+	var pathParams, requiredQuery, optionalQuery []parameter
+	for _, p := range params {
+		switch {
+		case p.in == "path":
+			pathParams = append(pathParams, p)
+		case p.in == "query" && p.required:
+			requiredQuery = append(requiredQuery, p)
+		case p.in == "query":
+			optionalQuery = append(optionalQuery, p)
+		}
+	}
+	args = append(args, "ctx context.Context")
+	for _, p := range append(pathParams, requiredQuery...) {
+		args = append(args, p.goName+" "+p.goType)
+	}
+
+	paramsType := ""
+	if len(optionalQuery) > 0 {
+		paramsType = g.uniqueName(name + "Params")
+		g.declareParams(paramsType, name, optionalQuery)
+		args = append(args, "params *"+paramsType)
+	}
+
+	reqIn := "nil"
+	if requestBody, ok := g.deref(operation["requestBody"]).(map[string]interface{}); ok {
+		content, _ := requestBody["content"].(map[string]interface{})
+		if media, ok := content["application/json"].(map[string]interface{}); ok {
+			schema, _ := media["schema"].(map[string]interface{})
+			bodyType := g.typeOf(schema, name+"Request")
+			if g.isStruct(schema) {
+				bodyType = "*" + bodyType
+			}
+			args = append(args, "body "+bodyType)
+			reqIn = "restclient.NewJsonEntity(body)"
+		} else {
+			args = append(args, "body *restclient.Entity")
+			reqIn = "body"
+		}
+	}
+
+	responses, _ := operation["responses"].(map[string]interface{})
+	resultType, resultSchema := g.successResult(name, responses)
+	returns := "error"
+	errorReturn := "return "
+	if resultType != "" {
+		returnType := resultType
+		if g.isStruct(resultSchema) {
+			returnType = "*" + resultType
+		}
+		returns = "(" + returnType + ", error)"
+		if strings.HasPrefix(returnType, "*") || strings.HasPrefix(returnType, "[]") ||
+			strings.HasPrefix(returnType, "map[") {
+			errorReturn = "return nil, "
+		} else {
+			errorReturn = "return result, "
+		}
+	}
+
+	fmt.Fprintf(out, "// %s invokes %s %s\n", name, strings.ToUpper(method), path)
+	summary, _ := operation["summary"].(string)
+	if summary == "" {
+		summary, _ = operation["description"].(string)
+	}
+	if summary != "" {
+		out.WriteString("//\n")
+		writeComment(out, "", summary)
+	}
+	if deprecated, _ := operation["deprecated"].(bool); deprecated {
+		out.WriteString("//\n// Deprecated: the operation is deprecated by the API.\n")
+	}
+	fmt.Fprintf(out, "func (c *%s) %s(%s) %s {\n", g.options.clientType, name, strings.Join(args, ", "), returns)
+
+	// query
+	query := "nil"
+	if len(requiredQuery) > 0 || paramsType != "" {
+		g.imports["net/url"] = true
+		query = "query"
+		out.WriteString("\tquery := url.Values{}\n")
+		if paramsType != "" {
+			out.WriteString("\tif params != nil {\n\t\tquery = params.values()\n\t}\n")
+		}
+		for _, p := range requiredQuery {
+			g.writeQueryValue(out, "\t", p, p.goName)
+		}
+	}
+
+	respOut := "nil"
+	if resultType != "" {
+		fmt.Fprintf(out, "\tvar result %s\n", resultType)
+		respOut = "restclient.NewJsonEntity(&result)"
+	}
+
+	fmt.Fprintf(out, "\terr := c.ExchangeWithContext(ctx, %q, %s, %s, %s, %s)\n",
+		strings.ToUpper(method), g.pathExpression(path, pathParams), query, reqIn, respOut)
+	out.WriteString("\tif err != nil {\n")
+	g.writeFailures(out, responses, errorReturn)
+	fmt.Fprintf(out, "\t\t%serr\n\t}\n", errorReturn)
+	if resultType == "" {
+		out.WriteString("\treturn nil\n}\n\n")
+	} else if g.isStruct(resultSchema) {
+		out.WriteString("\treturn &result, nil\n}\n\n")
+	} else {
+		out.WriteString("\treturn result, nil\n}\n\n")
+	}
+	return nil
+}
+
+// parameters merges the path item's parameters with the operation's, where the latter take precedence
+func (g *generator) parameters(operationName string, item map[string]interface{},
+	operation map[string]interface{}) []parameter {
+
+	var params []parameter
+	index := make(map[string]int)
+	for _, source := range []interface{}{item["parameters"], operation["parameters"]} {
+		list, _ := source.([]interface{})
+		for _, node := range list {
+			param, ok := g.deref(node).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			required, _ := param["required"].(bool)
+			schema, _ := param["schema"].(map[string]interface{})
+			p := parameter{
+				name:     name,
+				in:       in,
+				required: required || in == "path",
+				goName:   identifier(lowerFirst(goName(name))),
+				goType:   g.typeOf(schema, operationName+goName(name)),
+			}
+			if i, exists := index[in+":"+name]; exists {
+				params[i] = p
+			} else {
+				index[in+":"+name] = len(params)
+				params = append(params, p)
+			}
+		}
+	}
+	return params
+}
+
+func (g *generator) declareParams(typeName string, operationName string, params []parameter) {
+	var decl bytes.Buffer
+	fmt.Fprintf(&decl, "// %s are the optional query parameters of %s\n", typeName, operationName)
+	fmt.Fprintf(&decl, "type %s struct {\n", typeName)
+	for _, p := range params {
+		if strings.HasPrefix(p.goType, "[]") {
+			fmt.Fprintf(&decl, "\t%s %s\n", goName(p.name), p.goType)
+		} else {
+			fmt.Fprintf(&decl, "\t%s *%s\n", goName(p.name), p.goType)
+		}
+	}
+	decl.WriteString("}\n\n")
+
+	fmt.Fprintf(&decl, "func (p *%s) values() url.Values {\n\tquery := url.Values{}\n", typeName)
+	for _, p := range params {
+		field := "p." + goName(p.name)
+		if strings.HasPrefix(p.goType, "[]") {
+			g.writeQueryValue(&decl, "\t", p, field)
+		} else {
+			fmt.Fprintf(&decl, "\tif %s != nil {\n", field)
+			g.writeQueryValue(&decl, "\t\t", p, "*"+field)
+			decl.WriteString("\t}\n")
+		}
+	}
+	decl.WriteString("\treturn query\n}\n")
+	g.decls = append(g.decls, decl.String())
+}
+
+func (g *generator) writeQueryValue(out *bytes.Buffer, indent string, p parameter, expr string) {
+	if strings.HasPrefix(p.goType, "[]") {
+		fmt.Fprintf(out, "%sfor _, v := range %s {\n", indent, expr)
+		fmt.Fprintf(out, "%s\tquery.Add(%q, %s)\n", indent, p.name, g.formatValue(strings.TrimPrefix(p.goType, "[]"), "v"))
+		fmt.Fprintf(out, "%s}\n", indent)
+	} else {
+		fmt.Fprintf(out, "%squery.Set(%q, %s)\n", indent, p.name, g.formatValue(p.goType, expr))
+	}
+}
+
+// formatValue produces the expression that converts the value to a string
+func (g *generator) formatValue(goType string, expr string) string {
+	switch goType {
+	case "string":
+		return expr
+	case "time.Time":
+		return expr + ".Format(time.RFC3339)"
+	default:
+		g.imports["fmt"] = true
+		return "fmt.Sprint(" + expr + ")"
+	}
+}
+
+// pathExpression produces the expression of the operation's path relative to the base URL
+func (g *generator) pathExpression(path string, pathParams []parameter) string {
+	types := make(map[string]parameter)
+	for _, p := range pathParams {
+		types[p.name] = p
+	}
+
+	var parts []string
+	literal := strings.TrimPrefix(path, "/")
+	for {
+		start := strings.Index(literal, "{")
+		end := strings.Index(literal, "}")
+		if start < 0 || end < start {
+			break
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(literal[:start]))
+		}
+		p := types[literal[start+1:end]]
+		g.imports["net/url"] = true
+		parts = append(parts, "url.PathEscape("+g.formatValue(p.goType, p.goName)+")")
+		literal = literal[end+1:]
+	}
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// successResult determines the type of the first successful response with JSON content
+func (g *generator) successResult(operationName string, responses map[string]interface{}) (string, map[string]interface{}) {
+	for _, code := range sortedKeys(responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if schema := g.jsonSchema(responses[code]); schema != nil {
+			return g.typeOf(schema, operationName+"Response"), schema
+		}
+	}
+	return "", nil
+}
+
+// writeFailures converts failed responses documented with a referenced schema into typed errors
+func (g *generator) writeFailures(out *bytes.Buffer, responses map[string]interface{}, errorReturn string) {
+	var cases []string
+	var defaultCase string
+	codes := sortedKeys(responses)
+	// exact status codes are matched before ranges, such as 4XX
+	sort.SliceStable(codes, func(i, j int) bool {
+		return !strings.HasSuffix(codes[i], "XX") && strings.HasSuffix(codes[j], "XX")
+	})
+	for _, code := range codes {
+		if strings.HasPrefix(code, "1") || strings.HasPrefix(code, "2") || strings.HasPrefix(code, "3") {
+			continue
+		}
+		schema := g.jsonSchema(responses[code])
+		ref, _ := schema["$ref"].(string)
+		if ref == "" {
+			continue
+		}
+		constructor := "new" + g.failureType(refName(ref))
+		switch {
+		case code == "default":
+			defaultCase = constructor
+		case strings.HasSuffix(code, "XX"):
+			cases = append(cases, fmt.Sprintf("failure.StatusCode/100 == %s", code[:1]), constructor)
+		default:
+			cases = append(cases, fmt.Sprintf("failure.StatusCode == %s", code), constructor)
+		}
+	}
+	if len(cases) == 0 && defaultCase == "" {
+		return
+	}
+
+	g.imports["errors"] = true
+	out.WriteString("\t\tvar failure *restclient.FailedResponseError\n")
+	out.WriteString("\t\tif errors.As(err, &failure) {\n\t\t\tswitch {\n")
+	for i := 0; i < len(cases); i += 2 {
+		fmt.Fprintf(out, "\t\t\tcase %s:\n\t\t\t\t%s%s(failure)\n", cases[i], errorReturn, cases[i+1])
+	}
+	if defaultCase != "" {
+		fmt.Fprintf(out, "\t\t\tdefault:\n\t\t\t\t%s%s(failure)\n", errorReturn, defaultCase)
+	}
+	out.WriteString("\t\t\t}\n\t\t}\n")
+}
+
+// failureType declares, once, the error type conveying the given body type of failed responses
+func (g *generator) failureType(bodyType string) string {
+	if name, ok := g.failures[bodyType]; ok {
+		return name
+	}
+	name := g.uniqueName(bodyType + "Failure")
+	g.failures[bodyType] = name
+	g.imports["encoding/json"] = true
+
+	var decl bytes.Buffer
+	fmt.Fprintf(&decl, "// %s is returned for failed responses whose body is described by %s\n", name, bodyType)
+	fmt.Fprintf(&decl, "type %s struct {\n\t*restclient.FailedResponseError\n\tBody %s\n}\n\n", name, bodyType)
+	fmt.Fprintf(&decl, "func (e *%s) Unwrap() error {\n\treturn e.FailedResponseError\n}\n\n", name)
+	fmt.Fprintf(&decl, "func new%s(failure *restclient.FailedResponseError) error {\n", name)
+	fmt.Fprintf(&decl, "\ttyped := &%s{FailedResponseError: failure}\n", name)
+	decl.WriteString("\tcontent, _ := failure.Entity.Content.([]byte)\n")
+	decl.WriteString("\tif err := json.Unmarshal(content, &typed.Body); err != nil {\n\t\treturn failure\n\t}\n")
+	decl.WriteString("\treturn typed\n}\n")
+	g.decls = append(g.decls, decl.String())
+	return name
+}
+
+// jsonSchema locates the schema of the response's JSON content, if any
+func (g *generator) jsonSchema(node interface{}) map[string]interface{} {
+	response, _ := g.deref(node).(map[string]interface{})
+	content, _ := response["content"].(map[string]interface{})
+	for _, mediaType := range sortedKeys(content) {
+		if strings.Contains(mediaType, "json") {
+			media, _ := content[mediaType].(map[string]interface{})
+			schema, _ := media["schema"].(map[string]interface{})
+			return schema
+		}
+	}
+	return nil
+}
+
+// deref resolves a node that is a reference object, other than schemas which become named types
+func (g *generator) deref(node interface{}) interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if ref, ok := m["$ref"].(string); ok {
+			return g.spec.Resolve(ref)
+		}
+	}
+	return node
+}
+
+func operationName(path string, method string, operation map[string]interface{}) string {
+	if id, ok := operation["operationId"].(string); ok && id != "" {
+		return goName(id)
+	}
+	name := goName(method)
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name += "By" + goName(segment[1:len(segment)-1])
+		} else {
+			name += goName(segment)
+		}
+	}
+	return name
+}
+
+// goName converts a name, such as "server_id" or "list-servers", into an exported Go name
+func goName(name string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("N")
+		}
+		if upperNext {
+			b.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// enumName converts an enumerated value into a Go name, where upper case values, such as "ACTIVE",
+// become "Active"
+func enumName(value string) string {
+	if strings.ToUpper(value) == value {
+		value = strings.ToLower(value)
+	}
+	return goName(value)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// identifier avoids Go keywords and the names used within generated methods
+func identifier(name string) string {
+	switch {
+	case token.IsKeyword(name):
+		return name + "_"
+	case name == "ctx" || name == "query" || name == "params" || name == "body" || name == "result" ||
+		name == "err" || name == "failure":
+		return name + "Param"
+	}
+	return name
+}
+
+func refName(ref string) string {
+	return goName(ref[strings.LastIndex(ref, "/")+1:])
+}
+
+func writeDocComment(out *bytes.Buffer, name string, schema map[string]interface{}) {
+	description, _ := schema["description"].(string)
+	if description == "" {
+		description, _ = schema["title"].(string)
+	}
+	if description == "" {
+		fmt.Fprintf(out, "// %s is generated from the specification\n", name)
+	} else {
+		writeComment(out, "", name+" "+lowerFirst(description))
+	}
+}
+
+func writeComment(out *bytes.Buffer, indent string, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(out, "%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"github.com/racker/go-restclient/openapi"
+	"github.com/racker/go-restclient/restclienttest"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	spec, err := openapi.Load("testdata/servers.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := generate(spec, options{
+		packageName: "servers",
+		clientType:  "Client",
+		source:      "servers.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const goldenFile = "testdata/servers.go.golden"
+	if os.Getenv(restclienttest.UpdateGoldenEnv) != "" {
+		if err := ioutil.WriteFile(goldenFile, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, code) {
+		t.Errorf("generated code differs from %s, re-run with %s=1 to update:\n%s",
+			goldenFile, restclienttest.UpdateGoldenEnv, code)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"server_id":    "ServerId",
+		"list-servers": "ListServers",
+		"getServer":    "GetServer",
+		"2fa":          "N2fa",
+	}
+	for in, expected := range tests {
+		if actual := goName(in); actual != expected {
+			t.Errorf("goName(%q) = %q, expected %q", in, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Command restclient-gen generates a typed Go client from an OpenAPI 3 specification.
+
+The generated code builds upon restclient.Client and includes:
+
+  - a struct, slice, or enumerated string type for each schema of the specification's components
+    and for inline object schemas
+  - a method per operation with typed path parameters, required query parameters, and request body
+    as arguments, a Params struct for the optional query parameters, and a typed result decoded
+    from the operation's successful JSON response
+  - an error type per schema referenced by error responses, which conveys the decoded body and wraps
+    the restclient.FailedResponseError
+
+Operations are named by their operationId or, when absent, by their method and path. Only JSON
+request and response bodies are typed; other request bodies are given as a *restclient.Entity.
+Header and cookie parameters are not included.
+
+Usage:
+
+	restclient-gen -spec openapi.yaml -package servers -out servers/client.go
+
+It can also be invoked via go:generate, such as
+
+	//go:generate restclient-gen -spec ../api/openapi.yaml -package servers -out client.go
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/racker/go-restclient/openapi"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	specFile := flag.String("spec", "", "the OpenAPI 3 specification, in JSON or YAML form")
+	pkg := flag.String("package", "api", "the package name of the generated code")
+	clientType := flag.String("client", "Client", "the name of the generated client type")
+	out := flag.String("out", "", "the file to write, otherwise the generated code is written to stdout")
+	flag.Parse()
+
+	if *specFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	spec, err := openapi.Load(*specFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code, err := generate(spec, options{
+		packageName: *pkg,
+		clientType:  *clientType,
+		source:      filepath.Base(*specFile),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(code)
+	} else {
+		err = ioutil.WriteFile(*out, code, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sync"
+)
+
+// Codec encodes request content and decodes response content for a particular MimeType.
+// Codecs are registered with RegisterCodec.
+type Codec interface {
+	// Encode writes the encoded form of content to w
+	Encode(w io.Writer, content interface{}) error
+	// Decode reads from r and decodes into the value referenced by content
+	Decode(r io.Reader, content interface{}) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[MimeType]Codec{
+		JsonType: JsonCodec{},
+	}
+)
+
+// RegisterCodec registers the codec to use for entities of the given content type.
+// A codec registered for JsonType replaces the default encoding/json based codec.
+//
+// Packages providing codecs typically call this from an init function, so that the codec is
+// enabled by importing the package.
+func RegisterCodec(contentType MimeType, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[contentType] = codec
+}
+
+func lookupCodec(contentType MimeType) Codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	return codecs[contentType]
+}
+
+// JsonArrayItemHandler can be used as the content of a JsonType response entity when the response
+// is a JSON array. Rather than decoding the entire array into memory, the handler is invoked for each
+// element of the array and decode should be called to decode that element into the given reference.
+// An element is skipped when the handler returns without calling decode. Returning an error stops
+// the processing and is returned from the exchange.
+type JsonArrayItemHandler func(decode func(v interface{}) error) error
+
+// JsonCodec is the JSON codec registered for JsonType by default, which uses encoding/json. It can
+// be registered with options, such as
+//
+//	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{UseNumber: true})
+//
+// Alternative JSON implementations, such as jsoniter, go-json, or segmentio/encoding, can be plugged
+// into hot paths via Marshal and Unmarshal since they share the signatures of encoding/json, such as
+//
+//	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{
+//		Marshal:   jsoniter.ConfigCompatibleWithStandardLibrary.Marshal,
+//		Unmarshal: jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal,
+//	})
+type JsonCodec struct {
+	// UseNumber decodes numbers into interface{} values, such as those of a map[string]interface{},
+	// as json.Number rather than float64, which avoids the loss of precision of large IDs.
+	// It only applies when Unmarshal is not set.
+	UseNumber bool
+	// Marshal, when set, replaces encoding/json for encoding content
+	Marshal func(v interface{}) ([]byte, error)
+	// Unmarshal, when set, replaces encoding/json for decoding content. Since it is given the
+	// entire body, content that is a JsonArrayItemHandler is still streamed with encoding/json.
+	Unmarshal func(data []byte, v interface{}) error
+	// Canonical encodes content as canonical JSON, as given by CanonicalJson, so that the same
+	// content always produces the same bytes, such as for bodies that are signed or hashed
+	Canonical bool
+	// FieldNaming, when set, names the struct fields that have no name in their json tag, such as
+	// SnakeCase. Since the content is re-encoded after renaming, object keys are sorted. Content
+	// that is a JsonArrayItemHandler is decoded without renaming.
+	FieldNaming FieldNaming
+}
+
+func (c JsonCodec) Encode(w io.Writer, content interface{}) error {
+	if c.Canonical || c.FieldNaming != nil {
+		marshal := c.Marshal
+		if marshal == nil {
+			marshal = json.Marshal
+		}
+		b, err := marshal(content)
+		if err != nil {
+			return err
+		}
+		if c.FieldNaming != nil {
+			b, err = renameJsonFields(b, reflect.TypeOf(content), c.FieldNaming, true)
+			if err != nil {
+				return err
+			}
+		}
+		if c.Canonical {
+			b, err = canonicalizeJson(b)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	if c.Marshal != nil {
+		b, err := c.Marshal(content)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return json.NewEncoder(w).Encode(content)
+}
+
+// CanonicalJson encodes the content as canonical JSON, where object keys are sorted, including
+// those of struct fields, there is no insignificant whitespace, HTML characters are not escaped,
+// and numbers retain their encoded form
+func CanonicalJson(content interface{}) ([]byte, error) {
+	b, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalizeJson(b)
+}
+
+func canonicalizeJson(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	// maps, unlike structs, are encoded with sorted keys
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+func (c JsonCodec) Decode(r io.Reader, content interface{}) error {
+	handler, isHandler := content.(JsonArrayItemHandler)
+	if c.FieldNaming != nil && !isHandler {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		b, err = renameJsonFields(b, reflect.TypeOf(content), c.FieldNaming, false)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	if c.Unmarshal != nil && !isHandler {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return c.Unmarshal(b, content)
+	}
+
+	decoder := json.NewDecoder(r)
+	if c.UseNumber {
+		decoder.UseNumber()
+	}
+	if isHandler {
+		return decodeJsonArray(decoder, handler)
+	}
+	return decoder.Decode(content)
+}
+
+func decodeJsonArray(decoder *json.Decoder, handler JsonArrayItemHandler) error {
+	if err := expectJsonDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		decoded := false
+		decode := func(v interface{}) error {
+			decoded = true
+			return decoder.Decode(v)
+		}
+		if err := handler(decode); err != nil {
+			return err
+		}
+		if !decoded {
+			// the element must still be consumed to advance to the next one
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+		}
+	}
+	return expectJsonDelim(decoder, ']')
+}
+
+func expectJsonDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %s in JSON array but got %v", expected, token)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func ExampleJsonArrayItemHandler() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"Id":1,"Name":"first"},{"Id":2,"Name":"second"}]`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Row struct {
+		Id   int
+		Name string
+	}
+
+	err := client.Exchange("GET", "/export", nil, nil,
+		restclient.NewJsonEntity(restclient.JsonArrayItemHandler(func(decode func(v interface{}) error) error {
+			var row Row
+			if err := decode(&row); err != nil {
+				return err
+			}
+			fmt.Printf("%+v\n", row)
+			return nil
+		})))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// {Id:1 Name:first}
+	// {Id:2 Name:second}
+}
+
+func ExampleJsonArrayItemHandler_skip() {
+	body := []byte(`[{"Id":1},{"Id":2},{"Id":3}]`)
+
+	// only the first element is decoded and the rest are skipped
+	count := 0
+	err := restclient.JsonCodec{}.Decode(bytes.NewReader(body),
+		restclient.JsonArrayItemHandler(func(decode func(v interface{}) error) error {
+			count++
+			if count > 1 {
+				return nil
+			}
+			var row struct{ Id int }
+			if err := decode(&row); err != nil {
+				return err
+			}
+			fmt.Printf("%+v\n", row)
+			return nil
+		}))
+	fmt.Println(count, err)
+	// Output:
+	// {Id:1}
+	// 3 <nil>
+}
+
+func ExampleJsonCodec() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":9007199254740993,"name":"big"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{UseNumber: true})
+	// restores the default for the other examples
+	defer restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resp map[string]interface{}
+	err := client.Exchange("GET", "/resource", nil, nil,
+		restclient.NewJsonEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp["id"])
+	// Output:
+	// 9007199254740993
+}
+
+func ExampleCanonicalJson() {
+	type Server struct {
+		Name     string            `json:"name"`
+		Flavor   string            `json:"flavor"`
+		Metadata map[string]string `json:"metadata"`
+	}
+	content, err := restclient.CanonicalJson(&Server{
+		Name:     "<web>",
+		Flavor:   "general1-1",
+		Metadata: map[string]string{"role": "web", "environment": "production"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(content))
+	// Output:
+	// {"flavor":"general1-1","metadata":{"environment":"production","role":"web"},"name":"<web>"}
+}
+
+func ExampleJsonCodec_canonical() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println(string(body))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{Canonical: true})
+	// restores the default for the other examples
+	defer restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	err := client.Exchange("POST", "/servers", nil,
+		restclient.NewJsonEntity(struct {
+			Ratio float64 `json:"ratio"`
+			Name  string  `json:"name"`
+		}{Ratio: 1.5, Name: "web"}), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// {"name":"web","ratio":1.5}
+}
+
+func ExampleJsonCodec_fieldNaming() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println(string(body))
+		fmt.Fprint(w, `{"server_id":"123","ipv4_address":"10.0.0.1","flavor":{"ram_mb":1024}}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{FieldNaming: restclient.SnakeCase})
+	// restores the default for the other examples
+	defer restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{})
+
+	type Flavor struct {
+		RamMB int
+	}
+	type Server struct {
+		ServerID    string
+		Ipv4Address string
+		Flavor      Flavor
+		Name        string `json:"displayName,omitempty"`
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var created Server
+	err := client.Exchange("POST", "/servers", nil,
+		restclient.NewJsonEntity(&Server{Name: "web", Flavor: Flavor{RamMB: 1024}}),
+		restclient.NewJsonEntity(&created))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%+v\n", created)
+	// Output:
+	// {"displayName":"web","flavor":{"ram_mb":1024},"ipv4_address":"","server_id":""}
+	// {ServerID:123 Ipv4Address:10.0.0.1 Flavor:{RamMB:1024} Name:}
+}
+
+func ExampleFieldNaming() {
+	for _, name := range []string{"UserID", "HTTPServer", "Ipv4Address", "Name"} {
+		fmt.Println(restclient.SnakeCase(name), restclient.CamelCase(name))
+	}
+	// Output:
+	// user_id userID
+	// http_server httpServer
+	// ipv4_address ipv4Address
+	// name name
+}
+
+type benchmarkServer struct {
+	Id        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Progress  int               `json:"progress"`
+	Addresses []string          `json:"addresses"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// largeEntity is a typical large listing of a few hundred KB when encoded
+func largeEntity() []benchmarkServer {
+	servers := make([]benchmarkServer, 1000)
+	for i := range servers {
+		servers[i] = benchmarkServer{
+			Id:        fmt.Sprintf("c3f1a3e2-%04d-4a7b-9d3e-5a8f0e2b7c61", i),
+			Name:      fmt.Sprintf("web-%04d.example.com", i),
+			Status:    "ACTIVE",
+			Progress:  100,
+			Addresses: []string{"10.0.0.1", "2001:db8::1"},
+			Metadata:  map[string]string{"environment": "production", "role": "web"},
+		}
+	}
+	return servers
+}
+
+// benchmarkCodecs are the JSON codecs compared by the benchmarks, the default streaming codec and
+// one plugged in through Marshal and Unmarshal. An alternative implementation, such as jsoniter, is
+// compared by adding it here with its Marshal and Unmarshal.
+var benchmarkCodecs = []struct {
+	name  string
+	codec restclient.JsonCodec
+}{
+	{"default", restclient.JsonCodec{}},
+	{"marshal", restclient.JsonCodec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}},
+}
+
+func BenchmarkJsonCodec_Encode(b *testing.B) {
+	content := largeEntity()
+	for _, bc := range benchmarkCodecs {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bc.codec.Encode(ioutil.Discard, content); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkJsonCodec_Decode(b *testing.B) {
+	encoded, err := json.Marshal(largeEntity())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, bc := range benchmarkCodecs {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(encoded)))
+			for i := 0; i < b.N; i++ {
+				var content []benchmarkServer
+				if err := bc.codec.Decode(bytes.NewReader(encoded), &content); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkJsonCodec_exchange decodes the large listing through Client.Exchange with each of the
+// registered codecs and, for reference, through a raw http.Client call
+func BenchmarkJsonCodec_exchange(b *testing.B) {
+	encoded, err := json.Marshal(largeEntity())
+	if err != nil {
+		b.Fatal(err)
+	}
+	// restores the default for the other benchmarks and examples
+	defer restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{})
+
+	for _, bc := range benchmarkCodecs {
+		b.Run(bc.name, func(b *testing.B) {
+			restclient.RegisterCodec(restclient.JsonType, bc.codec)
+			client := benchmarkClient(http.StatusOK, encoded)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(encoded)))
+			for i := 0; i < b.N; i++ {
+				var content []benchmarkServer
+				err := client.Exchange("GET", "/servers", nil, nil, restclient.NewJsonEntity(&content))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("http", func(b *testing.B) {
+		httpClient := benchmarkClient(http.StatusOK, encoded).HttpClient
+		b.ReportAllocs()
+		b.SetBytes(int64(len(encoded)))
+		for i := 0; i < b.N; i++ {
+			resp, err := httpClient.Get("http://localhost/servers")
+			if err != nil {
+				b.Fatal(err)
+			}
+			var content []benchmarkServer
+			err = json.NewDecoder(resp.Body).Decode(&content)
+			_ = resp.Body.Close()
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrConflict is matched, via errors.Is, by the FailedResponseError of a 412 Precondition Failed
+// response, which indicates that a conditional request, such as one given WithIfMatch, lost a race
+// with a concurrent modification of the resource
+var ErrConflict = errors.New("conflicting modification")
+
+// WithETag populates etag with the ETag header of the response, which is typically given to
+// a subsequent update of the resource via WithIfMatch
+func WithETag(etag *string) RequestOption {
+	return func(o *requestOptions) {
+		o.etag = etag
+	}
+}
+
+// WithIfMatch makes the request conditional upon the resource still having the given entity tag.
+// If the resource was modified in the meantime, the exchange fails with an error matching ErrConflict.
+func WithIfMatch(etag string) RequestOption {
+	return func(o *requestOptions) {
+		if etag != "" {
+			o.setHeader("If-Match", etag)
+		}
+	}
+}
+
+// ConditionalUpdate performs an optimistic concurrency loop on the resource at urlIn. The resource is
+// retrieved into the content of resource, modify is called to apply the changes to that content,
+// and then the content is sent with the given method, such as PUT, conditional on the ETag retrieved.
+// If the update conflicts with a concurrent modification, the loop is repeated up to maxAttempts times,
+// where values below 1 make a single attempt. Returning an error from modify stops the loop and is
+// returned.
+//
+// The resource entity is used both for retrieving and updating, so its content should be a reference,
+// such as from NewJsonEntity(&server). The referenced value is reset to its zero value before each
+// retrieval, so that a repeated attempt starts from the latest resource rather than merging it into
+// the changes of the conflicting attempt.
+func (c *Client) ConditionalUpdate(ctx context.Context, method string, urlIn string, resource *Entity,
+	maxAttempts int, modify func() error, opts ...RequestOption) error {
+
+	// limits the capacity, so that appending options doesn't modify the caller's slice
+	opts = opts[:len(opts):len(opts)]
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var etag string
+		resetContent(resource.Content)
+		err = c.ExchangeWithContext(ctx, "GET", urlIn, nil, nil, resource, append(opts, WithETag(&etag))...)
+		if err != nil {
+			return err
+		}
+		if etag == "" {
+			return fmt.Errorf("resource at %s did not provide an ETag", urlIn)
+		}
+
+		if err := modify(); err != nil {
+			return err
+		}
+
+		err = c.ExchangeWithContext(ctx, method, urlIn, nil, resource, nil, append(opts, WithIfMatch(etag))...)
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("gave up after %d attempts: %w", maxAttempts, err)
+}
+
+// resetContent sets the value referenced by content to its zero value
+func resetContent(content interface{}) {
+	v := reflect.ValueOf(content)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
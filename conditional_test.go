@@ -0,0 +1,187 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+)
+
+func ExampleWithIfMatch() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "web"}`))
+		} else if r.Header.Get("If-Match") != `"v2"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var server map[string]interface{}
+	var etag string
+	err := client.Exchange("GET", "/servers/1", nil, nil,
+		restclient.NewJsonEntity(&server), restclient.WithETag(&etag))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server["name"] = "www"
+	err = client.Exchange("PUT", "/servers/1", nil,
+		restclient.NewJsonEntity(server), nil, restclient.WithIfMatch(etag))
+	fmt.Println(etag, errors.Is(err, restclient.ErrConflict))
+	// Output:
+	// "v1" true
+}
+
+func ExampleClient_ConditionalUpdate() {
+	// Setup a test HTTP server where the resource is concurrently modified once
+	version := 1
+	counter := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := strconv.Quote(strconv.Itoa(version))
+		switch r.Method {
+		case "GET":
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]int{"counter": counter})
+		case "PUT":
+			if version == 1 {
+				// someone else got there first
+				version++
+				counter = 10
+			}
+			if r.Header.Get("If-Match") != strconv.Quote(strconv.Itoa(version)) {
+				fmt.Println("conflict")
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			var body map[string]int
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			counter = body["counter"]
+			version++
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resource struct {
+		Counter int `json:"counter"`
+	}
+	err := client.ConditionalUpdate(context.Background(), "PUT", "/counter",
+		restclient.NewJsonEntity(&resource), 3, func() error {
+			resource.Counter++
+			return nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(counter)
+	// Output:
+	// conflict
+	// 11
+}
+
+func ExampleClient_ConditionalUpdate_removedField() {
+	// Setup a test HTTP server where a concurrent modification replaces the tags
+	tags := `{"env":"prod"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Header().Set("ETag", strconv.Quote(tags))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"tags":%s}`, tags)
+			return
+		}
+		if tags == `{"env":"prod"}` {
+			// someone else got there first
+			tags = `{"owner":"ops"}`
+			fmt.Println("conflict")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Print(string(body))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resource struct {
+		Tags map[string]string `json:"tags"`
+	}
+	err := client.ConditionalUpdate(context.Background(), "PUT", "/metadata",
+		restclient.NewJsonEntity(&resource), 3, func() error {
+			resource.Tags["reviewed"] = "yes"
+			return nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// conflict
+	// {"tags":{"owner":"ops","reviewed":"yes"}}
+}
+
+func ExampleClient_ConditionalUpdate_noAttempts() {
+	// Setup a test HTTP server where the resource is always concurrently modified
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		fmt.Println("conflict")
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// less than one attempt still makes an attempt
+	var resource map[string]interface{}
+	err := client.ConditionalUpdate(context.Background(), "PUT", "/counter",
+		restclient.NewJsonEntity(&resource), 0, func() error {
+			return nil
+		})
+	fmt.Println(errors.Is(err, restclient.ErrConflict))
+	// Output:
+	// conflict
+	// true
+}
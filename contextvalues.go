@@ -0,0 +1,101 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	// PrincipalAttribute is the context attribute, given as a string, that identifies who made the
+	// request. It is used by the Audit interceptor in preference to AuditConfig.Principal.
+	PrincipalAttribute = "principal"
+	// EndpointAttribute is the context attribute, given as a string, that names the endpoint of the
+	// request, such as "/servers/{id}". It is used by LatencyRecorder in preference to
+	// LatencyConfig.Template.
+	EndpointAttribute = "endpoint"
+	// OperationAttribute is the context attribute, given as a string, that names the operation of
+	// the request, as set by WithOperationName
+	OperationAttribute = "operation"
+)
+
+type contextHeadersKey struct{}
+
+type contextAttributesKey struct{}
+
+// ContextWithHeader returns a context, for use with ExchangeWithContext, that adds the header to
+// the requests of the exchange, such as a correlation id passed down from an incoming request.
+// Headers given by WithHeader take precedence.
+func ContextWithHeader(ctx context.Context, name string, value string) context.Context {
+	header := HeadersFromContext(ctx).Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(name, value)
+	return context.WithValue(ctx, contextHeadersKey{}, header)
+}
+
+// HeadersFromContext returns the headers conveyed by the context, or nil if none. The returned
+// headers must not be modified.
+func HeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(contextHeadersKey{}).(http.Header)
+	return header
+}
+
+// ContextWithAttribute returns a context, for use with ExchangeWithContext, that conveys the
+// attribute to interceptors, which obtain it from the request's context with AttributeFromContext.
+// Built-in interceptors consume attributes such as PrincipalAttribute and EndpointAttribute.
+func ContextWithAttribute(ctx context.Context, key string, value interface{}) context.Context {
+	existing := AttributesFromContext(ctx)
+	attributes := make(map[string]interface{}, len(existing)+1)
+	for k, v := range existing {
+		attributes[k] = v
+	}
+	attributes[key] = value
+	return context.WithValue(ctx, contextAttributesKey{}, attributes)
+}
+
+// AttributeFromContext returns the attribute conveyed by the context, or nil if none
+func AttributeFromContext(ctx context.Context, key string) interface{} {
+	return AttributesFromContext(ctx)[key]
+}
+
+// AttributesFromContext returns all of the attributes conveyed by the context, or nil if none.
+// The returned map must not be modified.
+func AttributesFromContext(ctx context.Context) map[string]interface{} {
+	attributes, _ := ctx.Value(contextAttributesKey{}).(map[string]interface{})
+	return attributes
+}
+
+// OperationFromContext returns the name of the operation given by WithOperationName, or an empty
+// string if none
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := stringAttribute(ctx, OperationAttribute)
+	return operation
+}
+
+// stringAttribute returns the attribute conveyed by the context when it is a non-empty string
+func stringAttribute(ctx context.Context, key string) (string, bool) {
+	value, ok := AttributeFromContext(ctx, key).(string)
+	return value, ok && value != ""
+}
+
+func applyContextHeaders(req *http.Request) {
+	for name, values := range HeadersFromContext(req.Context()) {
+		req.Header[name] = values
+	}
+}
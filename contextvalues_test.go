@@ -0,0 +1,77 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleContextWithHeader() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println(r.Header.Get("X-Request-Id"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// such as the id of the incoming request being handled
+	ctx := restclient.ContextWithHeader(context.Background(), "X-Request-Id", "req-1234")
+	err := client.ExchangeWithContext(ctx, "GET", "/servers", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// req-1234
+}
+
+func ExampleContextWithAttribute() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Audit(restclient.AuditConfig{
+		Sink: restclient.AuditSinkFunc(func(ctx context.Context, record restclient.AuditRecord) error {
+			fmt.Println(record.Principal, record.Method)
+			return nil
+		}),
+	}))
+	// a custom interceptor consuming an application attribute
+	client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		fmt.Println("tenant", restclient.AttributeFromContext(req.Context(), "tenant"))
+		return next(req)
+	})
+
+	ctx := restclient.ContextWithAttribute(context.Background(), restclient.PrincipalAttribute, "alice")
+	ctx = restclient.ContextWithAttribute(ctx, "tenant", 123456)
+	err := client.ExchangeWithContext(ctx, "DELETE", "/servers/1", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// tenant 123456
+	// alice DELETE
+}
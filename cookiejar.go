@@ -0,0 +1,300 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileCookieJar is an http.CookieJar that can be saved to and loaded from a file, so that command
+// line tools can maintain authenticated sessions between invocations. It is used by setting it as
+// the Jar of the client's HttpClient, such as
+//
+//	jar, err := restclient.NewFileCookieJar(filepath.Join(configDir, "cookies.json"))
+//	client.HttpClient = &http.Client{Jar: jar}
+//	defer jar.Save()
+//
+// Cookies are matched by domain, path, expiry, and the Secure attribute. Since the jar does not
+// consult a public suffix list, it only accepts a Domain attribute that has at least two labels.
+// Unlike a browser, session cookies, those without an expiry, are also saved.
+type FileCookieJar struct {
+	// Clock is used to expire cookies, which defaults to SystemClock
+	Clock Clock
+
+	filename string
+	mu       sync.Mutex
+	cookies  map[string]*storedCookie
+	sequence int64
+}
+
+// storedCookie is the persisted form of a cookie in the jar
+type storedCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+	// HostOnly cookies only match the exact host that set them
+	HostOnly bool `json:"hostOnly,omitempty"`
+	// Sequence orders cookies of the same path length by their creation
+	Sequence int64 `json:"sequence"`
+}
+
+type cookieFile struct {
+	Cookies []*storedCookie `json:"cookies"`
+}
+
+// NewFileCookieJar creates a jar that is saved to the given file and loads the cookies of that
+// file, if it exists. Expired cookies are not loaded.
+func NewFileCookieJar(filename string) (*FileCookieJar, error) {
+	jar := &FileCookieJar{
+		filename: filename,
+		cookies:  make(map[string]*storedCookie),
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return jar, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	var stored cookieFile
+	err = json.Unmarshal(content, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookie file %s: %w", filename, err)
+	}
+	now := jar.now()
+	for _, cookie := range stored.Cookies {
+		if cookie.expired(now) {
+			continue
+		}
+		jar.cookies[cookie.key()] = cookie
+		if cookie.Sequence > jar.sequence {
+			jar.sequence = cookie.Sequence
+		}
+	}
+	return jar, nil
+}
+
+// Save writes the unexpired cookies to the jar's file, which is only readable by the current user.
+// The file is replaced atomically, so that a failure doesn't lose the previously saved cookies.
+func (j *FileCookieJar) Save() error {
+	j.mu.Lock()
+	stored := cookieFile{Cookies: []*storedCookie{}}
+	now := j.now()
+	for _, cookie := range j.cookies {
+		if !cookie.expired(now) {
+			stored.Cookies = append(stored.Cookies, cookie)
+		}
+	}
+	j.mu.Unlock()
+	sort.Slice(stored.Cookies, func(i, k int) bool {
+		return stored.Cookies[i].Sequence < stored.Cookies[k].Sequence
+	})
+
+	content, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(j.filename), filepath.Base(j.filename)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cookie file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(content)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	err = os.Rename(tempFile.Name(), j.filename)
+	if err != nil {
+		return fmt.Errorf("failed to replace cookie file: %w", err)
+	}
+	return nil
+}
+
+// SetCookies implements http.CookieJar
+func (j *FileCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := canonicalCookieHost(u)
+	if host == "" {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	for _, cookie := range cookies {
+		stored, ok := newStoredCookie(host, u.Path, cookie, now)
+		if !ok {
+			continue
+		}
+		key := stored.key()
+		if stored.expired(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		if existing, ok := j.cookies[key]; ok {
+			// a replaced cookie retains its creation order
+			stored.Sequence = existing.Sequence
+		} else {
+			j.sequence++
+			stored.Sequence = j.sequence
+		}
+		j.cookies[key] = stored
+	}
+}
+
+// Cookies implements http.CookieJar
+func (j *FileCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	host := canonicalCookieHost(u)
+	if host == "" {
+		return nil
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	var matched []*storedCookie
+	now := j.now()
+	for key, cookie := range j.cookies {
+		if cookie.expired(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		if (cookie.Secure && !secure) || !cookie.domainMatches(host) || !cookiePathMatches(path, cookie.Path) {
+			continue
+		}
+		matched = append(matched, cookie)
+	}
+	j.mu.Unlock()
+
+	// longer paths first and then by creation, as given by RFC 6265
+	sort.Slice(matched, func(i, k int) bool {
+		if len(matched[i].Path) != len(matched[k].Path) {
+			return len(matched[i].Path) > len(matched[k].Path)
+		}
+		return matched[i].Sequence < matched[k].Sequence
+	})
+	result := make([]*http.Cookie, len(matched))
+	for i, cookie := range matched {
+		result[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+	}
+	return result
+}
+
+func (j *FileCookieJar) now() time.Time {
+	if j.Clock != nil {
+		return j.Clock.Now()
+	}
+	return SystemClock.Now()
+}
+
+func newStoredCookie(host string, requestPath string, cookie *http.Cookie, now time.Time) (*storedCookie, bool) {
+	stored := &storedCookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	if domain == "" || domain == host {
+		stored.Domain = host
+		stored.HostOnly = cookie.Domain == ""
+	} else {
+		// the domain must be a parent of the host, other than a top-level domain, and IP addresses
+		// have no parent
+		if net.ParseIP(host) != nil || !strings.Contains(domain, ".") || !strings.HasSuffix(host, "."+domain) {
+			return nil, false
+		}
+		stored.Domain = domain
+	}
+
+	if stored.Path == "" || !strings.HasPrefix(stored.Path, "/") {
+		stored.Path = defaultCookiePath(requestPath)
+	}
+
+	switch {
+	case cookie.MaxAge < 0:
+		// expires immediately, which deletes the cookie
+		stored.Expires = now.Add(-time.Second)
+	case cookie.MaxAge > 0:
+		stored.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	case !cookie.Expires.IsZero():
+		stored.Expires = cookie.Expires
+	}
+	return stored, true
+}
+
+func (c *storedCookie) key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c *storedCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+func (c *storedCookie) domainMatches(host string) bool {
+	if c.HostOnly {
+		return host == c.Domain
+	}
+	return host == c.Domain || strings.HasSuffix(host, "."+c.Domain)
+}
+
+func canonicalCookieHost(u *url.URL) string {
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// defaultCookiePath is the directory of the request path, as given by RFC 6265
+func defaultCookiePath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+func cookiePathMatches(requestPath string, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
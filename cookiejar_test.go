@@ -0,0 +1,334 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleFileCookieJar() {
+	// Setup a test HTTP server with a session cookie
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/ui"})
+			return
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Println("RECV", r.URL.Path, cookie.Value, len(r.Cookies()))
+	}))
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "cookies")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cookies.json")
+
+	// Real example starts here
+	login := func() {
+		jar, err := restclient.NewFileCookieJar(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		client := restclient.NewClient()
+		client.SetBaseUrl(ts.URL)
+		client.HttpClient = &http.Client{Jar: jar}
+
+		err = client.Exchange("POST", "/login", nil, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = jar.Save()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// a later invocation of the tool
+	listServers := func() {
+		jar, err := restclient.NewFileCookieJar(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		client := restclient.NewClient()
+		client.SetBaseUrl(ts.URL)
+		client.HttpClient = &http.Client{Jar: jar}
+
+		err = client.Exchange("GET", "/servers", nil, nil, nil)
+		fmt.Println(err)
+	}
+
+	login()
+	listServers()
+	// Output:
+	// RECV /servers abc123 1
+	// <nil>
+}
+
+// cookieSet is a response of the host at url setting the cookie
+type cookieSet struct {
+	url    string
+	cookie http.Cookie
+}
+
+func TestFileCookieJar_matching(t *testing.T) {
+	base := time.Date(2020, 11, 3, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		sets     []cookieSet
+		advance  time.Duration
+		url      string
+		expected string
+	}{
+		{name: "host only",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1"}}},
+			url:  "http://example.com/servers", expected: "a=1"},
+		{name: "host only excludes subdomain",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1"}}},
+			url:  "http://www.example.com/"},
+		{name: "host is case-insensitive",
+			sets: []cookieSet{{"http://Example.COM/", http.Cookie{Name: "a", Value: "1"}}},
+			url:  "http://example.com./", expected: "a=1"},
+		{name: "domain includes subdomains",
+			sets: []cookieSet{{"http://www.example.com/", http.Cookie{Name: "a", Value: "1", Domain: "example.com"}}},
+			url:  "http://api.example.com/", expected: "a=1"},
+		{name: "domain with leading dot",
+			sets: []cookieSet{{"http://www.example.com/", http.Cookie{Name: "a", Value: "1", Domain: ".example.com"}}},
+			url:  "http://example.com/", expected: "a=1"},
+		{name: "domain excludes other domains",
+			sets: []cookieSet{{"http://www.example.com/", http.Cookie{Name: "a", Value: "1", Domain: "example.com"}}},
+			url:  "http://notexample.com/"},
+		{name: "domain that is not a parent is rejected",
+			sets: []cookieSet{{"http://www.example.com/", http.Cookie{Name: "a", Value: "1", Domain: "other.com"}}},
+			url:  "http://other.com/"},
+		{name: "top-level domain is rejected",
+			sets: []cookieSet{{"http://www.example.com/", http.Cookie{Name: "a", Value: "1", Domain: "com"}}},
+			url:  "http://www.example.com/"},
+		{name: "domain of IP address is rejected",
+			sets: []cookieSet{{"http://127.0.0.1/", http.Cookie{Name: "a", Value: "1", Domain: "0.0.1"}}},
+			url:  "http://127.0.0.1/"},
+		{name: "path prefix",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", Path: "/api"}}},
+			url:  "http://example.com/api/servers", expected: "a=1"},
+		{name: "path prefix within segment",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", Path: "/api"}}},
+			url:  "http://example.com/apix"},
+		{name: "path with trailing slash",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", Path: "/api/"}}},
+			url:  "http://example.com/api/servers", expected: "a=1"},
+		{name: "default path is directory of request",
+			sets: []cookieSet{{"http://example.com/api/login", http.Cookie{Name: "a", Value: "1"}}},
+			url:  "http://example.com/api/servers", expected: "a=1"},
+		{name: "default path excludes other directories",
+			sets: []cookieSet{{"http://example.com/api/login", http.Cookie{Name: "a", Value: "1"}}},
+			url:  "http://example.com/ui"},
+		{name: "longer paths first",
+			sets: []cookieSet{
+				{"http://example.com/", http.Cookie{Name: "a", Value: "1", Path: "/"}},
+				{"http://example.com/", http.Cookie{Name: "b", Value: "2", Path: "/api"}},
+				{"http://example.com/", http.Cookie{Name: "c", Value: "3", Path: "/"}},
+			},
+			url: "http://example.com/api", expected: "b=2; a=1; c=3"},
+		{name: "replaced cookie retains order",
+			sets: []cookieSet{
+				{"http://example.com/", http.Cookie{Name: "a", Value: "1"}},
+				{"http://example.com/", http.Cookie{Name: "b", Value: "2"}},
+				{"http://example.com/", http.Cookie{Name: "a", Value: "3"}},
+			},
+			url: "http://example.com/", expected: "a=3; b=2"},
+		{name: "secure over https",
+			sets: []cookieSet{{"https://example.com/", http.Cookie{Name: "a", Value: "1", Secure: true}}},
+			url:  "https://example.com/", expected: "a=1"},
+		{name: "secure not over http",
+			sets: []cookieSet{{"https://example.com/", http.Cookie{Name: "a", Value: "1", Secure: true}}},
+			url:  "http://example.com/"},
+		{name: "max age unexpired",
+			sets:    []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", MaxAge: 60}}},
+			advance: 59 * time.Second,
+			url:     "http://example.com/", expected: "a=1"},
+		{name: "max age expired",
+			sets:    []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", MaxAge: 60}}},
+			advance: 60 * time.Second,
+			url:     "http://example.com/"},
+		{name: "expires unexpired",
+			sets:    []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", Expires: base.Add(time.Hour)}}},
+			advance: 30 * time.Minute,
+			url:     "http://example.com/", expected: "a=1"},
+		{name: "expires expired",
+			sets:    []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", Expires: base.Add(time.Hour)}}},
+			advance: time.Hour,
+			url:     "http://example.com/"},
+		{name: "max age takes precedence over expires",
+			sets: []cookieSet{{"http://example.com/", http.Cookie{Name: "a", Value: "1", MaxAge: 60,
+				Expires: base.Add(time.Hour)}}},
+			advance: time.Minute,
+			url:     "http://example.com/"},
+		{name: "negative max age deletes",
+			sets: []cookieSet{
+				{"http://example.com/", http.Cookie{Name: "a", Value: "1"}},
+				{"http://example.com/", http.Cookie{Name: "a", Value: "", MaxAge: -1}},
+			},
+			url: "http://example.com/"},
+		{name: "past expires deletes",
+			sets: []cookieSet{
+				{"http://example.com/", http.Cookie{Name: "a", Value: "1"}},
+				{"http://example.com/", http.Cookie{Name: "a", Value: "", Expires: base.Add(-time.Hour)}},
+			},
+			url: "http://example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := restclienttest.NewFakeClock(base)
+			jar, err := restclient.NewFileCookieJar(filepath.Join(os.TempDir(), "unsaved-cookies.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			jar.Clock = clock
+
+			for _, set := range tt.sets {
+				cookie := set.cookie
+				jar.SetCookies(mustParseUrl(t, set.url), []*http.Cookie{&cookie})
+			}
+			clock.Advance(tt.advance)
+
+			actual := formatCookies(jar.Cookies(mustParseUrl(t, tt.url)))
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFileCookieJar_saveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cookies.json")
+
+	// the jar loads cookies by the system clock, so the fake clock starts now
+	clock := restclienttest.NewFakeClock(time.Now())
+	jar, err := restclient.NewFileCookieJar(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jar.Clock = clock
+	serverUrl := mustParseUrl(t, "https://example.com/")
+	jar.SetCookies(serverUrl, []*http.Cookie{
+		{Name: "remembered", Value: "1", MaxAge: 3600},
+		{Name: "short", Value: "2", MaxAge: 60},
+		{Name: "session", Value: "3"},
+		{Name: "secure", Value: "4", Secure: true, Path: "/api"},
+	})
+	clock.Advance(2 * time.Minute)
+	if err := jar.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := restclient.NewFileCookieJar(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://example.com/api", expected: "secure=4; remembered=1; session=3"},
+		{url: "http://example.com/api", expected: "remembered=1; session=3"},
+		{url: "https://www.example.com/"},
+	}
+	for _, tt := range tests {
+		actual := formatCookies(loaded.Cookies(mustParseUrl(t, tt.url)))
+		if actual != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.url, tt.expected, actual)
+		}
+	}
+}
+
+func TestFileCookieJar_loadSkipsExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cookies.json")
+	err = ioutil.WriteFile(filename, []byte(`{"cookies": [
+		{"name": "expired", "value": "1", "domain": "example.com", "path": "/",
+			"expires": "2000-01-01T00:00:00Z", "hostOnly": true, "sequence": 1},
+		{"name": "valid", "value": "2", "domain": "example.com", "path": "/",
+			"expires": "2100-01-01T00:00:00Z", "hostOnly": true, "sequence": 2}
+	]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jar, err := restclient.NewFileCookieJar(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := formatCookies(jar.Cookies(mustParseUrl(t, "http://example.com/")))
+	if actual != "valid=2" {
+		t.Errorf("expected %q, got %q", "valid=2", actual)
+	}
+
+	// saving also omits the expired cookie
+	if err := jar.Save(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "expired") {
+		t.Errorf("expected the expired cookie to be omitted, got %s", content)
+	}
+}
+
+func mustParseUrl(t *testing.T, rawUrl string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func formatCookies(cookies []*http.Cookie) string {
+	parts := make([]string, len(cookies))
+	for i, cookie := range cookies {
+		parts[i] = cookie.Name + "=" + cookie.Value
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Credentials are the secrets supplied by a CredentialsProvider. Which fields are used depends
+// upon the consumer, such as BasicAuthFrom using Username and Password.
+type Credentials struct {
+	Username string
+	Password string
+	Apikey   string
+}
+
+// CredentialsProvider supplies credentials at the time of each request, which allows secrets rotated
+// externally, such as by Vault or a mounted file, to be picked up without rebuilding the client
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc adapts a function to a CredentialsProvider
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+type staticCredentials Credentials
+
+func (c staticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(c), nil
+}
+
+// StaticCredentials creates a CredentialsProvider that always supplies the given credentials
+func StaticCredentials(credentials Credentials) CredentialsProvider {
+	return staticCredentials(credentials)
+}
+
+// TokenProvider supplies a token, such as a bearer token, at the time of each request
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc adapts a function to a TokenProvider
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// BasicAuthFrom creates an Interceptor that sets up basic authentication with the username and
+// password supplied by the provider for each request
+func BasicAuthFrom(provider CredentialsProvider) Interceptor {
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		credentials, err := provider.Credentials(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain credentials: %w", err)
+		}
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+		return next(req)
+	}
+}
+
+// BearerTokenFrom creates an Interceptor that sets the Authorization header to the bearer token
+// supplied by the provider for each request
+func BearerTokenFrom(provider TokenProvider) Interceptor {
+	return TokenAuthFrom(BearerTokenHeader, provider)
+}
+
+// TokenAuthFrom creates an Interceptor that sets the given header to the token supplied by the
+// provider for each request
+func TokenAuthFrom(header TokenHeader, provider TokenProvider) Interceptor {
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		token, err := provider.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain token: %w", err)
+		}
+		header.Set(req, token)
+		return next(req)
+	}
+}
+
+// FileToken creates a TokenProvider that supplies the content of the named file, with surrounding
+// whitespace removed, such as a token written by a Vault agent or a mounted Kubernetes secret.
+// The file is read again whenever its modification time changes.
+func FileToken(filename string) TokenProvider {
+	return &fileToken{filename: filename}
+}
+
+type fileToken struct {
+	filename string
+
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+func (f *fileToken) Token(context.Context) (string, error) {
+	info, err := os.Stat(f.filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to access token file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+
+	content, err := ioutil.ReadFile(f.filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := string(bytes.TrimSpace(content))
+	if token == "" {
+		return "", errors.New("token file is empty")
+	}
+	f.token = token
+	f.modTime = info.ModTime()
+	return token, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func ExampleBasicAuthFrom() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		fmt.Printf("RECV %s %s\n", username, password)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	password := "first"
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.BasicAuthFrom(restclient.CredentialsProviderFunc(
+		func(ctx context.Context) (restclient.Credentials, error) {
+			// such as looking up the current secret in a vault
+			return restclient.Credentials{Username: "admin", Password: password}, nil
+		})))
+
+	_ = client.Exchange("GET", "/", nil, nil, nil)
+	password = "rotated"
+	_ = client.Exchange("GET", "/", nil, nil, nil)
+
+	// Output:
+	// RECV admin first
+	// RECV admin rotated
+}
+
+func ExampleFileToken() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s\n", r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("token-1\n"), 0600)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.BearerTokenFrom(restclient.FileToken(tokenFile)))
+
+	_ = client.Exchange("GET", "/", nil, nil, nil)
+
+	// the token is rotated by an external agent
+	err = ioutil.WriteFile(tokenFile, []byte("token-2\n"), 0600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(tokenFile, later, later)
+
+	_ = client.Exchange("GET", "/", nil, nil, nil)
+
+	// Output:
+	// RECV Bearer token-1
+	// RECV Bearer token-2
+}
+
+func ExampleWithCredentialsProvider() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key1"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer ts.Close()
+
+	// Real example starts here
+	apikey := "key1"
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "", "", "",
+		restclient.WithCredentialsProvider(restclient.CredentialsProviderFunc(
+			func(ctx context.Context) (restclient.Credentials, error) {
+				return restclient.Credentials{Username: "user1", Apikey: apikey}, nil
+			})))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(authenticator)
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.TokensIssued())
+
+	// the rotated apikey is used to obtain a new token
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key2"})
+	apikey = "key2"
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.TokensIssued())
+
+	// Output:
+	// <nil> 1
+	// <nil> 2
+}
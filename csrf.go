@@ -0,0 +1,199 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CsrfConfig configures a CsrfProtection. At least one of TokenUrl, Cookie, or the responses
+// conveying the Header is needed to acquire the token. Zero values are replaced with the defaults
+// noted on each field.
+type CsrfConfig struct {
+	// Header conveys the token on mutating requests and, when present on any response, provides a
+	// new token. It defaults to X-CSRF-Token.
+	Header string
+	// TokenUrl, when set, is retrieved with GET to acquire a token when one is needed. The request
+	// includes the Header with the value "Fetch" and the token is taken from the Header or Cookie
+	// of the response. It may be relative to the URL of the request that needs the token.
+	TokenUrl string
+	// Cookie, when set, is the name of a cookie that provides the token, such as XSRF-TOKEN
+	Cookie string
+	// IsExpired determines if a response indicates that the token was missing or expired, in which
+	// case a new token is acquired and the request is retried once. The default detects a
+	// 403 Forbidden with the Header set to "Required" and the 419 status used by some frameworks.
+	IsExpired func(resp *http.Response) bool
+}
+
+// CsrfProtection acquires a cross-site request forgery token and injects it on mutating requests,
+// those other than GET, HEAD, OPTIONS, and TRACE, which is required by APIs that are shared with
+// browser-based applications.
+//
+// Use the Intercept method as the Interceptor, after any authentication interceptors, such as
+//
+//	client.AddInterceptor(csrf.Intercept)
+type CsrfProtection struct {
+	config CsrfConfig
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewCsrfProtection creates a CsrfProtection with the given configuration
+func NewCsrfProtection(config CsrfConfig) *CsrfProtection {
+	if config.Header == "" {
+		config.Header = "X-CSRF-Token"
+	}
+	if config.IsExpired == nil {
+		header := config.Header
+		config.IsExpired = func(resp *http.Response) bool {
+			return resp.StatusCode == 419 ||
+				(resp.StatusCode == http.StatusForbidden && strings.EqualFold(resp.Header.Get(header), "Required"))
+		}
+	}
+	return &CsrfProtection{config: config}
+}
+
+// Intercept is an Interceptor that injects the token on mutating requests and captures new tokens
+// from responses
+func (p *CsrfProtection) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	if !isMutating(req.Method) {
+		resp, err := next(req)
+		if err == nil {
+			p.capture(resp)
+		}
+		return resp, err
+	}
+
+	token, err := p.currentToken(req, next)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(p.config.Header, token)
+	}
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	p.capture(resp)
+
+	canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !p.config.IsExpired(resp) || !canReplay {
+		return resp, nil
+	}
+
+	p.clear(token)
+	token, err = p.currentToken(req, next)
+	if err != nil || token == "" {
+		// the original response is more informative than the failure to acquire a token
+		return resp, nil
+	}
+	discardBody(resp)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		req.Body = body
+	}
+	req.Header.Set(p.config.Header, token)
+	resp, err = next(req)
+	if err == nil {
+		p.capture(resp)
+	}
+	return resp, err
+}
+
+// Token returns the current token, if one has been acquired
+func (p *CsrfProtection) Token() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.token
+}
+
+// currentToken returns the current token or fetches one from the TokenUrl, if configured
+func (p *CsrfProtection) currentToken(req *http.Request, next NextCallback) (string, error) {
+	if token := p.Token(); token != "" || p.config.TokenUrl == "" {
+		return token, nil
+	}
+
+	tokenUrl, err := req.URL.Parse(p.config.TokenUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse CSRF token url: %w", err)
+	}
+	fetchReq, err := http.NewRequestWithContext(req.Context(), "GET", tokenUrl.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to setup CSRF token request: %w", err)
+	}
+	// retains headers set so far, such as authentication
+	fetchReq.Header = req.Header.Clone()
+	fetchReq.Header.Del(headerContentType)
+	fetchReq.Header.Set(p.config.Header, "Fetch")
+
+	resp, err := next(fetchReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch CSRF token: %w", err)
+	}
+	discardBody(resp)
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to fetch CSRF token: %s", resp.Status)
+	}
+	p.capture(resp)
+	return p.Token(), nil
+}
+
+// capture retains a token provided by the response
+func (p *CsrfProtection) capture(resp *http.Response) {
+	token := resp.Header.Get(p.config.Header)
+	if strings.EqualFold(token, "Required") {
+		token = ""
+	}
+	if p.config.Cookie != "" {
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == p.config.Cookie && cookie.Value != "" {
+				token = cookie.Value
+			}
+		}
+	}
+	if token == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = token
+}
+
+// clear discards the token, unless it was already replaced by a concurrent request
+func (p *CsrfProtection) clear(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token == token {
+		p.token = ""
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleCsrfProtection() {
+	// Setup a test HTTP server whose token expires after its first use
+	tokens := 0
+	current := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-CSRF-Token") == "Fetch" {
+			tokens++
+			current = fmt.Sprintf("token%d", tokens)
+			w.Header().Set("X-CSRF-Token", current)
+			return
+		}
+		if r.Method != "GET" {
+			if r.Header.Get("X-CSRF-Token") != current {
+				w.Header().Set("X-CSRF-Token", "Required")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			current = ""
+		}
+		fmt.Printf("RECV %s %s token=%q\n", r.Method, r.URL.Path, r.Header.Get("X-CSRF-Token"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	csrf := restclient.NewCsrfProtection(restclient.CsrfConfig{TokenUrl: "/csrf"})
+	client.AddInterceptor(csrf.Intercept)
+
+	fmt.Println(client.Exchange("GET", "/servers", nil, nil, nil))
+	fmt.Println(client.Exchange("POST", "/servers", nil, restclient.NewJsonEntity(map[string]string{"name": "web"}), nil))
+	fmt.Println(client.Exchange("DELETE", "/servers/web", nil, nil, nil))
+	// Output:
+	// RECV GET /servers token=""
+	// <nil>
+	// RECV POST /servers token="token1"
+	// <nil>
+	// RECV DELETE /servers/web token="token2"
+	// <nil>
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CsvType is the content type of comma-separated values entities
+const CsvType MimeType = "text/csv"
+
+// CsvRowHandler can be used as the content of a CsvType response entity to process each
+// row as it is read rather than accumulating all rows in memory. The row is keyed by the
+// column names given in the header row. Returning an error stops the processing and is
+// returned from the exchange.
+type CsvRowHandler func(row map[string]string) error
+
+func init() {
+	RegisterCodec(CsvType, csvCodec{})
+}
+
+// NewCsvEntity creates an entity of CsvType.
+//
+// For a response entity, content can be a pointer to a slice of structs, where the header row of the
+// response is mapped to fields by the "csv" tag or case-insensitive field name; a *[][]string, which
+// receives all rows; or a CsvRowHandler to stream the rows.
+//
+// For a request entity, content can be a slice of structs or a [][]string.
+func NewCsvEntity(content interface{}) *Entity {
+	return &Entity{
+		ContentType: CsvType,
+		Content:     content,
+	}
+}
+
+// csvCodec decodes CSV content with a header row into one of
+//
+// *[]T or *[]*T where T is a struct with fields matched to columns by a "csv" tag or case-insensitive name,
+// *[][]string, which receives all rows including the header,
+// or a CsvRowHandler.
+//
+// It encodes content of []T, []*T, or [][]string in the corresponding manner.
+type csvCodec struct{}
+
+func (csvCodec) Decode(r io.Reader, content interface{}) error {
+	reader := csv.NewReader(r)
+
+	switch c := content.(type) {
+	case *[][]string:
+		records, err := reader.ReadAll()
+		if err != nil {
+			return err
+		}
+		*c = records
+		return nil
+
+	case CsvRowHandler:
+		return decodeCsvRows(reader, func(header, record []string) error {
+			row := make(map[string]string, len(header))
+			for i, name := range header {
+				row[name] = record[i]
+			}
+			return c(row)
+		})
+	}
+
+	sliceVal := reflect.ValueOf(content)
+	if sliceVal.Kind() != reflect.Ptr || sliceVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported CSV content type %T", content)
+	}
+	sliceVal = sliceVal.Elem()
+	elemType := sliceVal.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported CSV content type %T", content)
+	}
+
+	var fields []int
+	return decodeCsvRows(reader, func(header, record []string) error {
+		if fields == nil {
+			fields = csvFieldIndexes(structType, header)
+		}
+		structPtr := reflect.New(structType)
+		for col, fieldIndex := range fields {
+			if fieldIndex < 0 {
+				continue
+			}
+			err := setCsvField(structPtr.Elem().Field(fieldIndex), record[col])
+			if err != nil {
+				return fmt.Errorf("column %s: %w", header[col], err)
+			}
+		}
+		if elemType.Kind() == reflect.Ptr {
+			sliceVal.Set(reflect.Append(sliceVal, structPtr))
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, structPtr.Elem()))
+		}
+		return nil
+	})
+}
+
+func (csvCodec) Encode(w io.Writer, content interface{}) error {
+	writer := csv.NewWriter(w)
+
+	if records, ok := content.([][]string); ok {
+		return writer.WriteAll(records)
+	}
+
+	sliceVal := reflect.ValueOf(content)
+	if sliceVal.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported CSV content type %T", content)
+	}
+	structType := sliceVal.Type().Elem()
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported CSV content type %T", content)
+	}
+
+	var header []string
+	var fields []int
+	for i := 0; i < structType.NumField(); i++ {
+		if name, ok := csvFieldName(structType.Field(i)); ok {
+			header = append(header, name)
+			fields = append(fields, i)
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := 0; i < sliceVal.Len(); i++ {
+		structVal := reflect.Indirect(sliceVal.Index(i))
+		for col, fieldIndex := range fields {
+			value := structVal.Field(fieldIndex).Interface()
+			if m, ok := value.(encoding.TextMarshaler); ok {
+				text, err := m.MarshalText()
+				if err != nil {
+					return err
+				}
+				record[col] = string(text)
+			} else {
+				record[col] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// decodeCsvRows reads the header row and then invokes handler with each subsequent row
+func decodeCsvRows(reader *csv.Reader, handler func(header, record []string) error) error {
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := handler(header, record); err != nil {
+			return err
+		}
+	}
+}
+
+// csvFieldName resolves the column name of the given field and false if the field should be skipped
+func csvFieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		// unexported
+		return "", false
+	}
+	tag := field.Tag.Get("csv")
+	if tag == "-" {
+		return "", false
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return field.Name, true
+}
+
+// csvFieldIndexes returns, for each header column, the index of the matching struct field or -1
+func csvFieldIndexes(structType reflect.Type, header []string) []int {
+	indexes := make([]int, len(header))
+	for col, columnName := range header {
+		indexes[col] = -1
+		for i := 0; i < structType.NumField(); i++ {
+			if name, ok := csvFieldName(structType.Field(i)); ok && strings.EqualFold(name, columnName) {
+				indexes[col] = i
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+func setCsvField(field reflect.Value, value string) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
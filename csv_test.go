@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleNewCsvEntity() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "name,qty,unit_price\nwidget,3,1.25\ngadget,1,10\n")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Item struct {
+		Name      string
+		Qty       int
+		UnitPrice float64 `csv:"unit_price"`
+	}
+	var items []Item
+
+	err := client.Exchange("GET", "/report", nil, nil,
+		restclient.NewCsvEntity(&items))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%+v\n", items)
+	// Output:
+	// [{Name:widget Qty:3 UnitPrice:1.25} {Name:gadget Qty:1 UnitPrice:10}]
+}
+
+func ExampleCsvRowHandler() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "host,status\nweb1,up\nweb2,down\n")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	err := client.Exchange("GET", "/export", nil, nil,
+		restclient.NewCsvEntity(restclient.CsvRowHandler(func(row map[string]string) error {
+			fmt.Printf("%s is %s\n", row["host"], row["status"])
+			return nil
+		})))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// web1 is up
+	// web2 is down
+}
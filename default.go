@@ -0,0 +1,56 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// DefaultClient is the Client used by the package-level Exchange, Get, and Post functions.
+// It can be configured, such as setting its BaseUrl or adding interceptors, or replaced entirely.
+var DefaultClient = NewClient()
+
+// ExchangeWithContext uses DefaultClient to perform the exchange. See Client.ExchangeWithContext
+// for details.
+func ExchangeWithContext(ctx context.Context, method string,
+	urlIn string, query url.Values,
+	reqIn *Entity,
+	respOut *Entity,
+	opts ...RequestOption) error {
+	return DefaultClient.ExchangeWithContext(ctx, method, urlIn, query, reqIn, respOut, opts...)
+}
+
+// Exchange uses DefaultClient to perform the exchange. See Client.ExchangeWithContext for details.
+func Exchange(method string,
+	urlIn string, query url.Values,
+	reqIn *Entity,
+	respOut *Entity,
+	opts ...RequestOption) error {
+	return DefaultClient.Exchange(method, urlIn, query, reqIn, respOut, opts...)
+}
+
+// Get uses DefaultClient to issue a GET request and process the response into respOut, if non-nil.
+func Get(urlIn string, respOut *Entity, opts ...RequestOption) error {
+	return DefaultClient.Exchange("GET", urlIn, nil, nil, respOut, opts...)
+}
+
+// Post uses DefaultClient to issue a POST request with reqIn as the payload and process the
+// response into respOut, if non-nil.
+func Post(urlIn string, reqIn *Entity, respOut *Entity, opts ...RequestOption) error {
+	return DefaultClient.Exchange("POST", urlIn, nil, reqIn, respOut, opts...)
+}
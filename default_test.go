@@ -0,0 +1,73 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleGet() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	type Health struct {
+		Status string
+	}
+	var resp Health
+
+	err := restclient.Get(ts.URL+"/health", restclient.NewJsonEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp.Status)
+	// Output:
+	// ok
+}
+
+func ExampleExchangeWithContext() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Status":"ok"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var resp map[string]string
+	err := restclient.ExchangeWithContext(ctx, "GET", ts.URL+"/health", nil,
+		nil, restclient.NewJsonEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp["Status"])
+	// Output:
+	// ok
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Warning is a warning given by the Warning header of a response, as specified by RFC 7234
+type Warning struct {
+	// Code is the warn-code, such as 299 for a miscellaneous persistent warning
+	Code  int
+	Agent string
+	Text  string
+	// Date is the time of the warning or the zero time if not given
+	Date time.Time
+}
+
+// DeprecationInfo conveys the deprecation of the requested resource reported by a server
+type DeprecationInfo struct {
+	// Deprecated is true when the Deprecation header declares the resource deprecated
+	Deprecated bool
+	// Date is when the resource was or will be deprecated, or the zero time if not given
+	Date time.Time
+	// Sunset is when the resource is expected to become unresponsive, as given by the Sunset header
+	// of RFC 8594, or the zero time if not given
+	Sunset time.Time
+	// Links are the URLs of the Link header with the relation "deprecation" or "sunset", which
+	// typically describe the deprecation and migration
+	Links []string
+	// Warnings are those of the Warning header
+	Warnings []Warning
+}
+
+// DeprecationEvent conveys the deprecation notice of a response to the hook of DeprecationNotices
+type DeprecationEvent struct {
+	Method string
+	// Url is the URL of the request, with sensitive query parameters redacted
+	Url        string
+	StatusCode int
+	Info       DeprecationInfo
+}
+
+// DeprecationNotices creates an Interceptor that calls onNotice for each response having a
+// Deprecation, Sunset, or Warning header, so that teams get advance notice of calls to endpoints
+// that are scheduled for removal, such as
+//
+//	client.AddInterceptor(restclient.DeprecationNotices(func(event restclient.DeprecationEvent) {
+//		log.Printf("%s %s is deprecated, sunset at %s", event.Method, event.Url, event.Info.Sunset)
+//	}))
+//
+// The hook is called for every such response, so it should deduplicate notices if needed.
+func DeprecationNotices(onNotice func(event DeprecationEvent)) Interceptor {
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		if info := ParseDeprecation(resp.Header); info != nil {
+			onNotice(DeprecationEvent{
+				Method:     req.Method,
+				Url:        DefaultRedactionPolicy.RedactUrl(req.URL).String(),
+				StatusCode: resp.StatusCode,
+				Info:       *info,
+			})
+		}
+		return resp, nil
+	}
+}
+
+// ParseDeprecation extracts the deprecation notice from the Deprecation header, given as a
+// structured date such as "@1688169599", an HTTP date, or "true", the Sunset header, and the
+// Warning header. Returns nil if none of the headers are present.
+func ParseDeprecation(header http.Header) *DeprecationInfo {
+	deprecation := strings.TrimSpace(header.Get("Deprecation"))
+	sunset := strings.TrimSpace(header.Get("Sunset"))
+	warnings := ParseWarnings(header)
+	if deprecation == "" && sunset == "" && len(warnings) == 0 {
+		return nil
+	}
+
+	info := &DeprecationInfo{Warnings: warnings}
+	if deprecation != "" && !strings.EqualFold(deprecation, "false") {
+		info.Deprecated = true
+		if strings.HasPrefix(deprecation, "@") {
+			if seconds, err := strconv.ParseInt(deprecation[1:], 10, 64); err == nil {
+				info.Date = time.Unix(seconds, 0).UTC()
+			}
+		} else if date, err := http.ParseTime(deprecation); err == nil {
+			info.Date = date
+		}
+	}
+	if sunset != "" {
+		if date, err := http.ParseTime(sunset); err == nil {
+			info.Sunset = date
+		}
+	}
+	for _, link := range parseLinks(header) {
+		if link.rels["deprecation"] || link.rels["sunset"] {
+			info.Links = append(info.Links, link.url)
+		}
+	}
+	return info
+}
+
+// ParseWarnings extracts the warnings of the Warning headers, such as
+//
+//	Warning: 299 api.example.com "Deprecated API" "Wed, 21 Oct 2015 07:28:00 GMT"
+//
+// Malformed warnings are skipped.
+func ParseWarnings(header http.Header) []Warning {
+	var warnings []Warning
+	for _, value := range header["Warning"] {
+		rest := value
+		for {
+			rest = strings.TrimLeft(rest, " \t,")
+			if rest == "" {
+				break
+			}
+			warning, remaining, ok := parseWarning(rest)
+			if !ok {
+				break
+			}
+			warnings = append(warnings, warning)
+			rest = remaining
+		}
+	}
+	return warnings
+}
+
+// parseWarning parses the warning at the start of value and returns the remainder
+func parseWarning(value string) (Warning, string, bool) {
+	var warning Warning
+	fields := strings.SplitN(value, " ", 3)
+	if len(fields) < 3 {
+		return warning, "", false
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return warning, "", false
+	}
+	warning.Code = code
+	warning.Agent = fields[1]
+
+	text, rest, ok := parseQuotedString(strings.TrimLeft(fields[2], " "))
+	if !ok {
+		return warning, "", false
+	}
+	warning.Text = text
+
+	if trimmed := strings.TrimLeft(rest, " "); strings.HasPrefix(trimmed, `"`) {
+		if date, remaining, ok := parseQuotedString(trimmed); ok {
+			warning.Date, _ = http.ParseTime(date)
+			rest = remaining
+		}
+	}
+	return warning, rest, true
+}
+
+// parseQuotedString parses the quoted string at the start of value and returns the remainder
+func parseQuotedString(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, `"`) {
+		return "", "", false
+	}
+	var builder strings.Builder
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				builder.WriteByte(value[i])
+			}
+		case '"':
+			return builder.String(), value[i+1:], true
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return "", "", false
+}
+
+type headerLink struct {
+	url  string
+	rels map[string]bool
+}
+
+// parseLinks parses the Link headers, as specified by RFC 8288, retaining the URL and relations
+func parseLinks(header http.Header) []headerLink {
+	var links []headerLink
+	for _, value := range header["Link"] {
+		for _, part := range splitLinks(value) {
+			part = strings.TrimSpace(part)
+			end := strings.Index(part, ">")
+			if !strings.HasPrefix(part, "<") || end < 0 {
+				continue
+			}
+			link := headerLink{url: part[1:end], rels: make(map[string]bool)}
+			for _, param := range strings.Split(part[end+1:], ";") {
+				name := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(name) == 2 && strings.EqualFold(strings.TrimSpace(name[0]), "rel") {
+					for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(name[1]), `"`)) {
+						link.rels[strings.ToLower(rel)] = true
+					}
+				}
+			}
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// splitLinks splits the comma separated links, ignoring commas within URLs and quoted parameters
+func splitLinks(value string) []string {
+	var parts []string
+	inUrl, inQuotes := false, false
+	start := 0
+	for i, c := range value {
+		switch {
+		case c == '<' && !inQuotes:
+			inUrl = true
+		case c == '>' && !inQuotes:
+			inUrl = false
+		case c == '"' && !inUrl:
+			inQuotes = !inQuotes
+		case c == ',' && !inUrl && !inQuotes:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
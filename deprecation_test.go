@@ -0,0 +1,56 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleDeprecationNotices() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1688169599")
+		w.Header().Set("Sunset", "Sun, 30 Jun 2024 23:59:59 GMT")
+		w.Header().Set("Link", `<https://developer.example.com/v2-migration>; rel="deprecation"; type="text/html"`)
+		w.Header().Set("Warning", `299 api.example.com "Use /v2/servers instead", 199 - "Rate, limited" "Wed, 21 Oct 2015 07:28:00 GMT"`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.DeprecationNotices(func(event restclient.DeprecationEvent) {
+		fmt.Println(event.Method, event.Info.Deprecated, event.Info.Date, event.Info.Sunset)
+		fmt.Println(event.Info.Links)
+		for _, warning := range event.Info.Warnings {
+			fmt.Printf("%d %s %q %s\n", warning.Code, warning.Agent, warning.Text, warning.Date)
+		}
+	}))
+
+	err := client.Exchange("GET", "/v1/servers", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// GET true 2023-06-30 23:59:59 +0000 UTC 2024-06-30 23:59:59 +0000 UTC
+	// [https://developer.example.com/v2-migration]
+	// 299 api.example.com "Use /v2/servers instead" 0001-01-01 00:00:00 +0000 UTC
+	// 199 - "Rate, limited" 2015-10-21 07:28:00 +0000 UTC
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// DigestAlgorithm identifies a hash algorithm by its RFC 3230 name
+type DigestAlgorithm string
+
+const (
+	DigestMD5    DigestAlgorithm = "MD5"
+	DigestSHA256 DigestAlgorithm = "SHA-256"
+	DigestSHA512 DigestAlgorithm = "SHA-512"
+)
+
+func (a DigestAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case DigestMD5:
+		return md5.New(), nil
+	case DigestSHA256:
+		return sha256.New(), nil
+	case DigestSHA512:
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %s", a)
+	}
+}
+
+// ContentMD5 creates an Interceptor that sets the Content-MD5 header of requests with a body.
+//
+// Request bodies that can't be replayed, such as an io.Reader entity, are buffered in memory in
+// order to compute the checksum.
+func ContentMD5() Interceptor {
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		sums, err := digestRequestBody(req, []DigestAlgorithm{DigestMD5})
+		if err != nil {
+			return nil, err
+		}
+		if sums != nil {
+			req.Header.Set("Content-MD5", sums[0])
+		}
+		return next(req)
+	}
+}
+
+// ContentDigest creates an Interceptor that sets the RFC 3230 Digest header of requests with a body
+// using each of the given algorithms, such as
+//
+// Digest: SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=
+//
+// Request bodies that can't be replayed, such as an io.Reader entity, are buffered in memory in
+// order to compute the digest.
+func ContentDigest(algorithms ...DigestAlgorithm) Interceptor {
+	if len(algorithms) == 0 {
+		algorithms = []DigestAlgorithm{DigestSHA256}
+	}
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		sums, err := digestRequestBody(req, algorithms)
+		if err != nil {
+			return nil, err
+		}
+		if sums != nil {
+			values := make([]string, len(algorithms))
+			for i, algorithm := range algorithms {
+				values[i] = string(algorithm) + "=" + sums[i]
+			}
+			req.Header.Set("Digest", strings.Join(values, ","))
+		}
+		return next(req)
+	}
+}
+
+// digestRequestBody computes the base64 encoded digests of the request body or returns nil
+// if the request has no body
+func digestRequestBody(req *http.Request, algorithms []DigestAlgorithm) ([]string, error) {
+	content, err := ReadRequestBody(req)
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	sums := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		h, err := algorithm.newHash()
+		if err != nil {
+			return nil, err
+		}
+		_, _ = h.Write(content)
+		sums[i] = base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+func ExampleContentDigest() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("Digest: %s\n", r.Header.Get("Digest"))
+		fmt.Printf("Content-MD5: %s\n", r.Header.Get("Content-MD5"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.ContentDigest(restclient.DigestSHA256))
+	client.AddInterceptor(restclient.ContentMD5())
+
+	err := client.Exchange("PUT", "/objects/greeting", nil,
+		restclient.NewTextEntity("hello world"), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// Digest: SHA-256=uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=
+	// Content-MD5: XrY7u+Ae7tCTyyK7j1rNww==
+}
+
+func ExampleContentMD5_file() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("Content-MD5: %s\n", r.Header.Get("Content-MD5"))
+		fmt.Printf("RECV %d %s\n", r.ContentLength, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("hello world")
+	file.Seek(0, io.SeekStart)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.ContentMD5())
+
+	// the digest is computed from a copy of the file's content, so the file is still sent in full
+	err = client.Exchange("PUT", "/objects/greeting", nil,
+		restclient.NewReaderEntity(file, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// Content-MD5: XrY7u+Ae7tCTyyK7j1rNww==
+	// RECV 11 hello world
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDiscoveryTTL is how long a DiscoveryCache retains a version document by default
+const DefaultDiscoveryTTL = time.Hour
+
+// ErrVersionNotFound is matched, via errors.Is, when an API root does not list the requested version
+var ErrVersionNotFound = errors.New("API version not found")
+
+// ApiVersion describes a version of an API as listed by the API's root, in the style of OpenStack
+// version discovery
+type ApiVersion struct {
+	Id string `json:"id"`
+	// Status is typically CURRENT, SUPPORTED, or DEPRECATED
+	Status string `json:"status"`
+	Links  []struct {
+		Href string `json:"href"`
+		Rel  string `json:"rel"`
+	} `json:"links"`
+	// Version is the maximum microversion supported by the version, if any
+	Version string `json:"version"`
+	// MinVersion is the minimum microversion supported by the version, if any
+	MinVersion string `json:"min_version"`
+	Updated    string `json:"updated"`
+}
+
+// selfUrl is the URL of the version's self link resolved against the URL of the document
+func (v *ApiVersion) selfUrl(documentUrl *url.URL) (*url.URL, error) {
+	for _, link := range v.Links {
+		if link.Rel == "self" {
+			u, err := documentUrl.Parse(link.Href)
+			if err != nil {
+				return nil, fmt.Errorf("invalid link of version %s: %w", v.Id, err)
+			}
+			if !strings.HasSuffix(u.Path, "/") {
+				// allows relative request URLs to resolve within the version
+				u.Path += "/"
+			}
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s has no self link", v.Id)
+}
+
+// DiscoveryCache retrieves and caches the version documents of API roots, such as the listing
+// returned by GET on the root of an OpenStack API, and resolves the versioned base URL of an API.
+// A document is retrieved again once its TTL has elapsed, so resolving is cheap enough to perform
+// prior to each use of an API. Concurrent callers of the same root share a single retrieval, which
+// they stop waiting on when their context is done.
+type DiscoveryCache struct {
+	// Clock determines when documents have expired, which defaults to SystemClock
+	Clock Clock
+
+	client *Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*discoveryEntry
+}
+
+// discoveringKey marks the context of requests for version documents, which interceptors that
+// themselves rely upon discovery must pass along
+type discoveringKey struct{}
+
+// discoveryEntry is the document of an API root, which is being retrieved until ready is closed
+type discoveryEntry struct {
+	ready       chan struct{}
+	documentUrl *url.URL
+	versions    []ApiVersion
+	err         error
+	fetched     time.Time
+}
+
+// NewDiscoveryCache creates a DiscoveryCache that retrieves documents with the given client, whose
+// interceptors, such as for authentication, apply. A non-positive ttl is replaced with DefaultDiscoveryTTL.
+func NewDiscoveryCache(client *Client, ttl time.Duration) *DiscoveryCache {
+	if ttl <= 0 {
+		ttl = DefaultDiscoveryTTL
+	}
+	return &DiscoveryCache{
+		client:  client,
+		ttl:     ttl,
+		entries: make(map[string]*discoveryEntry),
+	}
+}
+
+// Versions returns the versions listed by the API root at rootUrl, which may be relative to the
+// client's BaseUrl
+func (d *DiscoveryCache) Versions(ctx context.Context, rootUrl string) ([]ApiVersion, error) {
+	entry, err := d.entry(ctx, rootUrl)
+	if err != nil {
+		return nil, err
+	}
+	return entry.versions, nil
+}
+
+// ResolveVersion locates the given version listed by the API root at rootUrl. The version matches
+// an identical id or, such as "v2" matching "v2.1", an id with a dotted suffix, where a CURRENT version
+// is preferred. An empty version locates the CURRENT version.
+func (d *DiscoveryCache) ResolveVersion(ctx context.Context, rootUrl string, version string) (*ApiVersion, error) {
+	entry, err := d.entry(ctx, rootUrl)
+	if err != nil {
+		return nil, err
+	}
+	v := matchVersion(entry.versions, version)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %q at %s", ErrVersionNotFound, version, rootUrl)
+	}
+	return v, nil
+}
+
+// Resolve returns the base URL of the given version, located as described for ResolveVersion. The
+// URL ends with a slash, so that it can be given as the BaseUrl of a Client with request URLs
+// that are relative, such as "servers" rather than "/servers".
+func (d *DiscoveryCache) Resolve(ctx context.Context, rootUrl string, version string) (*url.URL, error) {
+	entry, err := d.entry(ctx, rootUrl)
+	if err != nil {
+		return nil, err
+	}
+	v := matchVersion(entry.versions, version)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %q at %s", ErrVersionNotFound, version, rootUrl)
+	}
+	return v.selfUrl(entry.documentUrl)
+}
+
+// Invalidate discards the document of the API root, such as after a request to a resolved URL
+// failed with 404 Not Found
+func (d *DiscoveryCache) Invalidate(rootUrl string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, rootUrl)
+}
+
+func (d *DiscoveryCache) entry(ctx context.Context, rootUrl string) (*discoveryEntry, error) {
+	now := d.clock().Now()
+	d.mu.Lock()
+	entry, exists := d.entries[rootUrl]
+	if exists {
+		select {
+		case <-entry.ready:
+			// expired documents are retrieved again
+			exists = now.Sub(entry.fetched) < d.ttl
+		default:
+		}
+	}
+	if !exists {
+		entry = &discoveryEntry{ready: make(chan struct{}), fetched: now}
+		d.entries[rootUrl] = entry
+	}
+	d.mu.Unlock()
+
+	if exists {
+		// waits on the retrieval by another caller without holding up other roots
+		select {
+		case <-entry.ready:
+			if entry.err != nil {
+				return nil, entry.err
+			}
+			return entry, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	entry.documentUrl, entry.versions, entry.err = d.fetch(ctx, rootUrl)
+	if entry.err != nil {
+		// failures are not cached, so the next call tries again
+		d.mu.Lock()
+		if d.entries[rootUrl] == entry {
+			delete(d.entries, rootUrl)
+		}
+		d.mu.Unlock()
+	}
+	close(entry.ready)
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry, nil
+}
+
+// fetch retrieves and parses the version document of the API root
+func (d *DiscoveryCache) fetch(ctx context.Context, rootUrl string) (*url.URL, []ApiVersion, error) {
+	documentUrl, err := d.client.buildReqUrl(rootUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	respOut := &Entity{ContentType: JsonType, Content: []byte(nil)}
+	err = d.client.ExchangeWithContext(context.WithValue(ctx, discoveringKey{}, true), "GET", documentUrl.String(), nil, nil, respOut)
+	content, _ := respOut.Content.([]byte)
+	if err != nil {
+		// some APIs, such as Keystone, list their versions with 300 Multiple Choices
+		var failed *FailedResponseError
+		if !errors.As(err, &failed) || failed.StatusCode != http.StatusMultipleChoices {
+			return nil, nil, fmt.Errorf("failed to discover versions at %s: %w", rootUrl, err)
+		}
+		content, _ = failed.Entity.Content.([]byte)
+	}
+
+	versions, err := parseVersionDocument(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover versions at %s: %w", rootUrl, err)
+	}
+	return documentUrl, versions, nil
+}
+
+func (d *DiscoveryCache) clock() Clock {
+	if d.Clock != nil {
+		return d.Clock
+	}
+	return SystemClock
+}
+
+// parseVersionDocument accepts the forms of version documents in use, which are a list of versions,
+// such as by Nova, versions nested in "values", such as by Keystone, or a single version
+func parseVersionDocument(content []byte) ([]ApiVersion, error) {
+	var document struct {
+		Versions json.RawMessage `json:"versions"`
+		Version  *ApiVersion     `json:"version"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("invalid version document: %w", err)
+	}
+
+	switch {
+	case len(document.Versions) > 0 && document.Versions[0] == '[':
+		var versions []ApiVersion
+		if err := json.Unmarshal(document.Versions, &versions); err != nil {
+			return nil, fmt.Errorf("invalid version document: %w", err)
+		}
+		return versions, nil
+	case len(document.Versions) > 0:
+		var nested struct {
+			Values []ApiVersion `json:"values"`
+		}
+		if err := json.Unmarshal(document.Versions, &nested); err != nil {
+			return nil, fmt.Errorf("invalid version document: %w", err)
+		}
+		return nested.Values, nil
+	case document.Version != nil:
+		return []ApiVersion{*document.Version}, nil
+	}
+	return nil, errors.New("document does not list versions")
+}
+
+func matchVersion(versions []ApiVersion, version string) *ApiVersion {
+	var match *ApiVersion
+	for i := range versions {
+		v := &versions[i]
+		switch {
+		case version == "":
+			if strings.EqualFold(v.Status, "CURRENT") || strings.EqualFold(v.Status, "STABLE") {
+				return v
+			}
+		case v.Id == version:
+			return v
+		case strings.HasPrefix(v.Id, version+"."):
+			if match == nil || strings.EqualFold(v.Status, "CURRENT") {
+				match = v
+			}
+		}
+	}
+	return match
+}
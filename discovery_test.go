@@ -0,0 +1,144 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleDiscoveryCache() {
+	// Setup a test HTTP server with an OpenStack style version document at its root
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"versions": [
+  {"id": "v2.0", "status": "SUPPORTED", "links": [{"href": "/v2/", "rel": "self"}]},
+  {"id": "v2.1", "status": "CURRENT", "version": "2.90", "min_version": "2.1",
+   "links": [{"href": "/v2.1/", "rel": "self"}]}
+]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"servers": []}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	clock := restclienttest.NewFakeClock(time.Now())
+	discovery := restclient.NewDiscoveryCache(client, time.Hour)
+	discovery.Clock = clock
+
+	for i := 0; i < 2; i++ {
+		baseUrl, err := discovery.Resolve(context.Background(), "/", "v2")
+		if err != nil {
+			log.Fatal(err)
+		}
+		compute := restclient.NewClient()
+		compute.BaseUrl = baseUrl
+		// the request URL is relative, so that it resolves within the version
+		err = compute.Exchange("GET", "servers", nil, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	clock.Advance(2 * time.Hour)
+	v, err := discovery.ResolveVersion(context.Background(), "/", "")
+	fmt.Println(v.Id, v.MinVersion, v.Version, err)
+	// Output:
+	// RECV /
+	// RECV /v2.1/servers
+	// RECV /v2.1/servers
+	// RECV /
+	// v2.1 2.1 2.90 <nil>
+}
+
+func ExampleDiscoveryCache_multipleChoices() {
+	// Setup a test HTTP server with a Keystone style version document
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultipleChoices)
+		_, _ = w.Write([]byte(`{"versions": {"values": [
+  {"id": "v3.14", "status": "stable", "links": [{"href": "http://identity.example.com/v3/", "rel": "self"}]}
+]}}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	discovery := restclient.NewDiscoveryCache(client, 0)
+
+	baseUrl, err := discovery.Resolve(context.Background(), ts.URL, "")
+	fmt.Println(baseUrl, err)
+	_, err = discovery.Resolve(context.Background(), ts.URL, "v2")
+	fmt.Println(err != nil)
+	// Output:
+	// http://identity.example.com/v3/ <nil>
+	// true
+}
+
+func ExampleDiscoveryCache_slowRoot() {
+	// Setup a test HTTP server where the version document of one API is slow to retrieve
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow/" {
+			close(requested)
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"version": {"id": "v1", "status": "CURRENT", "links": [{"href": "%sv1/", "rel": "self"}]}}`,
+			r.URL.Path)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	discovery := restclient.NewDiscoveryCache(client, time.Hour)
+
+	slow := make(chan error)
+	go func() {
+		_, err := discovery.Resolve(context.Background(), "/slow/", "v1")
+		slow <- err
+	}()
+	<-requested
+
+	// other roots are resolved, and callers of the slow root give up with their context
+	fast, err := discovery.Resolve(context.Background(), "/fast/", "v1")
+	fmt.Println(fast.Path, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = discovery.Resolve(ctx, "/slow/", "v1")
+	fmt.Println(err)
+
+	close(release)
+	fmt.Println(<-slow)
+	// Output:
+	// /fast/v1/ <nil>
+	// context deadline exceeded
+	// <nil>
+}
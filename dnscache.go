@@ -0,0 +1,350 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDnsTTL           = time.Minute
+	defaultDnsNegativeTTL   = 5 * time.Second
+	defaultDnsLookupTimeout = 10 * time.Second
+	// defaultDialFallbackDelay is that of net.Dialer before racing addresses of the other IP family
+	defaultDialFallbackDelay = 300 * time.Millisecond
+	// minDialAttemptTimeout is the least time given to each address when the dial timeout is split
+	// among them, as done by net.Dialer
+	minDialAttemptTimeout = 2 * time.Second
+)
+
+// DnsCache caches the addresses of hosts resolved when connecting, so that high-volume clients
+// don't add the latency of DNS to every new connection and survive brief outages of the resolver.
+// It is set as the DnsCache of a Client, such as
+//
+//	client.DnsCache = restclient.NewDnsCache()
+//
+// Since the Go resolver does not expose the TTLs of records, addresses are cached for the TTL
+// of the cache. Zero values are replaced with the defaults noted on each field.
+//
+// As with net.Dialer, the addresses of a host are dialed in turn, each given a share of the dial
+// timeout, and those of the other IP family are raced after the dialer's FallbackDelay.
+type DnsCache struct {
+	// TTL is how long resolved addresses are used, which defaults to 1 minute
+	TTL time.Duration
+	// NegativeTTL is how long a host that was not found is remembered as such, which defaults to
+	// 5 seconds. A negative value disables negative caching.
+	NegativeTTL time.Duration
+	// StaleTTL, when positive, is how long after expiry addresses are still used when resolving
+	// them again fails for a reason other than the host not being found, such as a timeout
+	StaleTTL time.Duration
+	// LookupHost resolves a host to its addresses, which defaults to that of net.DefaultResolver
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+	// LookupTimeout bounds each lookup, which defaults to 10 seconds. A lookup is shared by the
+	// concurrent dials of the host, so it is not bound by the context of any one of them.
+	LookupTimeout time.Duration
+	// Clock determines the expiry of entries, which defaults to SystemClock
+	Clock Clock
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+	pending map[string]*dnsLookup
+}
+
+type dnsEntry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// dnsLookup is an in-flight lookup that concurrent dials of the same host wait for
+type dnsLookup struct {
+	done  chan struct{}
+	addrs []string
+	err   error
+}
+
+// NewDnsCache creates a DnsCache with the default configuration
+func NewDnsCache() *DnsCache {
+	return &DnsCache{}
+}
+
+// Lookup returns the addresses of the host, from the cache when available
+func (d *DnsCache) Lookup(ctx context.Context, host string) ([]string, error) {
+	now := d.clock().Now()
+
+	d.mu.Lock()
+	if d.entries == nil {
+		d.entries = make(map[string]*dnsEntry)
+	}
+	if d.pending == nil {
+		d.pending = make(map[string]*dnsLookup)
+	}
+	entry := d.entries[host]
+	if entry != nil && now.Before(entry.expires) {
+		d.mu.Unlock()
+		return entry.addrs, entry.err
+	}
+	lookup, inFlight := d.pending[host]
+	if !inFlight {
+		lookup = &dnsLookup{done: make(chan struct{})}
+		d.pending[host] = lookup
+		go d.resolve(host, lookup, entry, now)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-lookup.done:
+		return lookup.addrs, lookup.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve performs the lookup of the host shared by its concurrent dials, which is bounded by the
+// LookupTimeout rather than any one of their contexts, so that a canceled dial doesn't fail the others
+func (d *DnsCache) resolve(host string, lookup *dnsLookup, entry *dnsEntry, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.lookupTimeout())
+	defer cancel()
+	lookup.addrs, lookup.err = d.lookupHost(ctx, host)
+
+	d.mu.Lock()
+	delete(d.pending, host)
+	switch {
+	case lookup.err == nil:
+		d.entries[host] = &dnsEntry{addrs: lookup.addrs, expires: now.Add(d.ttl())}
+	case isDnsNotFound(lookup.err):
+		if negativeTTL := d.negativeTTL(); negativeTTL > 0 {
+			d.entries[host] = &dnsEntry{err: lookup.err, expires: now.Add(negativeTTL)}
+		}
+	case entry != nil && entry.err == nil && now.Before(entry.expires.Add(d.StaleTTL)):
+		// serves the stale addresses while the resolver is failing
+		lookup.addrs, lookup.err = entry.addrs, nil
+	}
+	d.mu.Unlock()
+	close(lookup.done)
+}
+
+// Flush discards all cached entries
+func (d *DnsCache) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = make(map[string]*dnsEntry)
+}
+
+// dialContext dials the address with dialer, resolving its host with the cache. Like net.Dialer,
+// the addresses of the first address's IP family are dialed in turn and those of the other family
+// are raced after the dialer's FallbackDelay.
+func (d *DnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		addrs, err := d.Lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+		}
+		deadline := time.Now().Add(dialer.Timeout)
+		if dialer.Timeout <= 0 {
+			deadline = time.Time{}
+		}
+		primaries, fallbacks := partitionAddrs(addrs)
+		if len(fallbacks) == 0 || dialer.FallbackDelay < 0 {
+			return dialSerial(ctx, dialer, network, port, addrs, deadline)
+		}
+		return dialParallel(ctx, dialer, network, port, primaries, fallbacks, deadline)
+	}
+}
+
+// partitionAddrs splits the addresses into those of the first address's IP family and the others
+func partitionAddrs(addrs []string) (primaries []string, fallbacks []string) {
+	primaryIPv4 := isIPv4(addrs[0])
+	for _, addr := range addrs {
+		if isIPv4(addr) == primaryIPv4 {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
+
+func isIPv4(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() != nil
+}
+
+// dialSerial dials the addresses in turn until one connects. When there is a deadline, each
+// address is given an equal share of the remaining time, so that an unreachable address doesn't
+// consume the whole dial timeout.
+func dialSerial(ctx context.Context, dialer *net.Dialer, network string, port string, addrs []string,
+	deadline time.Time) (net.Conn, error) {
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	var lastErr error
+	for i, addr := range addrs {
+		attemptCtx, cancel := ctx, func() {}
+		if !deadline.IsZero() {
+			attemptCtx, cancel = context.WithDeadline(ctx, partialDeadline(deadline, len(addrs)-i))
+		}
+		conn, err := dialer.DialContext(attemptCtx, network, net.JoinHostPort(addr, port))
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// partialDeadline returns the deadline of an attempt that shares the time remaining until deadline
+// with the remaining attempts
+func partialDeadline(deadline time.Time, remaining int) time.Time {
+	timeRemaining := time.Until(deadline)
+	if timeRemaining <= 0 {
+		return deadline
+	}
+	timeout := timeRemaining / time.Duration(remaining)
+	if timeout < minDialAttemptTimeout {
+		if timeRemaining < minDialAttemptTimeout {
+			timeout = timeRemaining
+		} else {
+			timeout = minDialAttemptTimeout
+		}
+	}
+	return time.Now().Add(timeout)
+}
+
+type dialResult struct {
+	conn    net.Conn
+	err     error
+	primary bool
+}
+
+// dialParallel races the fallback addresses against the primary ones, starting them after the
+// dialer's FallbackDelay or as soon as the primary addresses fail, and returns the first connection
+func dialParallel(ctx context.Context, dialer *net.Dialer, network string, port string,
+	primaries []string, fallbacks []string, deadline time.Time) (net.Conn, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan dialResult)
+	returned := make(chan struct{})
+	defer close(returned)
+
+	dial := func(addrs []string, primary bool) {
+		conn, err := dialSerial(ctx, dialer, network, port, addrs, deadline)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary}:
+		case <-returned:
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+	go dial(primaries, true)
+
+	fallbackDelay := dialer.FallbackDelay
+	if fallbackDelay == 0 {
+		fallbackDelay = defaultDialFallbackDelay
+	}
+	fallbackTimer := time.NewTimer(fallbackDelay)
+	defer fallbackTimer.Stop()
+
+	var primaryErr error
+	fallbackStarted := false
+	pending := 1
+	for {
+		select {
+		case <-fallbackTimer.C:
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				go dial(fallbacks, false)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.conn, nil
+			}
+			if result.primary {
+				primaryErr = result.err
+				if !fallbackStarted {
+					fallbackStarted = true
+					pending++
+					go dial(fallbacks, false)
+				}
+			} else if primaryErr == nil {
+				primaryErr = result.err
+			}
+			if pending == 0 {
+				return nil, primaryErr
+			}
+		}
+	}
+}
+
+func (d *DnsCache) lookupHost(ctx context.Context, host string) ([]string, error) {
+	if d.LookupHost != nil {
+		return d.LookupHost(ctx, host)
+	}
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+func (d *DnsCache) lookupTimeout() time.Duration {
+	if d.LookupTimeout > 0 {
+		return d.LookupTimeout
+	}
+	return defaultDnsLookupTimeout
+}
+
+func (d *DnsCache) ttl() time.Duration {
+	if d.TTL > 0 {
+		return d.TTL
+	}
+	return defaultDnsTTL
+}
+
+func (d *DnsCache) negativeTTL() time.Duration {
+	if d.NegativeTTL != 0 {
+		return d.NegativeTTL
+	}
+	return defaultDnsNegativeTTL
+}
+
+func (d *DnsCache) clock() Clock {
+	if d.Clock != nil {
+		return d.Clock
+	}
+	return SystemClock
+}
+
+func isDnsNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+)
+
+func ExampleDnsCache() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	tsUrl, _ := url.Parse(ts.URL)
+
+	// a resolver for the example's host, which counts the lookups
+	lookups := 0
+	resolverDown := false
+	lookupHost := func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		if resolverDown {
+			return nil, &net.DNSError{Err: "i/o timeout", Name: host, IsTimeout: true}
+		}
+		if host != "api.example.test" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{tsUrl.Hostname()}, nil
+	}
+	clock := restclienttest.NewFakeClock(time.Now())
+
+	// Real example starts here
+	cache := restclient.NewDnsCache()
+	cache.TTL = time.Minute
+	cache.StaleTTL = 10 * time.Minute
+	cache.LookupHost = lookupHost
+	cache.Clock = clock
+
+	client := restclient.NewClient()
+	client.SetBaseUrl("http://api.example.test:" + tsUrl.Port())
+	client.DnsCache = cache
+	// new connections for each request
+	client.HttpClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	for i := 0; i < 3; i++ {
+		if err := client.Exchange("GET", "/servers", nil, nil, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println("lookups", lookups)
+
+	// the resolver fails once the entry expired, so the stale addresses are used
+	clock.Advance(2 * time.Minute)
+	resolverDown = true
+	err := client.Exchange("GET", "/servers", nil, nil, nil)
+	fmt.Println(err, "lookups", lookups)
+
+	// hosts that are not found are also cached
+	resolverDown = false
+	for i := 0; i < 2; i++ {
+		_, err = cache.Lookup(context.Background(), "missing.example.test")
+	}
+	var dnsErr *net.DNSError
+	fmt.Println(errors.As(err, &dnsErr) && dnsErr.IsNotFound, "lookups", lookups)
+	// Output:
+	// lookups 1
+	// <nil> lookups 2
+	// true lookups 3
+}
+
+func ExampleDnsCache_Lookup() {
+	// a resolver that is slow to respond
+	release := make(chan struct{})
+	lookupHost := func(ctx context.Context, host string) ([]string, error) {
+		select {
+		case <-release:
+			return []string{"192.0.2.10"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Real example starts here
+	cache := restclient.NewDnsCache()
+	cache.LookupHost = lookupHost
+
+	// the dial that started the lookup is canceled while another dial waits for the same host
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := cache.Lookup(canceledCtx, "api.example.test")
+		canceled <- err
+	}()
+	waiting := make(chan []string)
+	go func() {
+		addrs, err := cache.Lookup(context.Background(), "api.example.test")
+		if err != nil {
+			log.Fatal(err)
+		}
+		waiting <- addrs
+	}()
+
+	cancel()
+	fmt.Println(<-canceled)
+	close(release)
+	fmt.Println(<-waiting)
+	// Output:
+	// context canceled
+	// [192.0.2.10]
+}
+
+func ExampleDnsCache_fallback() {
+	// Setup a test HTTP server, which only listens on IPv4
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	tsUrl, _ := url.Parse(ts.URL)
+
+	// Real example starts here
+	cache := restclient.NewDnsCache()
+	cache.LookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"::1", tsUrl.Hostname()}, nil
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl("http://api.example.test:" + tsUrl.Port())
+	client.DnsCache = cache
+
+	// the IPv6 address can't be reached, so the IPv4 address is used
+	fmt.Println(client.Exchange("GET", "/servers", nil, nil, nil))
+	// Output:
+	// <nil>
+}
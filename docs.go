@@ -38,7 +38,7 @@ Quick example
 	req := &MsgHolder{Msg:"hello"}
 	var resp MsgHolder
 
-	client.Exchange("POST", "/ping", nil,
+	client.ExchangeWithContext(context.Background(), "POST", "/ping", nil,
 		restclient.NewJsonEntity(req), restclient.NewJsonEntity(&resp))
 
   	fmt.Println(resp.Msg)
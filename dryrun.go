@@ -0,0 +1,103 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BuildRequest prepares the request of an exchange without sending it, such as for inspection,
+// queuing, or handing to another transport. The arguments are as described for ExchangeWithContext,
+// where the response entity is absent, so an Accept header can be given WithHeader. The client's
+// Timeout is not applied to the request.
+//
+// When the request content is a ContentWriter, the body of the request must be read or closed
+// to release the writer.
+func (c *Client) BuildRequest(ctx context.Context, method string, urlIn string, query url.Values,
+	reqIn *Entity, opts ...RequestOption) (*http.Request, error) {
+
+	options := newRequestOptions(opts)
+	ctx = options.applyContext(ctx)
+
+	reqUrl, err := c.buildReqUrl(urlIn, query)
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkUrlAllowed(reqUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateEntity(reqIn, "request")
+	if err != nil {
+		return nil, err
+	}
+
+	bodyReader, err := c.buildBodyReader(reqIn)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.buildRequest(ctx, method, reqUrl, bodyReader, reqIn, nil)
+	if err != nil {
+		return nil, err
+	}
+	options.applyHeaders(req)
+	return req, nil
+}
+
+// InterceptRequest processes the request, such as one from BuildRequest, through the client's
+// interceptors without sending it and returns the request as it would have been sent. Each time
+// an interceptor proceeds, it is given an empty 200 OK response.
+//
+// Interceptors that send their own requests, such as an authenticator obtaining a token, still do
+// so, which allows the returned request to carry the credentials that would have been sent.
+func (c *Client) InterceptRequest(req *http.Request) (*http.Request, error) {
+	c.interceptorsMu.RLock()
+	interceptors := c.interceptors
+	c.interceptorsMu.RUnlock()
+
+	var intercepted *http.Request
+	chain := chainInterceptors(interceptors, func(req *http.Request) (*http.Response, error) {
+		intercepted = req
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+
+	resp, err := chain(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if intercepted == nil {
+		return nil, errors.New("interceptors did not proceed with the request")
+	}
+	return intercepted, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+func ExampleClient_BuildRequest() {
+	client := restclient.NewClient()
+	client.SetBaseUrl("https://api.example.com")
+	client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		req.Header.Set("X-Auth-Token", "token1")
+		return next(req)
+	})
+
+	req, err := client.BuildRequest(context.Background(), "POST", "/servers", nil,
+		restclient.NewJsonEntity(map[string]string{"name": "web"}),
+		restclient.WithHeader("X-Request-Id", "req1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(req.Method, req.URL, req.Header.Get("Content-Type"), req.Header.Get("X-Request-Id"),
+		req.Header.Get("X-Auth-Token") == "")
+
+	// the request as it would be sent after the interceptors
+	req, err = client.InterceptRequest(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	fmt.Println(req.Header.Get("X-Auth-Token"), string(body))
+	// Output:
+	// POST https://api.example.com/servers application/json req1 true
+	// token1 {"name":"web"}
+}
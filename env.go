@@ -0,0 +1,115 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envBaseUrl  = "BASE_URL"
+	envTimeout  = "TIMEOUT"
+	envToken    = "TOKEN"
+	envCaBundle = "CA_BUNDLE"
+)
+
+// NewFromEnv creates a Client configured from environment variables named with the given prefix.
+// For example, with a prefix of "MYAPI" the following variables are consulted:
+//
+// MYAPI_BASE_URL sets the base URL of the client.
+//
+// MYAPI_TIMEOUT sets the client timeout and is either a duration, such as "30s", a number of seconds,
+// or "none" for NoTimeout.
+//
+// MYAPI_TOKEN adds a BearerToken interceptor with the given token.
+//
+// MYAPI_CA_BUNDLE is the path of a PEM file containing CA certificates to trust in addition to
+// the system's certificate pool.
+//
+// Variables that are not set or are empty are ignored.
+func NewFromEnv(prefix string) (*Client, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	client := NewClient()
+
+	if baseUrl := os.Getenv(prefix + envBaseUrl); baseUrl != "" {
+		if err := client.SetBaseUrl(baseUrl); err != nil {
+			return nil, fmt.Errorf("invalid %s%s: %w", prefix, envBaseUrl, err)
+		}
+	}
+
+	if timeout := os.Getenv(prefix + envTimeout); timeout != "" {
+		d, err := parseEnvDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s%s: %w", prefix, envTimeout, err)
+		}
+		client.Timeout = d
+	}
+
+	if token := os.Getenv(prefix + envToken); token != "" {
+		client.AddInterceptor(BearerToken(token))
+	}
+
+	if caBundle := os.Getenv(prefix + envCaBundle); caBundle != "" {
+		transport, err := caBundleTransport(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s%s: %w", prefix, envCaBundle, err)
+		}
+		client.HttpClient = &http.Client{Transport: transport}
+	}
+
+	return client, nil
+}
+
+// parseEnvDuration accepts either a Go duration string, a plain number of seconds, or "none"
+func parseEnvDuration(value string) (time.Duration, error) {
+	if strings.EqualFold(value, "none") {
+		return NoTimeout, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func caBundleTransport(path string) (*http.Transport, error) {
+	pemContent, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pemContent) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+func ExampleNewFromEnv() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s %s\n", r.URL.Path, r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	// normally these would be set by the deployment environment
+	os.Setenv("MYAPI_BASE_URL", ts.URL)
+	os.Setenv("MYAPI_TIMEOUT", "30s")
+	os.Setenv("MYAPI_TOKEN", "abc123")
+	defer os.Unsetenv("MYAPI_BASE_URL")
+	defer os.Unsetenv("MYAPI_TIMEOUT")
+	defer os.Unsetenv("MYAPI_TOKEN")
+
+	// Real example starts here
+	client, err := restclient.NewFromEnv("MYAPI")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = client.Exchange("GET", "/status", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(client.Timeout)
+	// Output:
+	// RECV /status Bearer abc123
+	// 30s
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrClientTimeout is matched, via errors.Is, by errors of exchanges that exceeded the
+	// client's Timeout
+	ErrClientTimeout = errors.New("client timeout")
+	// ErrCanceled is matched, via errors.Is, by errors of exchanges whose context, as given by the
+	// caller, was canceled or exceeded its deadline
+	ErrCanceled = errors.New("canceled")
+)
+
+// contextError marks an error caused by the end of the exchange's context with one of
+// ErrClientTimeout, ErrCanceled, or ErrResponseHeaderTimeout while retaining the original error,
+// such as a *url.Error wrapping context.DeadlineExceeded
+type contextError struct {
+	kind  error
+	cause error
+}
+
+func (e *contextError) Error() string {
+	return e.kind.Error() + ": " + e.cause.Error()
+}
+
+func (e *contextError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *contextError) Unwrap() error {
+	return e.cause
+}
+
+// classifyContextError distinguishes errors caused by the caller's context from those caused by the
+// client's own timeout, which is applied by timeoutCtx. Other errors, such as transport errors,
+// are returned as is.
+func classifyContextError(callerCtx context.Context, timeoutCtx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if callerCtx.Err() != nil {
+		return &contextError{kind: ErrCanceled, cause: err}
+	}
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return &contextError{kind: ErrClientTimeout, cause: err}
+	}
+	return err
+}
+
+// DecodeError indicates that the content of a successful response could not be decoded. It retains
+// the beginning of the raw body, so that unexpected content, such as an HTML error page from a proxy
+// or truncated JSON, can be diagnosed.
+type DecodeError struct {
+	ContentType MimeType
+	// Body is the raw response body, limited to the first 1000 bytes
+	Body []byte
+	// Truncated indicates that the response body was longer than Body
+	Truncated bool
+	// Offset is the byte offset in the body at which decoding failed or -1 when not known
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Offset >= 0 {
+		return fmt.Sprintf("failed to decode response at offset %d: %s body=[%s]", e.Offset, e.Err, e.Body)
+	}
+	return fmt.Sprintf("failed to decode response: %s body=[%s]", e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// bodyCapture retains up to limit bytes written to it
+type bodyCapture struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *bodyCapture) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buffer.Len()
+	if len(p) > remaining {
+		b.buffer.Write(p[:remaining])
+		b.truncated = true
+	} else {
+		b.buffer.Write(p)
+	}
+	return len(p), nil
+}
+
+func newDecodeError(contentType MimeType, capture *bodyCapture, err error) *DecodeError {
+	decodeErr := &DecodeError{
+		ContentType: contentType,
+		Body:        capture.buffer.Bytes(),
+		Truncated:   capture.truncated,
+		Offset:      -1,
+		Err:         err,
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		decodeErr.Offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		decodeErr.Offset = typeErr.Offset
+	}
+	return decodeErr
+}
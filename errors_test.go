@@ -0,0 +1,51 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleErrClientTimeout() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.Timeout = 50 * time.Millisecond
+
+	err := client.Exchange("GET", "/slow", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrClientTimeout), errors.Is(err, restclient.ErrCanceled))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err = client.ExchangeWithContext(ctx, "GET", "/slow", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrClientTimeout), errors.Is(err, restclient.ErrCanceled))
+	// Output:
+	// true false
+	// false true
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FollowPolicy configures how Client.ExchangeAndFollow retrieves the resource given by the Location
+// of a created or accepted response. Zero values are replaced with the defaults noted on each field.
+type FollowPolicy struct {
+	// Done, if set, is called after each retrieval of the resource to determine if it has reached a
+	// terminal state, such as by checking a status field of the content. The resource is polled until
+	// Done returns true or an error. When nil, the resource is retrieved once.
+	Done func() (bool, error)
+	// Interval is the delay between polls of the resource, which defaults to 1s
+	Interval time.Duration
+	// MaxInterval caps the delay between polls, which defaults to 30s
+	MaxInterval time.Duration
+	// Multiplier increases the interval after each poll, which defaults to 1 for a fixed interval
+	Multiplier float64
+	// Clock is used for the delays between polls, which defaults to SystemClock
+	Clock Clock
+}
+
+// ExchangeAndFollow performs the exchange and, when the response is a 201 Created or 202 Accepted
+// with a Location header, retrieves the resource at that location into resource. This is the
+// typical pattern of APIs that create resources or start jobs asynchronously. The policy's Done
+// function, if set, is used to keep polling the resource until it reaches a terminal state.
+//
+// When the response is not followed, its content, if any, is decoded into resource. The given
+// opts are applied to each exchange.
+func (c *Client) ExchangeAndFollow(ctx context.Context, method string, urlIn string, query url.Values,
+	reqIn *Entity, resource *Entity, policy FollowPolicy, opts ...RequestOption) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	reqUrl, err := c.buildReqUrl(urlIn, query)
+	if err != nil {
+		return err
+	}
+
+	// the content is captured as-is since the response may be empty or describe the operation
+	// rather than the resource
+	var info ResponseInfo
+	var initial *Entity
+	if resource != nil {
+		initial = &Entity{ContentType: resource.ContentType, Accept: resource.Accept, Content: []byte(nil)}
+	}
+	// limits the capacity, so that appending options doesn't modify the caller's slice
+	opts = opts[:len(opts):len(opts)]
+	err = c.ExchangeWithContext(ctx, method, urlIn, query, reqIn, initial, append(opts, WithResponseInfo(&info))...)
+	if err != nil {
+		return err
+	}
+
+	locationHeader := info.Header.Get("Location")
+	if (info.StatusCode != http.StatusCreated && info.StatusCode != http.StatusAccepted) || locationHeader == "" {
+		return c.decodeCapturedContent(resource, initial, info.Header)
+	}
+	location, err := reqUrl.Parse(locationHeader)
+	if err != nil {
+		return fmt.Errorf("failed to parse location %s: %w", locationHeader, err)
+	}
+
+	poller := &Poller{
+		Client:    c,
+		StatusUrl: location.String(),
+		Content:   resource,
+		Status: func() (PollStatus, error) {
+			if policy.Done == nil {
+				return PollSucceeded, nil
+			}
+			done, err := policy.Done()
+			if done {
+				return PollSucceeded, err
+			}
+			return PollPending, err
+		},
+		Interval:    policy.Interval,
+		MaxInterval: policy.MaxInterval,
+		Multiplier:  policy.Multiplier,
+		Clock:       policy.Clock,
+		Options:     opts,
+	}
+	return poller.Poll(ctx)
+}
+
+// decodeCapturedContent processes the content captured as []byte by the captured entity into
+// resource, as if it had been given to the exchange. Empty content is ignored.
+func (c *Client) decodeCapturedContent(resource *Entity, captured *Entity, header http.Header) error {
+	if resource == nil {
+		return nil
+	}
+	body, _ := captured.Content.([]byte)
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	resp := &http.Response{Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}
+	err := c.processResponseContent(resource, resp)
+	if err != nil {
+		return err
+	}
+	return validateEntity(resource, "response")
+}
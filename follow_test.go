@@ -0,0 +1,107 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleClient_ExchangeAndFollow() {
+	// Setup a test HTTP server where the created server takes a couple of polls to become active
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "/servers/abc")
+			w.WriteHeader(http.StatusAccepted)
+		case "GET":
+			polls++
+			status := "BUILD"
+			if polls >= 3 {
+				status = "ACTIVE"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"id": "abc", "status": %q}`, status)
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, 5*time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var server struct {
+		Id     string
+		Status string
+	}
+	err := client.ExchangeAndFollow(context.Background(), "POST", "/servers", nil,
+		restclient.NewJsonEntity(map[string]string{"name": "web"}),
+		restclient.NewJsonEntity(&server),
+		restclient.FollowPolicy{
+			Done: func() (bool, error) {
+				fmt.Println("status", server.Status)
+				return server.Status == "ACTIVE", nil
+			},
+			Interval: 5 * time.Second,
+			Clock:    clock,
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(server.Id, server.Status)
+	// Output:
+	// status BUILD
+	// status BUILD
+	// status ACTIVE
+	// abc ACTIVE
+}
+
+func ExampleClient_ExchangeAndFollow_notFollowed() {
+	// Setup a test HTTP server that creates the resource synchronously
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "abc", "status": "ACTIVE"}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var server struct {
+		Id     string
+		Status string
+	}
+	err := client.ExchangeAndFollow(context.Background(), "POST", "/servers", nil,
+		restclient.NewJsonEntity(map[string]string{"name": "web"}),
+		restclient.NewJsonEntity(&server),
+		restclient.FollowPolicy{})
+	fmt.Println(err, server.Id, server.Status)
+	// Output:
+	// <nil> abc ACTIVE
+}
@@ -1,3 +1,9 @@
 module github.com/racker/go-restclient
 
 go 1.13
+
+require (
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
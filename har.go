@@ -0,0 +1,308 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const harVersion = "1.2"
+
+// HarRecorder records exchanges in the HTTP Archive (HAR) format, which can be loaded into browser
+// developer tools and HAR analyzers, such as when debugging API issues. Use the Intercept method as
+// the Interceptor, such as
+//
+// client.AddInterceptor(recorder.Intercept)
+//
+// Since the recorder captures the request as it is passed along, it should be added after any
+// interceptors that modify the request, such as authentication. Sensitive headers, query
+// parameters, and JSON fields are masked according to Redaction.
+type HarRecorder struct {
+	// Redaction determines the values masked in the recording, which defaults to DefaultRedactionPolicy
+	Redaction *RedactionPolicy
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHarRecorder creates an empty HarRecorder
+func NewHarRecorder() *HarRecorder {
+	return &HarRecorder{}
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectUrl string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	Dns     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	Ssl     float64 `json:"ssl"`
+}
+
+// Intercept is an Interceptor that records the exchange. The entry is recorded once the response
+// body has been closed, which Exchange does automatically, or immediately if the request failed.
+func (r *HarRecorder) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	redaction := r.Redaction
+	if redaction == nil {
+		redaction = &DefaultRedactionPolicy
+	}
+
+	reqBody, err := ReadRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := harEntry{
+		StartedDateTime: time.Now(),
+		Request:         buildHarRequest(req, reqBody, redaction),
+		Timings: harTimings{
+			Blocked: -1,
+			Dns:     -1,
+			Connect: -1,
+			Ssl:     -1,
+		},
+	}
+
+	resp, err := next(req)
+	waited := time.Since(entry.StartedDateTime)
+	entry.Timings.Wait = durationMillis(waited)
+	if err != nil {
+		entry.Response = harResponse{
+			Cookies:  []harNameValue{},
+			Headers:  []harNameValue{},
+			BodySize: -1,
+		}
+		entry.Time = entry.Timings.Wait
+		entry.Comment = err.Error()
+		r.add(entry)
+		return nil, err
+	}
+
+	resp.Body = &harResponseBody{
+		ReadCloser: resp.Body,
+		onClose: func(content []byte) {
+			entry.Timings.Receive = durationMillis(time.Since(entry.StartedDateTime) - waited)
+			entry.Time = entry.Timings.Wait + entry.Timings.Receive
+			entry.Response = buildHarResponse(resp, content, redaction)
+			r.add(entry)
+		},
+	}
+	return resp, nil
+}
+
+func (r *HarRecorder) add(entry harEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// Len returns the number of exchanges recorded so far
+func (r *HarRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// WriteTo writes the recorded exchanges as a HAR document
+func (r *HarRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	var doc harLog
+	doc.Log.Version = harVersion
+	doc.Log.Creator = harCreator{Name: "go-restclient", Version: harVersion}
+	doc.Log.Entries = append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	content, err := json.MarshalIndent(&doc, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	n, err := w.Write(content)
+	return int64(n), err
+}
+
+// WriteFile writes the recorded exchanges as a HAR document to the named file
+func (r *HarRecorder) WriteFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create HAR file: %w", err)
+	}
+	_, err = r.WriteTo(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// harResponseBody captures the content of the response body as it is read and reports it when closed
+type harResponseBody struct {
+	io.ReadCloser
+	buffer  bytes.Buffer
+	onClose func(content []byte)
+	once    sync.Once
+}
+
+func (b *harResponseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buffer.Write(p[:n])
+	return n, err
+}
+
+func (b *harResponseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.onClose(b.buffer.Bytes())
+	})
+	return err
+}
+
+func buildHarRequest(req *http.Request, body []byte, redaction *RedactionPolicy) harRequest {
+	redactedUrl := redaction.RedactUrl(req.URL)
+	harReq := harRequest{
+		Method:      req.Method,
+		Url:         redactedUrl.String(),
+		HttpVersion: req.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harNameValues(redaction.RedactHeader(req.Header)),
+		QueryString: harNameValues(redactedUrl.Query()),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	if body != nil {
+		harReq.PostData = &harPostData{
+			MimeType: req.Header.Get(headerContentType),
+			Text:     string(redaction.RedactJson(body)),
+		}
+	}
+	return harReq
+}
+
+func buildHarResponse(resp *http.Response, content []byte, redaction *RedactionPolicy) harResponse {
+	harResp := harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HttpVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harNameValues(redaction.RedactHeader(resp.Header)),
+		Content: harContent{
+			Size:     int64(len(content)),
+			MimeType: resp.Header.Get(headerContentType),
+		},
+		RedirectUrl: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(content)),
+	}
+	if utf8.Valid(content) {
+		harResp.Content.Text = string(redaction.RedactJson(content))
+	} else {
+		harResp.Content.Text = base64.StdEncoding.EncodeToString(content)
+		harResp.Content.Encoding = "base64"
+	}
+	return harResp
+}
+
+// harNameValues converts headers or query values into name-value pairs sorted by name
+func harNameValues(values map[string][]string) []harNameValue {
+	pairs := []harNameValue{}
+	for name, list := range values {
+		for _, value := range list {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Name < pairs[j].Name
+	})
+	return pairs
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+func ExampleHarRecorder() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"Msg":"pong"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	recorder := restclient.NewHarRecorder()
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.BearerToken("secret"))
+	client.AddInterceptor(recorder.Intercept)
+
+	err := client.Exchange("POST", "/ping", nil,
+		restclient.NewTextEntity("ping"), restclient.NewTextEntity(""))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// normally recorder.WriteFile would be used to save the HAR file
+	var buffer bytes.Buffer
+	recorder.WriteTo(&buffer)
+
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					Method  string
+					Headers []struct{ Name, Value string }
+				}
+				Response struct {
+					Status  int
+					Content struct{ Text string }
+				}
+			}
+		}
+	}
+	json.Unmarshal(buffer.Bytes(), &har)
+
+	entry := har.Log.Entries[0]
+	fmt.Println(entry.Request.Method, entry.Response.Status, entry.Response.Content.Text)
+	for _, header := range entry.Request.Headers {
+		if header.Name == "Authorization" {
+			fmt.Println(header.Name, header.Value)
+		}
+	}
+	// Output:
+	// POST 200 {"Msg":"pong"}
+	// Authorization REDACTED
+}
+
+func ExampleHarRecorder_file() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %s\n", r.ContentLength, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("file content")
+	file.Seek(0, io.SeekStart)
+
+	// Real example starts here
+	recorder := restclient.NewHarRecorder()
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(recorder.Intercept)
+
+	err = client.Exchange("PUT", "/objects/file", nil,
+		restclient.NewReaderEntity(file, restclient.TextType, 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	recorder.WriteTo(&buffer)
+
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					PostData struct{ Text string }
+				}
+			}
+		}
+	}
+	json.Unmarshal(buffer.Bytes(), &har)
+	fmt.Println(har.Log.Entries[0].Request.PostData.Text)
+	// Output:
+	// RECV 12 file content
+	// file content
+}
@@ -1,38 +1,69 @@
 package restclient
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
 const authTimeout = 60 * time.Second
 
-type identityV2AuthenticatorImpl struct {
-	username string
-	password string
-	apikey   string
+// IdentityV2Auth authenticates requests with tokens obtained from Rackspace Cloud Identity v2.0.
+// Use the Intercept method as the Interceptor of a Client.
+type IdentityV2Auth struct {
+	credentials CredentialsProvider
+	restClient  *Client
+	clock       Clock
 
-	restClient *Client
+	tokenHeader    TokenHeader
+	passcodePrompt PasscodePrompt
+	revokeOnClose  bool
+	// impersonate is the username of the user to impersonate, if any
+	impersonate           string
+	impersonationLifetime time.Duration
 
-	token           string
-	tokenExpiration time.Time
+	mu                      sync.Mutex
+	token                   string
+	tokenExpiration         time.Time
+	tokenCredentials        Credentials
+	tenantId                string
+	roles                   []string
+	catalog                 []CatalogService
+	impersonationToken      string
+	impersonationExpiration time.Time
+	impersonationTenantId   string
+	impersonationRoles      []string
+	impersonationCatalog    []CatalogService
 }
 
 // IdentityV2Authenticator provides an implementation of the Rackspace Cloud Identity v2.0
-// authentication flow.
+// authentication flow. It is the same as NewIdentityV2Auth, but only provides the Interceptor.
 // The identityUrl should be the base URL of the Identity endpoint, such as "https://identity.api.rackspacecloud.com".
 // Either password or apikey can be provided with the other passed an empty string.
 //
+// Options, such as WithClock, can be given to customize the authenticator. When the
+// WithCredentialsProvider option is given, the username, password, and apikey are ignored.
+//
 // Info about Identity v2.0 is available at https://developer.rackspace.com/docs/cloud-identity/v2/
-func IdentityV2Authenticator(identityUrl string, username string, password string, apikey string) (Interceptor, error) {
-	if username == "" {
-		return nil, errors.New("username is required")
-	}
-	if password == "" && apikey == "" {
-		return nil, errors.New("password or Apikey is required")
+func IdentityV2Authenticator(identityUrl string, username string, password string, apikey string,
+	opts ...IdentityV2Option) (Interceptor, error) {
+	auth, err := NewIdentityV2Auth(identityUrl, username, password, apikey, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return auth.Intercept, nil
+}
+
+// NewIdentityV2Auth creates an IdentityV2Auth, where the arguments are as described for
+// IdentityV2Authenticator
+func NewIdentityV2Auth(identityUrl string, username string, password string, apikey string,
+	opts ...IdentityV2Option) (*IdentityV2Auth, error) {
 
 	// looks slightly convoluted, but dogfood our own library to access the Identity REST API
 	restClient := NewClient()
@@ -42,14 +73,98 @@ func IdentityV2Authenticator(identityUrl string, username string, password strin
 	}
 	restClient.Timeout = authTimeout
 
-	impl := &identityV2AuthenticatorImpl{
-		username:   username,
-		password:   password,
-		apikey:     apikey,
-		restClient: restClient,
+	auth := &IdentityV2Auth{
+		credentials: StaticCredentials(Credentials{
+			Username: username,
+			Password: password,
+			Apikey:   apikey,
+		}),
+		restClient:  restClient,
+		clock:       SystemClock,
+		tokenHeader: AuthTokenHeader,
+	}
+	for _, opt := range opts {
+		opt(auth)
+	}
+	if static, ok := auth.credentials.(staticCredentials); ok {
+		if err := validateIdentityCredentials(Credentials(static)); err != nil {
+			return nil, err
+		}
+	}
+
+	return auth, nil
+}
+
+// IdentityV2Option customizes the authenticator created by IdentityV2Authenticator or NewIdentityV2Auth
+type IdentityV2Option func(a *IdentityV2Auth)
+
+// WithClock sets the clock used to determine when the authentication token has expired
+func WithClock(clock Clock) IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.clock = clock
 	}
+}
+
+// WithCredentialsProvider sets the provider of the username along with the password or apikey,
+// which is consulted for each request. When the provided credentials change, such as after rotation,
+// a new token is obtained with them.
+func WithCredentialsProvider(provider CredentialsProvider) IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.credentials = provider
+	}
+}
 
-	return impl.intercept, nil
+// WithTokenHeader sets the header that conveys the token in requests, which defaults to
+// AuthTokenHeader. Requests to Identity itself always use X-Auth-Token.
+func WithTokenHeader(header TokenHeader) IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.tokenHeader = header
+	}
+}
+
+// WithRevokeOnClose revokes the tokens obtained by the authenticator when its Close method is
+// called, so that short-lived automation doesn't leave valid tokens lingering
+func WithRevokeOnClose() IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.revokeOnClose = true
+	}
+}
+
+// ErrPasscodeRequired is returned when Identity requires multi-factor authentication, but the
+// authenticator was not given a PasscodePrompt via WithPasscodePrompt
+var ErrPasscodeRequired = errors.New("multi-factor passcode required")
+
+// PasscodePrompt obtains the multi-factor authentication passcode, such as by prompting the user
+// at a terminal or reading from an OTP generator
+type PasscodePrompt func(ctx context.Context) (string, error)
+
+// WithPasscodePrompt enables the multi-factor authentication flow, where Identity responds to the
+// password credentials with a session that requires a passcode. The prompt is called to obtain the
+// passcode each time a token is obtained.
+func WithPasscodePrompt(prompt PasscodePrompt) IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.passcodePrompt = prompt
+	}
+}
+
+// WithImpersonation uses the RAX-AUTH impersonation flow, where the credentials are those of an
+// admin, such as a Racker, who obtains a token on behalf of the given user. That impersonation token
+// is injected into requests. A lifetime of zero uses the default lifetime of impersonation tokens.
+func WithImpersonation(username string, lifetime time.Duration) IdentityV2Option {
+	return func(a *IdentityV2Auth) {
+		a.impersonate = username
+		a.impersonationLifetime = lifetime
+	}
+}
+
+func validateIdentityCredentials(credentials Credentials) error {
+	if credentials.Username == "" {
+		return errors.New("username is required")
+	}
+	if credentials.Password == "" && credentials.Apikey == "" {
+		return errors.New("password or Apikey is required")
+	}
+	return nil
 }
 
 type identityAuthApikeyReq struct {
@@ -70,54 +185,372 @@ type identityAuthPasswordReq struct {
 	} `json:"auth"`
 }
 
+type identityAuthPasscodeReq struct {
+	Auth struct {
+		Credentials struct {
+			Passcode string `json:"passcode"`
+		} `json:"RAX-AUTH:passcodeCredentials"`
+	} `json:"auth"`
+}
+
+// mfaSessionPattern picks out the session of a header such as
+// WWW-Authenticate: OS-MF sessionId='1a2b3c', factor='PASSCODE'
+var mfaSessionPattern = regexp.MustCompile(`^OS-MF\s+sessionId='([^']+)'`)
+
+type identityImpersonationReq struct {
+	Impersonation struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		ExpireInSeconds int `json:"expire-in-seconds,omitempty"`
+	} `json:"RAX-AUTH:impersonation"`
+}
+
 // identityAuthResp only picks out the fields needed and ignores the majority of response content
 type identityAuthResp struct {
 	Access struct {
 		Token struct {
 			Id      string
 			Expires time.Time
+			Tenant  struct {
+				Id string
+			}
 		}
+		User struct {
+			Id    string
+			Name  string
+			Roles []struct {
+				Name string
+			}
+		}
+		ServiceCatalog []CatalogService
 	}
 }
 
-func (a *identityV2AuthenticatorImpl) intercept(req *http.Request, next NextCallback) (*http.Response, error) {
-	if time.Now().After(a.tokenExpiration) {
-		if err := a.authenticate(); err != nil {
-			return nil, err
+// ErrInvalidToken is matched, via errors.Is, when a token given to ValidateToken is unknown to
+// Identity, expired, or revoked
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenInfo describes a token validated by Identity
+type TokenInfo struct {
+	Id       string
+	Expires  time.Time
+	TenantId string
+	UserId   string
+	Username string
+	Roles    []string
+}
+
+// ValidateToken validates an arbitrary token, such as one received from a caller, with Identity
+// and returns the token's expiration, user, and roles. The request is authenticated by this
+// authenticator's own token, whose user must be permitted to validate tokens, such as by an admin role.
+// Returns an error matching ErrInvalidToken when the token is not valid.
+func (a *IdentityV2Auth) ValidateToken(ctx context.Context, token string) (*TokenInfo, error) {
+	if _, err := a.currentToken(ctx); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	authToken := a.token
+	a.mu.Unlock()
+
+	var resp identityAuthResp
+	err := a.restClient.ExchangeWithContext(ctx, "GET", "/v2.0/tokens/"+url.PathEscape(token), nil,
+		nil, NewJsonEntity(&resp), withAuthToken(authToken))
+	if err != nil {
+		var failed *FailedResponseError
+		if errors.As(err, &failed) && failed.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, failed.Status)
 		}
+		return nil, fmt.Errorf("failed to validate token: %w", err)
+	}
+
+	info := &TokenInfo{
+		Id:       resp.Access.Token.Id,
+		Expires:  resp.Access.Token.Expires,
+		TenantId: resp.Access.Token.Tenant.Id,
+		UserId:   resp.Access.User.Id,
+		Username: resp.Access.User.Name,
+	}
+	info.Roles = resp.roleNames()
+	return info, nil
+}
+
+func (r *identityAuthResp) roleNames() []string {
+	var roles []string
+	for _, role := range r.Access.User.Roles {
+		roles = append(roles, role.Name)
+	}
+	return roles
+}
+
+// Roles returns the names of the roles of the user whose token is injected into requests, which is
+// the impersonated user when WithImpersonation was given. A token is first obtained, if needed.
+// The returned slice is a copy that the caller may modify.
+func (a *IdentityV2Auth) Roles(ctx context.Context) ([]string, error) {
+	if _, err := a.currentToken(ctx); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.currentRoles()...), nil
+}
+
+// HasRole determines if the user whose token is injected into requests has the named role, such as
+// to pre-check permissions and report a better error than a downstream 403 Forbidden. The roles are
+// those given when the token was obtained, so HasRole returns false until the first request or call
+// to Roles.
+func (a *IdentityV2Auth) HasRole(name string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, role := range a.currentRoles() {
+		if role == name {
+			return true
+		}
+	}
+	return false
+}
+
+// currentRoles must be called while holding the lock
+func (a *IdentityV2Auth) currentRoles() []string {
+	if a.impersonate != "" {
+		return a.impersonationRoles
+	}
+	return a.roles
+}
+
+// TenantId returns the tenant of the token that is injected into requests, which is the tenant of
+// the impersonated user when WithImpersonation was given. A token is first obtained, if needed.
+func (a *IdentityV2Auth) TenantId(ctx context.Context) (string, error) {
+	if _, err := a.currentToken(ctx); err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.currentTenantId(), nil
+}
+
+// currentTenantId must be called while holding the lock
+func (a *IdentityV2Auth) currentTenantId() string {
+	if a.impersonate != "" {
+		return a.impersonationTenantId
+	}
+	return a.tenantId
+}
+
+// ServiceCatalog returns the service catalog of the token that is injected into requests, which is
+// the catalog of the impersonated user when WithImpersonation was given. A token is first obtained,
+// if needed.
+func (a *IdentityV2Auth) ServiceCatalog(ctx context.Context) ([]CatalogService, error) {
+	if _, err := a.currentToken(ctx); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.impersonate != "" {
+		return a.impersonationCatalog, nil
+	}
+	return a.catalog, nil
+}
+
+// Intercept is an Interceptor that injects the current token into the request, first obtaining
+// a token if needed. A TenantIdPlaceholder in the path of the request is replaced by the tenant
+// of the token.
+func (a *IdentityV2Auth) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	token, err := a.currentToken(req.Context())
+	if err != nil {
+		return nil, err
 	}
 
 	// inject the auth token into the user's REST request
-	req.Header.Set("x-auth-token", a.token)
+	a.tokenHeader.Set(req, token)
+
+	if strings.Contains(req.URL.Path, TenantIdPlaceholder) {
+		a.mu.Lock()
+		tenantId := a.currentTenantId()
+		a.mu.Unlock()
+		if tenantId == "" {
+			return nil, errors.New("token has no tenant to fill in the request url")
+		}
+		fillPlaceholders(req.URL, map[string]string{"tenantId": tenantId})
+	}
 
 	return next(req)
 }
 
-func (a *identityV2AuthenticatorImpl) authenticate() error {
+// currentToken returns the token, first authenticating if the token has expired or the credentials
+// have changed
+func (a *IdentityV2Auth) currentToken(ctx context.Context) (string, error) {
+	credentials, err := a.credentials.Credentials(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain credentials: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if credentials != a.tokenCredentials || a.clock.Now().After(a.tokenExpiration) {
+		if err := a.authenticate(ctx, credentials); err != nil {
+			return "", err
+		}
+		// an impersonation token can only be obtained by the current admin token
+		a.impersonationToken = ""
+	}
+
+	if a.impersonate == "" {
+		return a.token, nil
+	}
+	if a.impersonationToken == "" || a.clock.Now().After(a.impersonationExpiration) {
+		if err := a.obtainImpersonationToken(ctx); err != nil {
+			return "", err
+		}
+	}
+	return a.impersonationToken, nil
+}
+
+func (a *IdentityV2Auth) obtainImpersonationToken(ctx context.Context) error {
+	var req identityImpersonationReq
+	req.Impersonation.User.Username = a.impersonate
+	req.Impersonation.ExpireInSeconds = int(a.impersonationLifetime.Seconds())
+
+	var resp identityAuthResp
+
+	err := a.restClient.ExchangeWithContext(ctx, "POST", "/v2.0/RAX-AUTH/impersonation-tokens", nil,
+		NewJsonEntity(req), NewJsonEntity(&resp), withAuthToken(a.token))
+	if err != nil {
+		return fmt.Errorf("failed to issue impersonation token request for %s: %w", a.impersonate, err)
+	}
+
+	if stats := statsFrom(ctx); stats != nil {
+		stats.add(&stats.tokenRefreshes)
+	}
+	a.impersonationToken = resp.Access.Token.Id
+	a.impersonationExpiration = resp.Access.Token.Expires
+	a.impersonationTenantId = resp.Access.Token.Tenant.Id
+	a.impersonationRoles = resp.roleNames()
+	a.impersonationCatalog = resp.Access.ServiceCatalog
+
+	return nil
+}
+
+func (a *IdentityV2Auth) authenticate(ctx context.Context, credentials Credentials) error {
+	if err := validateIdentityCredentials(credentials); err != nil {
+		return err
+	}
 
 	var req interface{}
-	if a.apikey != "" {
+	if credentials.Apikey != "" {
 		auth := &identityAuthApikeyReq{}
-		auth.Auth.Credentials.Username = a.username
-		auth.Auth.Credentials.Apikey = a.apikey
+		auth.Auth.Credentials.Username = credentials.Username
+		auth.Auth.Credentials.Apikey = credentials.Apikey
 		req = auth
 	} else {
 		auth := &identityAuthPasswordReq{}
-		auth.Auth.Credentials.Username = a.username
-		auth.Auth.Credentials.Password = a.password
+		auth.Auth.Credentials.Username = credentials.Username
+		auth.Auth.Credentials.Password = credentials.Password
 		req = auth
 	}
 
 	var resp identityAuthResp
 
-	err := a.restClient.Exchange("POST", "/v2.0/tokens", nil,
+	err := a.restClient.ExchangeWithContext(ctx, "POST", "/v2.0/tokens", nil,
 		NewJsonEntity(req), NewJsonEntity(&resp))
+	if sessionId := mfaSession(err); sessionId != "" {
+		err = a.authenticatePasscode(ctx, sessionId, &resp)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to issue token request: %w", err)
 	}
 
+	if stats := statsFrom(ctx); stats != nil {
+		stats.add(&stats.tokenRefreshes)
+	}
 	a.token = resp.Access.Token.Id
 	a.tokenExpiration = resp.Access.Token.Expires
+	a.tokenCredentials = credentials
+	a.tenantId = resp.Access.Token.Tenant.Id
+	a.roles = resp.roleNames()
+	a.catalog = resp.Access.ServiceCatalog
+
+	return nil
+}
+
+// Close discards the tokens obtained by the authenticator and, when WithRevokeOnClose was given,
+// revokes them with Identity. A subsequent request would obtain a new token.
+func (a *IdentityV2Auth) Close() error {
+	return a.CloseWithContext(context.Background())
+}
+
+// CloseWithContext is the same as Close, but allows for a context to be provided
+func (a *IdentityV2Auth) CloseWithContext(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err error
+	if a.revokeOnClose && a.token != "" && a.clock.Now().Before(a.tokenExpiration) {
+		if a.impersonationToken != "" && a.clock.Now().Before(a.impersonationExpiration) {
+			err = a.revokeToken(ctx, a.impersonationToken)
+		}
+		if revokeErr := a.revokeToken(ctx, a.token); err == nil {
+			err = revokeErr
+		}
+	}
 
+	a.token = ""
+	a.tokenExpiration = time.Time{}
+	a.tokenCredentials = Credentials{}
+	a.tenantId = ""
+	a.roles = nil
+	a.catalog = nil
+	a.impersonationToken = ""
+	a.impersonationTenantId = ""
+	a.impersonationRoles = nil
+	a.impersonationCatalog = nil
+	return err
+}
+
+func (a *IdentityV2Auth) revokeToken(ctx context.Context, token string) error {
+	err := a.restClient.ExchangeWithContext(ctx, "DELETE", "/v2.0/tokens/"+url.PathEscape(token), nil,
+		nil, nil, withAuthToken(a.token))
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
 	return nil
 }
+
+// mfaSession returns the multi-factor session of a 401 Unauthorized response, if any
+func mfaSession(err error) string {
+	var failed *FailedResponseError
+	if !errors.As(err, &failed) || failed.StatusCode != http.StatusUnauthorized {
+		return ""
+	}
+	if match := mfaSessionPattern.FindStringSubmatch(failed.Header.Get("WWW-Authenticate")); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// authenticatePasscode completes the second step of the multi-factor flow
+func (a *IdentityV2Auth) authenticatePasscode(ctx context.Context, sessionId string,
+	resp *identityAuthResp) error {
+	if a.passcodePrompt == nil {
+		return ErrPasscodeRequired
+	}
+	passcode, err := a.passcodePrompt(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to obtain passcode: %w", err)
+	}
+
+	var req identityAuthPasscodeReq
+	req.Auth.Credentials.Passcode = passcode
+	return a.restClient.ExchangeWithContext(ctx, "POST", "/v2.0/tokens", nil,
+		NewJsonEntity(req), NewJsonEntity(resp),
+		func(o *requestOptions) {
+			o.setHeader("X-SessionId", sessionId)
+		})
+}
+
+// withAuthToken authenticates a request to Identity itself with the given token
+func withAuthToken(token string) RequestOption {
+	return func(o *requestOptions) {
+		o.setHeader("X-Auth-Token", token)
+	}
+}
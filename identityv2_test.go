@@ -1,8 +1,16 @@
 package restclient_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
 )
 
 func ExampleIdentityV2Authenticator() {
@@ -21,3 +29,209 @@ func ExampleIdentityV2Authenticator() {
 	// Output:
 	//
 }
+
+func ExampleWithImpersonation() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "racker1",
+		Password: "secret",
+		Roles:    []string{"Racker"},
+	})
+	identity.AddUser(restclienttest.IdentityUser{Username: "customer1", TenantId: "123456"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV impersonated by", identity.ImpersonatorOf(r.Header.Get("X-Auth-Token")))
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "racker1", "secret", "",
+		restclient.WithImpersonation("customer1", time.Hour))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(authenticator)
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.TokensIssued())
+	// Output:
+	// RECV impersonated by racker1
+	// <nil> 2
+}
+
+func ExampleWithPasscodePrompt() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "user1",
+		Password: "secret",
+		Passcode: "123456",
+	})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "user1", "secret", "",
+		restclient.WithPasscodePrompt(func(ctx context.Context) (string, error) {
+			// typically prompts the user at the terminal
+			fmt.Println("Enter passcode:")
+			return "123456", nil
+		}))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(authenticator)
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.TokensIssued())
+	// Output:
+	// Enter passcode:
+	// <nil> 1
+}
+
+func ExampleIdentityV2Auth_Close() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key1"})
+
+	var lastToken string
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastToken = r.Header.Get("X-Auth-Token")
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "user1", "", "key1",
+		restclient.WithRevokeOnClose())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(auth.Intercept)
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.ValidToken(lastToken))
+
+	err = auth.Close()
+	fmt.Println(err, identity.ValidToken(lastToken))
+	// Output:
+	// <nil> true
+	// <nil> false
+}
+
+func ExampleIdentityV2Auth_ValidateToken() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "service1",
+		Apikey:   "key1",
+		Roles:    []string{"identity:admin"},
+	})
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "user1",
+		Apikey:   "key2",
+		TenantId: "123456",
+		Roles:    []string{"compute:admin"},
+	})
+
+	// a token received from elsewhere, such as the X-Auth-Token of a request to a service
+	var token string
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Auth-Token")
+	})))
+	defer ts.Close()
+	userAuth, _ := restclient.IdentityV2Authenticator(identity.URL, "user1", "", "key2")
+	userClient := restclient.NewClient()
+	userClient.SetBaseUrl(ts.URL)
+	userClient.AddInterceptor(userAuth)
+	_ = userClient.Exchange("GET", "/", nil, nil, nil)
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "service1", "", "key1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	info, err := auth.ValidateToken(context.Background(), token)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(info.Username, info.TenantId, info.Roles)
+
+	identity.RevokeToken(token)
+	_, err = auth.ValidateToken(context.Background(), token)
+	fmt.Println(errors.Is(err, restclient.ErrInvalidToken))
+	// Output:
+	// user1 123456 [compute:admin]
+	// true
+}
+
+func ExampleIdentityV2Auth_HasRole() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "user1",
+		Apikey:   "key1",
+		Roles:    []string{"identity:default", "object-store:observer"},
+	})
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "user1", "", "key1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	roles, err := auth.Roles(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(roles)
+
+	// the returned roles are a copy, so this does not grant the role
+	roles[1] = "object-store:admin"
+	if !auth.HasRole("object-store:admin") {
+		fmt.Println("deleting containers requires the object-store:admin role")
+	}
+	// Output:
+	// [identity:default object-store:observer]
+	// deleting containers requires the object-store:admin role
+}
+
+func ExampleWithTokenHeader() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key1"})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		fmt.Println("RECV", identity.ValidToken(token), r.Header.Get("X-Auth-Token") == "")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "user1", "", "key1",
+		restclient.WithTokenHeader(restclient.BearerTokenHeader))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(authenticator)
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// RECV true true
+	// <nil>
+}
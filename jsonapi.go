@@ -0,0 +1,344 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// JsonApiType is the content type of JSON:API documents, as specified at https://jsonapi.org
+const JsonApiType MimeType = "application/vnd.api+json"
+
+func init() {
+	RegisterCodec(JsonApiType, jsonApiCodec{})
+}
+
+// NewJsonApiEntity creates an entity of JsonApiType.
+//
+// For a response entity, content can be a *JsonApiDocument to access the envelope as is, or a
+// reference to a struct, or slice of structs, into which the primary data is flattened.
+// Flattening merges each resource's id, type, and attributes into one JSON object, where each
+// relationship is an attribute holding the related resource, or array of resources. Related resources
+// that are included in the document are themselves flattened; otherwise, only their id and type are
+// given. The flattened object is then decoded with encoding/json, so the fields of the structs are
+// declared as usual, such as
+//
+//	type Article struct {
+//		Id     string `json:"id"`
+//		Title  string `json:"title"`
+//		Author *struct {
+//			Id   string `json:"id"`
+//			Name string `json:"name"`
+//		} `json:"author"`
+//	}
+//
+// For a request entity, content can be a *JsonApiDocument or a value, or slice of values, that encodes
+// into JSON objects with a "type" member. The inverse of flattening is applied, where any members
+// that are objects with only "type" and "id", or arrays of such objects, become relationships.
+func NewJsonApiEntity(content interface{}) *Entity {
+	return &Entity{
+		ContentType: JsonApiType,
+		Content:     content,
+	}
+}
+
+// JsonApiDocument is the top-level envelope of a JSON:API document
+type JsonApiDocument struct {
+	// Data is the primary data, which is a resource object, an array of resource objects, or null.
+	// Use DecodeData to flatten it into structs.
+	Data     json.RawMessage          `json:"data,omitempty"`
+	Included []*JsonApiResource       `json:"included,omitempty"`
+	Links    map[string]*JsonApiLink  `json:"links,omitempty"`
+	Meta     map[string]interface{}   `json:"meta,omitempty"`
+	JsonApi  map[string]interface{}   `json:"jsonapi,omitempty"`
+	Errors   []map[string]interface{} `json:"errors,omitempty"`
+}
+
+// JsonApiResource is a resource object of a JSON:API document
+type JsonApiResource struct {
+	Type          string                          `json:"type"`
+	Id            string                          `json:"id,omitempty"`
+	Attributes    map[string]json.RawMessage      `json:"attributes,omitempty"`
+	Relationships map[string]*JsonApiRelationship `json:"relationships,omitempty"`
+	Links         map[string]*JsonApiLink         `json:"links,omitempty"`
+	Meta          map[string]interface{}          `json:"meta,omitempty"`
+}
+
+// JsonApiRelationship is a relationship of a resource object, where Data is either a resource
+// identifier, an array of identifiers, or null
+type JsonApiRelationship struct {
+	Data  json.RawMessage         `json:"data,omitempty"`
+	Links map[string]*JsonApiLink `json:"links,omitempty"`
+	Meta  map[string]interface{}  `json:"meta,omitempty"`
+}
+
+// JsonApiLink is a link given either as a URL string or as a link object
+type JsonApiLink struct {
+	Href string                 `json:"href"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+func (l *JsonApiLink) UnmarshalJSON(data []byte) error {
+	var href string
+	if err := json.Unmarshal(data, &href); err == nil {
+		l.Href = href
+		return nil
+	}
+	type linkObject JsonApiLink
+	return json.Unmarshal(data, (*linkObject)(l))
+}
+
+// NextLink returns the URL of the next page of a paginated collection, or an empty string when
+// there isn't one
+func (d *JsonApiDocument) NextLink() string {
+	if next := d.Links["next"]; next != nil {
+		return next.Href
+	}
+	return ""
+}
+
+type jsonApiIdentifier struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+// DecodeData flattens the primary data, as described by NewJsonApiEntity, and decodes it into
+// the value referenced by v
+func (d *JsonApiDocument) DecodeData(v interface{}) error {
+	if len(d.Data) == 0 || string(d.Data) == "null" {
+		return json.Unmarshal([]byte("null"), v)
+	}
+
+	included := make(map[jsonApiIdentifier]*JsonApiResource, len(d.Included))
+	for _, resource := range d.Included {
+		included[jsonApiIdentifier{Type: resource.Type, Id: resource.Id}] = resource
+	}
+
+	var flattened interface{}
+	if d.Data[0] == '[' {
+		var resources []*JsonApiResource
+		if err := json.Unmarshal(d.Data, &resources); err != nil {
+			return fmt.Errorf("failed to decode JSON:API data: %w", err)
+		}
+		list := make([]interface{}, len(resources))
+		for i, resource := range resources {
+			list[i] = flattenJsonApiResource(resource, included, map[jsonApiIdentifier]bool{})
+		}
+		flattened = list
+	} else {
+		var resource JsonApiResource
+		if err := json.Unmarshal(d.Data, &resource); err != nil {
+			return fmt.Errorf("failed to decode JSON:API data: %w", err)
+		}
+		flattened = flattenJsonApiResource(&resource, included, map[jsonApiIdentifier]bool{})
+	}
+
+	encoded, err := json.Marshal(flattened)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// flattenJsonApiResource merges the resource's members into one object. The visiting set prevents
+// endless recursion through included resources that refer back to each other.
+func flattenJsonApiResource(resource *JsonApiResource, included map[jsonApiIdentifier]*JsonApiResource,
+	visiting map[jsonApiIdentifier]bool) map[string]interface{} {
+
+	id := jsonApiIdentifier{Type: resource.Type, Id: resource.Id}
+	visiting[id] = true
+	defer delete(visiting, id)
+
+	flattened := make(map[string]interface{}, len(resource.Attributes)+len(resource.Relationships)+2)
+	for name, value := range resource.Attributes {
+		flattened[name] = value
+	}
+	for name, relationship := range resource.Relationships {
+		if relationship == nil || len(relationship.Data) == 0 {
+			continue
+		}
+		resolve := func(ref jsonApiIdentifier) interface{} {
+			if related, ok := included[ref]; ok && !visiting[ref] {
+				return flattenJsonApiResource(related, included, visiting)
+			}
+			return ref
+		}
+		var refs []jsonApiIdentifier
+		var ref *jsonApiIdentifier
+		if relationship.Data[0] == '[' && json.Unmarshal(relationship.Data, &refs) == nil {
+			list := make([]interface{}, len(refs))
+			for i, r := range refs {
+				list[i] = resolve(r)
+			}
+			flattened[name] = list
+		} else if json.Unmarshal(relationship.Data, &ref) == nil && ref != nil {
+			flattened[name] = resolve(*ref)
+		} else {
+			flattened[name] = nil
+		}
+	}
+	flattened["type"] = resource.Type
+	if resource.Id != "" {
+		flattened["id"] = resource.Id
+	}
+	return flattened
+}
+
+// JsonApiPages retrieves the JSON:API collection at the given URL and invokes pageHandler with each
+// page's document, following the "next" link of each document until there is none. The handler
+// typically calls DecodeData on the document. Returning an error from the handler stops the paging
+// and is returned.
+func (c *Client) JsonApiPages(ctx context.Context, urlIn string, query url.Values,
+	pageHandler func(doc *JsonApiDocument) error, opts ...RequestOption) error {
+
+	pageUrl, err := c.buildReqUrl(urlIn, query)
+	if err != nil {
+		return err
+	}
+	for {
+		var doc JsonApiDocument
+		err := c.ExchangeWithContext(ctx, "GET", pageUrl.String(), nil, nil, NewJsonApiEntity(&doc), opts...)
+		if err != nil {
+			return err
+		}
+		if err := pageHandler(&doc); err != nil {
+			return err
+		}
+
+		next := doc.NextLink()
+		if next == "" {
+			return nil
+		}
+		// links can be relative to the current page
+		pageUrl, err = pageUrl.Parse(next)
+		if err != nil {
+			return fmt.Errorf("failed to parse next link: %w", err)
+		}
+	}
+}
+
+// jsonApiCodec decodes JSON:API documents into a *JsonApiDocument or flattens the primary data into
+// the given reference. It encodes a JsonApiDocument as is or un-flattens other content into resources.
+type jsonApiCodec struct{}
+
+func (jsonApiCodec) Decode(r io.Reader, content interface{}) error {
+	if doc, ok := content.(*JsonApiDocument); ok {
+		return json.NewDecoder(r).Decode(doc)
+	}
+	var doc JsonApiDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	return doc.DecodeData(content)
+}
+
+func (jsonApiCodec) Encode(w io.Writer, content interface{}) error {
+	switch c := content.(type) {
+	case *JsonApiDocument, JsonApiDocument:
+		return json.NewEncoder(w).Encode(c)
+	}
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return err
+	}
+
+	var data interface{}
+	switch g := generic.(type) {
+	case []interface{}:
+		resources := make([]*JsonApiResource, len(g))
+		for i, item := range g {
+			resources[i], err = unflattenJsonApiResource(item)
+			if err != nil {
+				return err
+			}
+		}
+		data = resources
+	default:
+		data, err = unflattenJsonApiResource(g)
+		if err != nil {
+			return err
+		}
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func unflattenJsonApiResource(item interface{}) (*JsonApiResource, error) {
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("JSON:API resource content must encode as an object")
+	}
+	resource := &JsonApiResource{}
+	if resource.Type, ok = object["type"].(string); !ok || resource.Type == "" {
+		return nil, errors.New("JSON:API resource content requires a type member")
+	}
+	resource.Id, _ = object["id"].(string)
+
+	for name, value := range object {
+		if name == "type" || name == "id" {
+			continue
+		}
+		if isJsonApiIdentifiers(value) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			if resource.Relationships == nil {
+				resource.Relationships = make(map[string]*JsonApiRelationship)
+			}
+			resource.Relationships[name] = &JsonApiRelationship{Data: data}
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if resource.Attributes == nil {
+			resource.Attributes = make(map[string]json.RawMessage)
+		}
+		resource.Attributes[name] = encoded
+	}
+	return resource, nil
+}
+
+// isJsonApiIdentifiers determines if the value is a resource identifier object, having only a type
+// and id, or a non-empty array of them
+func isJsonApiIdentifiers(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		_, hasType := v["type"].(string)
+		_, hasId := v["id"].(string)
+		return hasType && hasId && len(v) == 2
+	case []interface{}:
+		for _, item := range v {
+			if !isJsonApiIdentifiers(item) {
+				return false
+			}
+		}
+		return len(v) > 0
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+type Author struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Article struct {
+	Id     string  `json:"id,omitempty"`
+	Type   string  `json:"type"`
+	Title  string  `json:"title"`
+	Author *Author `json:"author,omitempty"`
+}
+
+func ExampleNewJsonApiEntity() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", string(restclient.JsonApiType))
+		_, _ = w.Write([]byte(`{
+  "data": {
+    "type": "articles",
+    "id": "1",
+    "attributes": {"title": "JSON:API paints my bikeshed!"},
+    "relationships": {
+      "author": {"data": {"type": "people", "id": "9"}}
+    }
+  },
+  "included": [
+    {"type": "people", "id": "9", "attributes": {"name": "Dan"}}
+  ]
+}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var article Article
+	err := client.Exchange("GET", "/articles/1", nil, nil,
+		restclient.NewJsonApiEntity(&article))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(article.Id, article.Title, article.Author.Name)
+	// Output:
+	// 1 JSON:API paints my bikeshed! Dan
+}
+
+func ExampleNewJsonApiEntity_request() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Print(string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	article := Article{
+		Type:  "articles",
+		Title: "Rails is Omakase",
+		Author: &Author{
+			Id: "9",
+		},
+	}
+	// relationships are given as identifiers
+	type articleRequest struct {
+		Article
+		Author map[string]string `json:"author"`
+	}
+	err := client.Exchange("POST", "/articles", nil,
+		restclient.NewJsonApiEntity(articleRequest{
+			Article: article,
+			Author:  map[string]string{"type": "people", "id": article.Author.Id},
+		}), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// {"data":{"type":"articles","attributes":{"title":"Rails is Omakase"},"relationships":{"author":{"data":{"id":"9","type":"people"}}}}}
+}
+
+func ExampleClient_JsonApiPages() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", string(restclient.JsonApiType))
+		if r.URL.Query().Get("page") == "" {
+			_, _ = w.Write([]byte(`{
+  "data": [{"type": "articles", "id": "1", "attributes": {"title": "First"}}],
+  "links": {"next": "/articles?page=2"}
+}`))
+		} else {
+			_, _ = w.Write([]byte(`{
+  "data": [{"type": "articles", "id": "2", "attributes": {"title": "Second"}}],
+  "links": {"next": null}
+}`))
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	err := client.JsonApiPages(context.Background(), "/articles", nil,
+		func(doc *restclient.JsonApiDocument) error {
+			var articles []Article
+			if err := doc.DecodeData(&articles); err != nil {
+				return err
+			}
+			for _, article := range articles {
+				fmt.Println(article.Id, article.Title)
+			}
+			return nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// 1 First
+	// 2 Second
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jsonschema_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/jsonschema"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func Example_entitySchema() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "web", "status": "BUILDING"}`))
+	}))
+	defer ts.Close()
+
+	schema, err := jsonschema.Parse([]byte(`{
+  "type": "object",
+  "required": ["name", "status"],
+  "properties": {
+    "status": {"enum": ["ACTIVE", "ERROR"]}
+  }
+}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var server map[string]interface{}
+	respOut := restclient.NewJsonEntity(&server)
+	respOut.Schema = schema
+	err = client.Exchange("GET", "/servers/web", nil, nil, respOut)
+
+	var schemaErr *jsonschema.ValidationError
+	if errors.As(err, &schemaErr) {
+		for _, fieldErr := range schemaErr.Errors {
+			fmt.Println(fieldErr.Path, fieldErr.Message)
+		}
+	}
+
+	// Output:
+	// /status value is not one of the allowed values
+}
@@ -0,0 +1,525 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package jsonschema validates JSON content against a JSON Schema.
+
+The commonly used validation keywords of JSON Schema draft 4 through 7 are supported along with the
+"nullable" keyword of OpenAPI 3.0 schemas:
+type, enum, const, properties, required, additionalProperties, minProperties, maxProperties,
+items, minItems, maxItems, uniqueItems, minLength, maxLength, pattern, format,
+minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not, and $ref.
+
+References are resolved as JSON pointers within the document containing the schema, such as
+"#/definitions/Server" or "#/components/schemas/Server".
+
+A Schema can be set as the Schema of a restclient.Entity to validate request content before it is
+sent and response content after it is decoded.
+*/
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is a parsed JSON Schema that can validate values
+type Schema struct {
+	node interface{}
+	doc  *document
+}
+
+type document struct {
+	root interface{}
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// FieldError describes a violation of the schema at a location within the validated value
+type FieldError struct {
+	// Path is the JSON pointer of the violating value, such as "/servers/0/name", which is
+	// empty for the value as a whole
+	Path    string
+	Message string
+}
+
+func (e FieldError) String() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// ValidationError is returned when a value does not conform to the schema and conveys each of
+// the violations found
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldError := range e.Errors {
+		messages[i] = fieldError.String()
+	}
+	return "schema validation failed: " + strings.Join(messages, "; ")
+}
+
+// Parse parses a JSON Schema document
+func Parse(data []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return FromDocument(root, "")
+}
+
+// FromDocument creates the schema located by the JSON pointer within a decoded JSON document,
+// such as an OpenAPI specification. An empty pointer refers to the document itself.
+// References within the schema are resolved against the document.
+func FromDocument(root interface{}, pointer string) (*Schema, error) {
+	doc := &document{root: root, patterns: make(map[string]*regexp.Regexp)}
+	node, err := doc.resolve(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{node: node, doc: doc}, nil
+}
+
+// Validate validates the value, which can be the generic result of decoding JSON or any value that
+// can be encoded as JSON, such as a struct. Returns a *ValidationError if the value does not conform.
+func (s *Schema) Validate(value interface{}) error {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return err
+	}
+	return s.validateGeneric(generic)
+}
+
+// ValidateJson validates the encoded JSON content
+func (s *Schema) ValidateJson(data []byte) error {
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return &ValidationError{Errors: []FieldError{{Message: "invalid JSON: " + err.Error()}}}
+	}
+	return s.validateGeneric(generic)
+}
+
+func (s *Schema) validateGeneric(value interface{}) error {
+	var errs []FieldError
+	s.doc.validate(s.node, value, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func toGeneric(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, bool, float64, string, []interface{}, map[string]interface{}:
+		return value, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value for validation: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to decode value for validation: %w", err)
+	}
+	return generic, nil
+}
+
+// resolve locates the node at the JSON pointer, with or without a leading "#"
+func (d *document) resolve(pointer string) (interface{}, error) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	node := d.root
+	if pointer == "" {
+		return node, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported reference %q", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if node, ok = n[token]; !ok {
+				return nil, fmt.Errorf("reference %q not found", pointer)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("reference %q not found", pointer)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("reference %q not found", pointer)
+		}
+	}
+	return node, nil
+}
+
+func (d *document) pattern(expr string) (*regexp.Regexp, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if re, ok := d.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	d.patterns[expr] = re
+	return re, nil
+}
+
+func (d *document) validate(node interface{}, value interface{}, path string, errs *[]FieldError) {
+	addError := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if allowed, ok := node.(bool); ok {
+		// boolean schemas of draft 6 and later
+		if !allowed {
+			addError("no value is allowed")
+		}
+		return
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := d.resolve(ref)
+		if err != nil {
+			addError("%v", err)
+			return
+		}
+		d.validate(resolved, value, path, errs)
+		return
+	}
+
+	if value == nil && schema["nullable"] == true {
+		return
+	}
+
+	if types, ok := schemaTypes(schema["type"]); ok {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			addError("expected %s but got %s", strings.Join(types, " or "), jsonTypeOf(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addError("value is not one of the allowed values")
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		addError("value does not equal the constant")
+	}
+
+	switch v := value.(type) {
+	case string:
+		d.validateString(schema, v, addError)
+	case float64:
+		validateNumber(schema, v, addError)
+	case map[string]interface{}:
+		d.validateObject(schema, v, path, errs, addError)
+	case []interface{}:
+		d.validateArray(schema, v, path, errs, addError)
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			d.validate(sub, value, path, errs)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if d.countMatches(anyOf, value) == 0 {
+			addError("value does not match any of the allowed schemas")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := d.countMatches(oneOf, value); matches != 1 {
+			addError("value must match exactly one schema but matched %d", matches)
+		}
+	}
+	if not, ok := schema["not"]; ok {
+		var subErrs []FieldError
+		d.validate(not, value, path, &subErrs)
+		if len(subErrs) == 0 {
+			addError("value matches a disallowed schema")
+		}
+	}
+}
+
+func (d *document) countMatches(schemas []interface{}, value interface{}) int {
+	matches := 0
+	for _, sub := range schemas {
+		var subErrs []FieldError
+		d.validate(sub, value, "", &subErrs)
+		if len(subErrs) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func (d *document) validateString(schema map[string]interface{}, v string, addError func(string, ...interface{})) {
+	length := utf8.RuneCountInString(v)
+	if min, ok := number(schema["minLength"]); ok && float64(length) < min {
+		addError("length must be at least %v", min)
+	}
+	if max, ok := number(schema["maxLength"]); ok && float64(length) > max {
+		addError("length must be at most %v", max)
+	}
+	if expr, ok := schema["pattern"].(string); ok {
+		re, err := d.pattern(expr)
+		if err != nil {
+			addError("invalid pattern %q: %v", expr, err)
+		} else if !re.MatchString(v) {
+			addError("value does not match pattern %q", expr)
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		if !validFormat(format, v) {
+			addError("value is not a valid %s", format)
+		}
+	}
+}
+
+func validateNumber(schema map[string]interface{}, v float64, addError func(string, ...interface{})) {
+	if min, ok := number(schema["minimum"]); ok {
+		if schema["exclusiveMinimum"] == true && v <= min {
+			addError("value must be greater than %v", min)
+		} else if v < min {
+			addError("value must be at least %v", min)
+		}
+	}
+	if max, ok := number(schema["maximum"]); ok {
+		if schema["exclusiveMaximum"] == true && v >= max {
+			addError("value must be less than %v", max)
+		} else if v > max {
+			addError("value must be at most %v", max)
+		}
+	}
+	// numeric form of draft 6 and later
+	if min, ok := number(schema["exclusiveMinimum"]); ok && v <= min {
+		addError("value must be greater than %v", min)
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && v >= max {
+		addError("value must be less than %v", max)
+	}
+	if multipleOf, ok := number(schema["multipleOf"]); ok && multipleOf > 0 {
+		quotient := v / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			addError("value must be a multiple of %v", multipleOf)
+		}
+	}
+}
+
+func (d *document) validateObject(schema map[string]interface{}, v map[string]interface{}, path string,
+	errs *[]FieldError, addError func(string, ...interface{})) {
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if nameStr, ok := name.(string); ok {
+				if _, present := v[nameStr]; !present {
+					*errs = append(*errs, FieldError{Path: path + "/" + escapePointer(nameStr), Message: "required property is missing"})
+				}
+			}
+		}
+	}
+	if min, ok := number(schema["minProperties"]); ok && float64(len(v)) < min {
+		addError("must have at least %v properties", min)
+	}
+	if max, ok := number(schema["maxProperties"]); ok && float64(len(v)) > max {
+		addError("must have at most %v properties", max)
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	// consistent ordering of reported errors
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := path + "/" + escapePointer(name)
+		if propertySchema, ok := properties[name]; ok {
+			d.validate(propertySchema, v[name], propertyPath, errs)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, FieldError{Path: propertyPath, Message: "property is not allowed"})
+			}
+		case map[string]interface{}:
+			d.validate(additional, v[name], propertyPath, errs)
+		}
+	}
+}
+
+func (d *document) validateArray(schema map[string]interface{}, v []interface{}, path string,
+	errs *[]FieldError, addError func(string, ...interface{})) {
+
+	if min, ok := number(schema["minItems"]); ok && float64(len(v)) < min {
+		addError("must have at least %v items", min)
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(v)) > max {
+		addError("must have at most %v items", max)
+	}
+	if schema["uniqueItems"] == true {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					addError("items %d and %d are not unique", i, j)
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		if tuple, isTuple := items.([]interface{}); isTuple {
+			for i, itemSchema := range tuple {
+				if i < len(v) {
+					d.validate(itemSchema, v[i], path+"/"+strconv.Itoa(i), errs)
+				}
+			}
+		} else {
+			for i, item := range v {
+				d.validate(items, item, path+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	}
+}
+
+func schemaTypes(t interface{}) ([]string, bool) {
+	switch typed := t.(type) {
+	case string:
+		return []string{typed}, true
+	case []interface{}:
+		var types []string
+		for _, each := range typed {
+			if s, ok := each.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types, len(types) > 0
+	}
+	return nil, false
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks the well-known formats and accepts unknown formats, as allowed by the specification
+func validFormat(format string, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(v)
+	case "email":
+		at := strings.LastIndex(v, "@")
+		return at > 0 && at < len(v)-1
+	}
+	return true
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
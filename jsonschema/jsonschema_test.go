@@ -0,0 +1,51 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jsonschema_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient/jsonschema"
+	"log"
+)
+
+func ExampleSchema_Validate() {
+	schema, err := jsonschema.Parse([]byte(`{
+  "type": "object",
+  "required": ["name", "flavor"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "flavor": {"enum": ["small", "large"]},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	type Server struct {
+		Name   string   `json:"name"`
+		Flavor string   `json:"flavor"`
+		Tags   []string `json:"tags,omitempty"`
+	}
+
+	fmt.Println(schema.Validate(Server{Name: "web", Flavor: "small"}))
+	fmt.Println(schema.ValidateJson([]byte(`{"name": "", "tags": ["a", 2]}`)))
+
+	// Output:
+	// <nil>
+	// schema validation failed: /flavor: required property is missing; /name: length must be at least 1; /tags/1: expected string but got number
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencySamples is the number of recent samples retained per endpoint
+const defaultLatencySamples = 1024
+
+// LatencyConfig configures a LatencyRecorder. Zero values are replaced with the defaults noted on
+// each field.
+type LatencyConfig struct {
+	// Template maps a request to the path template of its endpoint, such as "/servers/{id}", so
+	// that requests of the same endpoint are aggregated. The default replaces path segments that
+	// look like identifiers, such as numbers, UUIDs, and long hex strings, with "{id}". The
+	// EndpointAttribute of the request's context, or else the name given by WithOperationName,
+	// takes precedence.
+	Template func(req *http.Request) string
+	// Samples is the number of recent latencies retained per endpoint from which the percentiles are
+	// computed, which defaults to 1024
+	Samples int
+	// SlowRequestThreshold, when positive, is the latency beyond which OnSlowRequest is called
+	SlowRequestThreshold time.Duration
+	// OnSlowRequest is called with requests whose latency exceeded the SlowRequestThreshold, such as
+	// to log a warning
+	OnSlowRequest func(event SlowRequestEvent)
+	// Clock measures the latency, which defaults to SystemClock
+	Clock Clock
+}
+
+// SlowRequestEvent describes a request whose latency exceeded the SlowRequestThreshold
+type SlowRequestEvent struct {
+	Request  *http.Request
+	Template string
+	Latency  time.Duration
+	// StatusCode is the status of the response or zero if Err is set
+	StatusCode int
+	Err        error
+}
+
+// EndpointLatency is a snapshot of the latencies of an endpoint
+type EndpointLatency struct {
+	Method   string
+	Template string
+	// Count is the total number of requests, while the percentiles are of the recent samples
+	Count int64
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// LatencyRecorder aggregates the latencies of requests by method and path template. The latency
+// is measured until the response headers are received, excluding the reading of the body.
+//
+// Use the Intercept method as the Interceptor of a Client, typically first so that the latency
+// includes that of the other interceptors, such as retries.
+type LatencyRecorder struct {
+	config LatencyConfig
+
+	mu        sync.Mutex
+	endpoints map[endpointKey]*latencySamples
+}
+
+type endpointKey struct {
+	method   string
+	template string
+}
+
+// latencySamples is a ring buffer of the recent latencies of an endpoint
+type latencySamples struct {
+	count   int64
+	samples []time.Duration
+	next    int
+}
+
+// NewLatencyRecorder creates a LatencyRecorder with the given configuration
+func NewLatencyRecorder(config LatencyConfig) *LatencyRecorder {
+	if config.Template == nil {
+		config.Template = defaultPathTemplate
+	}
+	if config.Samples <= 0 {
+		config.Samples = defaultLatencySamples
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	return &LatencyRecorder{
+		config:    config,
+		endpoints: make(map[endpointKey]*latencySamples),
+	}
+}
+
+// Intercept is an Interceptor that records the latency of each request
+func (r *LatencyRecorder) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	template, ok := stringAttribute(req.Context(), EndpointAttribute)
+	if !ok {
+		template, ok = stringAttribute(req.Context(), OperationAttribute)
+	}
+	if !ok {
+		template = r.config.Template(req)
+	}
+	start := r.config.Clock.Now()
+	resp, err := next(req)
+	latency := r.config.Clock.Now().Sub(start)
+
+	r.record(endpointKey{method: req.Method, template: template}, latency)
+
+	if r.config.SlowRequestThreshold > 0 && latency > r.config.SlowRequestThreshold && r.config.OnSlowRequest != nil {
+		event := SlowRequestEvent{Request: req, Template: template, Latency: latency, Err: err}
+		if resp != nil {
+			event.StatusCode = resp.StatusCode
+		}
+		r.config.OnSlowRequest(event)
+	}
+	return resp, err
+}
+
+func (r *LatencyRecorder) record(key endpointKey, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.endpoints[key]
+	if !ok {
+		s = &latencySamples{}
+		r.endpoints[key] = s
+	}
+	s.count++
+	if len(s.samples) < r.config.Samples {
+		s.samples = append(s.samples, latency)
+	} else {
+		s.samples[s.next] = latency
+		s.next = (s.next + 1) % len(s.samples)
+	}
+}
+
+// Snapshot returns the latencies of each endpoint, ordered by template and method
+func (r *LatencyRecorder) Snapshot() []EndpointLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make([]EndpointLatency, 0, len(r.endpoints))
+	for key, s := range r.endpoints {
+		sorted := make([]time.Duration, len(s.samples))
+		copy(sorted, s.samples)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		snapshot = append(snapshot, EndpointLatency{
+			Method:   key.method,
+			Template: key.template,
+			Count:    s.count,
+			P50:      percentile(sorted, 0.50),
+			P95:      percentile(sorted, 0.95),
+			P99:      percentile(sorted, 0.99),
+			Max:      sorted[len(sorted)-1],
+		})
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Template != snapshot[j].Template {
+			return snapshot[i].Template < snapshot[j].Template
+		}
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
+
+// Reset discards the recorded latencies
+func (r *LatencyRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints = make(map[endpointKey]*latencySamples)
+}
+
+// percentile uses the nearest-rank method on the sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func defaultPathTemplate(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if looksLikeIdentifier(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func looksLikeIdentifier(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits, hex, dashes := 0, 0, 0
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F'):
+			hex++
+		case r == '-':
+			dashes++
+		default:
+			return false
+		}
+	}
+	switch {
+	case digits == len(segment):
+		return true
+	case dashes == 4 && len(segment) == 36:
+		// UUID
+		return true
+	case dashes == 0 && len(segment) >= 16 && digits > 0:
+		return true
+	}
+	return false
+}
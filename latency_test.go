@@ -0,0 +1,70 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleLatencyRecorder() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	clock := restclienttest.NewFakeClock(time.Now())
+	// simulates the latency of each request with the fake clock
+	latency := 10 * time.Millisecond
+	simulateLatency := func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		clock.Advance(latency)
+		return next(req)
+	}
+
+	// Real example starts here
+	recorder := restclient.NewLatencyRecorder(restclient.LatencyConfig{
+		SlowRequestThreshold: 500 * time.Millisecond,
+		OnSlowRequest: func(event restclient.SlowRequestEvent) {
+			fmt.Println("SLOW", event.Request.Method, event.Template, event.Latency, event.StatusCode)
+		},
+		Clock: clock,
+	})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(recorder.Intercept)
+	client.AddInterceptor(simulateLatency)
+
+	for i := 1; i <= 100; i++ {
+		latency = time.Duration(i) * time.Millisecond
+		_ = client.Exchange("GET", fmt.Sprintf("/servers/%d", i), nil, nil, nil)
+	}
+	latency = time.Second
+	_ = client.Exchange("DELETE", "/servers/b4cd5a3e-2a5c-4e36-8d5c-4c7b1f6e2f10", nil, nil, nil)
+
+	for _, endpoint := range recorder.Snapshot() {
+		fmt.Println(endpoint.Method, endpoint.Template, endpoint.Count,
+			endpoint.P50, endpoint.P95, endpoint.P99, endpoint.Max)
+	}
+	// Output:
+	// SLOW DELETE /servers/{id} 1s 200
+	// DELETE /servers/{id} 1 1s 1s 1s 1s
+	// GET /servers/{id} 100 50ms 95ms 99ms 100ms
+}
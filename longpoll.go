@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LongPoll repeatedly retrieves a URL that the server holds open until an event is available, such
+// as a queue or change feed, and delivers each decoded event. Zero values are replaced with the
+// defaults noted on each field.
+//
+// Since each request is bound by the client's Timeout, it should exceed the time the server holds
+// requests open.
+type LongPoll struct {
+	Client *Client
+	// Url is retrieved with GET and may be relative to the client's BaseUrl
+	Url string
+	// Query, if set, is called before each request to provide the query parameters, such as to
+	// convey a cursor or marker of the last event received
+	Query func() url.Values
+	// NewContent is called before each request to provide the reference the event is decoded into,
+	// such as func() interface{} { return &Event{} }
+	NewContent func() interface{}
+	// ContentType of the events, which defaults to JsonType
+	ContentType MimeType
+	// InitialBackoff is the delay before reconnecting after the first error, which defaults to 1s
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between reconnects, which defaults to 30s
+	MaxBackoff time.Duration
+	// Clock is used for the backoff delays, which defaults to SystemClock
+	Clock Clock
+	// OnError, if set, is called with each error before backing off by delay
+	OnError func(err error, delay time.Duration)
+	// Options are applied to each request's exchange
+	Options []RequestOption
+	// IdleTimeout, when positive, aborts a request whose response headers or body stall for longer,
+	// such as on a silently half-open connection, and reconnects after the backoff. See WithIdleTimeout.
+	IdleTimeout time.Duration
+}
+
+// Run polls until ctx is done, sending each event to events and then returning the error of ctx.
+// A 204 No Content response, an empty body, or the client's Timeout is treated as the absence of an
+// event and the next request is issued immediately. Other errors, including a 408 Request Timeout or
+// 504 Gateway Timeout response, are reconnected with exponential backoff, so that a failing gateway
+// isn't retried in a tight loop.
+func (p *LongPoll) Run(ctx context.Context, events chan<- interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	contentType := p.ContentType
+	if contentType == "" {
+		contentType = JsonType
+	}
+	initialBackoff := p.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	clock := p.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	// limits the capacity, so that appending options doesn't modify the caller's slice
+	opts := p.Options[:len(p.Options):len(p.Options)]
+	if p.IdleTimeout > 0 {
+		opts = append(opts, WithIdleTimeout(p.IdleTimeout))
+	}
+
+	backoff := initialBackoff
+	for ctx.Err() == nil {
+		var query url.Values
+		if p.Query != nil {
+			query = p.Query()
+		}
+		var info ResponseInfo
+		captured := &Entity{ContentType: contentType, Content: []byte(nil)}
+		err := p.Client.ExchangeWithContext(ctx, "GET", p.Url, query, nil, captured,
+			append(opts, WithResponseInfo(&info))...)
+
+		var event interface{}
+		body, _ := captured.Content.([]byte)
+		if err == nil && info.StatusCode != http.StatusNoContent && len(bytes.TrimSpace(body)) > 0 {
+			event = p.NewContent()
+			err = p.Client.decodeCapturedContent(&Entity{ContentType: contentType, Content: event},
+				captured, info.Header)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if isLongPollTimeout(err) {
+				continue
+			}
+			if p.OnError != nil {
+				p.OnError(err, backoff)
+			}
+			wait, stopWait := startTimer(clock, backoff)
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				stopWait()
+				return ctx.Err()
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+
+		backoff = initialBackoff
+		if event == nil {
+			continue
+		}
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// isLongPollTimeout determines if the request was held open until the client's Timeout, which is
+// the absence of an event rather than a failure
+func isLongPollTimeout(err error) bool {
+	return errors.Is(err, ErrClientTimeout)
+}
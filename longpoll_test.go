@@ -0,0 +1,214 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+func ExampleLongPoll() {
+	// Setup a test HTTP server that has no event at first and then fails once
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			// the server's hold time elapsed without an event
+			w.WriteHeader(http.StatusNoContent)
+		case 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"id": %d, "message": "event after %s"}`,
+				requests, r.URL.Query().Get("marker"))
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Event struct {
+		Id      int
+		Message string
+	}
+	var marker int64
+	poll := &restclient.LongPoll{
+		Client: client,
+		Url:    "/v1/events",
+		Query: func() url.Values {
+			return url.Values{"marker": {strconv.FormatInt(atomic.LoadInt64(&marker), 10)}}
+		},
+		NewContent: func() interface{} {
+			return &Event{}
+		},
+		Clock: clock,
+		OnError: func(err error, delay time.Duration) {
+			fmt.Println("reconnecting after", delay)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan interface{})
+	done := make(chan error)
+	go func() {
+		done <- poll.Run(ctx, events)
+	}()
+
+	for i := 0; i < 2; i++ {
+		event := (<-events).(*Event)
+		atomic.StoreInt64(&marker, int64(event.Id))
+		fmt.Println(event.Message)
+	}
+	cancel()
+	fmt.Println(<-done)
+	// Output:
+	// event after 0
+	// reconnecting after 1s
+	// event after 2
+	// context canceled
+}
+
+func ExampleLongPoll_idleTimeout() {
+	// Setup a test HTTP server whose first responses stall, as on a half-open connection
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			// stalls before the response headers
+			<-r.Context().Done()
+		case 2:
+			// stalls within the response body
+			_, _ = fmt.Fprint(w, `{"message": `)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			_, _ = fmt.Fprint(w, `{"message": "resumed"}`)
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Event struct {
+		Message string
+	}
+	poll := &restclient.LongPoll{
+		Client: client,
+		Url:    "/v1/events",
+		NewContent: func() interface{} {
+			return &Event{}
+		},
+		IdleTimeout: 50 * time.Millisecond,
+		Clock:       clock,
+		OnError: func(err error, delay time.Duration) {
+			fmt.Println(errors.Is(err, restclient.ErrResponseHeaderTimeout),
+				errors.Is(err, restclient.ErrBodyReadTimeout), "reconnecting after", delay)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan interface{})
+	go func() {
+		_ = poll.Run(ctx, events)
+	}()
+
+	event := (<-events).(*Event)
+	fmt.Println(event.Message, atomic.LoadInt32(&requests))
+	// Output:
+	// true false reconnecting after 1s
+	// false true reconnecting after 2s
+	// resumed 3
+}
+
+func ExampleLongPoll_gatewayTimeout() {
+	// Setup a test HTTP server behind a failing gateway, which then responds without an event
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1, 2:
+			w.WriteHeader(http.StatusGatewayTimeout)
+		case 3:
+			// an empty body is the absence of an event
+			w.Header().Set("Content-Type", "application/json")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"message": "recovered"}`)
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Event struct {
+		Message string
+	}
+	poll := &restclient.LongPoll{
+		Client: client,
+		Url:    "/v1/events",
+		NewContent: func() interface{} {
+			return &Event{}
+		},
+		Clock: clock,
+		OnError: func(err error, delay time.Duration) {
+			fmt.Println("reconnecting after", delay)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan interface{})
+	go func() {
+		_ = poll.Run(ctx, events)
+	}()
+
+	event := (<-events).(*Event)
+	fmt.Println(event.Message, atomic.LoadInt32(&requests))
+	// Output:
+	// reconnecting after 1s
+	// reconnecting after 2s
+	// recovered 4
+}
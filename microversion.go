@@ -0,0 +1,203 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrIncompatibleVersion is matched, via errors.Is, when a server does not support any of the
+// microversions acceptable to a MicroversionNegotiator
+var ErrIncompatibleVersion = errors.New("incompatible API version")
+
+// Microversion is an API microversion, such as 2.53 of the OpenStack Compute API
+type Microversion struct {
+	Major int
+	Minor int
+}
+
+// ParseMicroversion parses a microversion of the form "2.53"
+func ParseMicroversion(s string) (Microversion, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) != 2 {
+		return Microversion{}, fmt.Errorf("invalid microversion %q", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Microversion{}, fmt.Errorf("invalid microversion %q", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Microversion{}, fmt.Errorf("invalid microversion %q", s)
+	}
+	return Microversion{Major: major, Minor: minor}, nil
+}
+
+func (v Microversion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// IsZero indicates the microversion was not given
+func (v Microversion) IsZero() bool {
+	return v == Microversion{}
+}
+
+// Less indicates that v is prior to other
+func (v Microversion) Less(other Microversion) bool {
+	return v.Major < other.Major || (v.Major == other.Major && v.Minor < other.Minor)
+}
+
+const openStackApiVersionHeader = "OpenStack-API-Version"
+
+// MicroversionConfig configures a MicroversionNegotiator. Zero values are replaced with the
+// defaults noted on each field.
+type MicroversionConfig struct {
+	// Header conveys the negotiated microversion on requests. It defaults to OpenStack-API-Version.
+	Header string
+	// Service is the service type, such as "compute", that precedes the microversion in the value
+	// of the Header. It is required by OpenStack-API-Version and is left empty for legacy headers,
+	// such as X-OpenStack-Nova-API-Version.
+	Service string
+	// Min is the earliest microversion the application supports
+	Min Microversion
+	// Max is the latest microversion the application supports, which defaults to the latest
+	// supported by the server
+	Max Microversion
+}
+
+// MicroversionNegotiator negotiates the latest microversion supported by both the application and
+// the server, as advertised by the version document of the API root, and pins the negotiated
+// microversion on requests.
+//
+// Use the Intercept method as the Interceptor of a Client, such as
+//
+//	client.AddInterceptor(negotiator.Intercept)
+type MicroversionNegotiator struct {
+	config     MicroversionConfig
+	discovery  *DiscoveryCache
+	rootUrl    string
+	apiVersion string
+
+	mu         sync.Mutex
+	negotiated Microversion
+}
+
+// NewMicroversionNegotiator creates a MicroversionNegotiator for the apiVersion, such as "v2.1", listed
+// by the API root at rootUrl, which are located as described for DiscoveryCache.ResolveVersion
+func NewMicroversionNegotiator(discovery *DiscoveryCache, rootUrl string, apiVersion string,
+	config MicroversionConfig) *MicroversionNegotiator {
+
+	if config.Header == "" {
+		config.Header = openStackApiVersionHeader
+	}
+	return &MicroversionNegotiator{
+		config:     config,
+		discovery:  discovery,
+		rootUrl:    rootUrl,
+		apiVersion: apiVersion,
+	}
+}
+
+// Negotiate determines the microversion to use, which is the latest supported by both the
+// application and the server. A zero microversion is returned when the server does not support
+// microversions and the application does not require one. The error matches ErrIncompatibleVersion
+// when there is no such microversion.
+func (n *MicroversionNegotiator) Negotiate(ctx context.Context) (Microversion, error) {
+	version, err := n.discovery.ResolveVersion(ctx, n.rootUrl, n.apiVersion)
+	if err != nil {
+		return Microversion{}, err
+	}
+
+	var negotiated Microversion
+	if version.Version == "" {
+		if !n.config.Min.IsZero() {
+			return Microversion{}, fmt.Errorf("%w: requires %s but %s does not support microversions",
+				ErrIncompatibleVersion, n.config.Min, version.Id)
+		}
+	} else {
+		serverMax, err := ParseMicroversion(version.Version)
+		if err != nil {
+			return Microversion{}, err
+		}
+		serverMin := serverMax
+		if version.MinVersion != "" {
+			if serverMin, err = ParseMicroversion(version.MinVersion); err != nil {
+				return Microversion{}, err
+			}
+		}
+
+		negotiated = serverMax
+		if !n.config.Max.IsZero() && n.config.Max.Less(negotiated) {
+			negotiated = n.config.Max
+		}
+		if negotiated.Less(serverMin) || negotiated.Less(n.config.Min) {
+			return Microversion{}, fmt.Errorf("%w: requires %s to %s but %s supports %s to %s",
+				ErrIncompatibleVersion, n.config.Min, n.describeMax(), version.Id, serverMin, serverMax)
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.negotiated = negotiated
+	return negotiated, nil
+}
+
+// Version returns the most recently negotiated microversion
+func (n *MicroversionNegotiator) Version() Microversion {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.negotiated
+}
+
+// Intercept is an Interceptor that pins the negotiated microversion on requests. The negotiation
+// relies upon the DiscoveryCache, so it follows any change of the server's microversions once
+// the version document is retrieved again. Requests fail when the Header is OpenStack-API-Version
+// but the config has no Service, since the header's value would be invalid.
+func (n *MicroversionNegotiator) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	if req.Context().Value(discoveringKey{}) != nil {
+		// the client is also used for discovery
+		return next(req)
+	}
+	if n.config.Service == "" && strings.EqualFold(n.config.Header, openStackApiVersionHeader) {
+		return nil, fmt.Errorf("microversion config requires a Service for the %s header", n.config.Header)
+	}
+	version, err := n.Negotiate(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to negotiate microversion: %w", err)
+	}
+	if !version.IsZero() {
+		value := version.String()
+		if n.config.Service != "" {
+			value = n.config.Service + " " + value
+		}
+		req.Header.Set(n.config.Header, value)
+	}
+	return next(req)
+}
+
+func (n *MicroversionNegotiator) describeMax() string {
+	if n.config.Max.IsZero() {
+		return "latest"
+	}
+	return n.config.Max.String()
+}
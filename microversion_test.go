@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleMicroversionNegotiator() {
+	// Setup a test HTTP server that supports microversions 2.1 to 2.53
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"versions": [{"id": "v2.1", "status": "CURRENT", "version": "2.53",
+  "min_version": "2.1", "links": [{"href": "/v2.1/", "rel": "self"}]}]}`))
+			return
+		}
+		fmt.Println("RECV", r.URL.Path, r.Header.Get("OpenStack-API-Version"))
+		_, _ = w.Write([]byte(`{"servers": []}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	discovery := restclient.NewDiscoveryCache(client, time.Hour)
+	negotiator := restclient.NewMicroversionNegotiator(discovery, "/", "v2.1", restclient.MicroversionConfig{
+		Service: "compute",
+		Min:     restclient.Microversion{Major: 2, Minor: 10},
+		Max:     restclient.Microversion{Major: 2, Minor: 60},
+	})
+	// the client that is used for discovery can also be negotiated
+	client.AddInterceptor(negotiator.Intercept)
+
+	err := client.Exchange("GET", "/v2.1/servers", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(negotiator.Version())
+
+	incompatible := restclient.NewMicroversionNegotiator(discovery, "/", "v2.1", restclient.MicroversionConfig{
+		Min: restclient.Microversion{Major: 2, Minor: 60},
+	})
+	_, err = incompatible.Negotiate(context.Background())
+	fmt.Println(errors.Is(err, restclient.ErrIncompatibleVersion))
+	fmt.Println(err)
+	// Output:
+	// RECV /v2.1/servers compute 2.53
+	// 2.53
+	// true
+	// incompatible API version: requires 2.60 to latest but v2.1 supports 2.1 to 2.53
+}
+
+func ExampleMicroversionConfig_legacyHeader() {
+	// Setup a test HTTP server that supports microversions 2.1 to 2.53
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"versions": [{"id": "v2.1", "status": "CURRENT", "version": "2.53",
+  "min_version": "2.1", "links": [{"href": "/v2.1/", "rel": "self"}]}]}`))
+			return
+		}
+		fmt.Printf("RECV %q\n", r.Header.Get("X-OpenStack-Nova-API-Version"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	discovery := restclient.NewDiscoveryCache(client, time.Hour)
+
+	// the legacy header has no service
+	legacy := restclient.NewMicroversionNegotiator(discovery, "/", "v2.1", restclient.MicroversionConfig{
+		Header: "X-OpenStack-Nova-API-Version",
+	})
+	client.AddInterceptor(legacy.Intercept)
+	fmt.Println(client.Exchange("GET", "/v2.1/servers", nil, nil, nil))
+
+	// whereas OpenStack-API-Version requires one
+	other := restclient.NewClient()
+	other.SetBaseUrl(ts.URL)
+	other.AddInterceptor(restclient.NewMicroversionNegotiator(discovery, "/", "v2.1",
+		restclient.MicroversionConfig{}).Intercept)
+	fmt.Println(other.Exchange("GET", "/v2.1/servers", nil, nil, nil))
+	// Output:
+	// RECV "2.53"
+	// <nil>
+	// failed to send request: microversion config requires a Service for the OpenStack-API-Version header
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package msgpackcodec enables MessagePack encoded entities with the restclient package.
+
+Importing this package registers a codec for MsgpackType, so that entities with that content type
+are encoded and decoded in the same manner as JSON entities, but with a more compact payload.
+Struct fields can be named with the "msgpack" tag.
+*/
+package msgpackcodec
+
+import (
+	"github.com/racker/go-restclient"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+)
+
+// MsgpackType is the content type of MessagePack encoded entities
+const MsgpackType restclient.MimeType = "application/msgpack"
+
+func init() {
+	restclient.RegisterCodec(MsgpackType, Codec{})
+}
+
+// Codec is a restclient.Codec that encodes and decodes MessagePack content
+type Codec struct{}
+
+func (Codec) Encode(w io.Writer, content interface{}) error {
+	return msgpack.NewEncoder(w).Encode(content)
+}
+
+func (Codec) Decode(r io.Reader, content interface{}) error {
+	return msgpack.NewDecoder(r).Decode(content)
+}
+
+// NewEntity creates an entity of MsgpackType with the given content.
+// When used for a response, content should be a reference to the value to decode into.
+func NewEntity(content interface{}) *restclient.Entity {
+	return &restclient.Entity{
+		ContentType: MsgpackType,
+		Content:     content,
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package msgpackcodec_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/msgpackcodec"
+	"github.com/vmihailenco/msgpack/v5"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleNewEntity() {
+	type Metric struct {
+		Name  string  `msgpack:"name"`
+		Value float64 `msgpack:"value"`
+	}
+
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metric Metric
+		_ = msgpack.NewDecoder(r.Body).Decode(&metric)
+		fmt.Printf("RECV %s %+v\n", r.Header.Get("Content-Type"), metric)
+
+		w.Header().Set("Content-Type", string(msgpackcodec.MsgpackType))
+		_ = msgpack.NewEncoder(w).Encode(map[string]int{"accepted": 1})
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resp map[string]int
+	err := client.Exchange("POST", "/metrics", nil,
+		msgpackcodec.NewEntity(&Metric{Name: "cpu", Value: 0.5}), msgpackcodec.NewEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp["accepted"])
+	// Output:
+	// RECV application/msgpack {Name:cpu Value:0.5}
+	// 1
+}
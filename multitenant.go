@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrNoCredentialTenant is returned by TenantAuth for requests whose context has no tenant given
+// by WithCredentialTenant
+var ErrNoCredentialTenant = errors.New("request has no tenant to select its credentials")
+
+type credentialTenantKey struct{}
+
+// WithCredentialTenant returns a context, for use with ExchangeWithContext, that selects the tenant,
+// such as a customer, whose credentials are used by TenantAuth to authenticate the exchange
+func WithCredentialTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, credentialTenantKey{}, tenant)
+}
+
+// CredentialTenantFromContext returns the tenant selected by the context or an empty string if none
+func CredentialTenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(credentialTenantKey{}).(string)
+	return tenant
+}
+
+// TenantAuthFactory creates the authentication interceptor of the tenant, such as the Intercept method
+// of an IdentityV2Auth created with the tenant's credentials
+type TenantAuthFactory func(ctx context.Context, tenant string) (Interceptor, error)
+
+// TenantAuth authenticates the requests of a shared Client with the credentials of the tenant
+// selected by WithCredentialTenant, such as to call an API on behalf of many customers. The
+// authenticator of each tenant is created by the factory upon the tenant's first request and
+// cached, so that tokens are reused across requests of the same tenant.
+//
+// Use the Intercept method as the Interceptor, such as
+//
+//	auth := restclient.NewTenantAuth(func(ctx context.Context, tenant string) (restclient.Interceptor, error) {
+//		apikey, err := lookupApikey(ctx, tenant)
+//		...
+//		return restclient.IdentityV2Authenticator(identityUrl, tenant, "", apikey)
+//	})
+//	client.AddInterceptor(auth.Intercept)
+//	...
+//	err := client.ExchangeWithContext(restclient.WithCredentialTenant(ctx, "customer1"), "GET", "/servers", nil, nil, restclient.NewJsonEntity(&servers))
+type TenantAuth struct {
+	factory TenantAuthFactory
+
+	mu      sync.Mutex
+	entries map[string]*tenantAuthEntry
+}
+
+type tenantAuthEntry struct {
+	// ready is closed once the interceptor has been created or failed to be
+	ready       chan struct{}
+	interceptor Interceptor
+	err         error
+}
+
+// NewTenantAuth creates a TenantAuth that creates the authenticators of tenants with factory
+func NewTenantAuth(factory TenantAuthFactory) *TenantAuth {
+	return &TenantAuth{
+		factory: factory,
+		entries: make(map[string]*tenantAuthEntry),
+	}
+}
+
+// Intercept is an Interceptor that authenticates the request with the authenticator of the tenant
+// selected by the request's context. Requests without a tenant fail with ErrNoCredentialTenant.
+func (a *TenantAuth) Intercept(req *http.Request, next NextCallback) (*http.Response, error) {
+	tenant := CredentialTenantFromContext(req.Context())
+	if tenant == "" {
+		return nil, ErrNoCredentialTenant
+	}
+	interceptor, err := a.authenticator(req.Context(), tenant)
+	if err != nil {
+		return nil, err
+	}
+	return interceptor(req, next)
+}
+
+// Tenants returns the tenants whose authenticators are cached
+func (a *TenantAuth) Tenants() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tenants := make([]string, 0, len(a.entries))
+	for tenant := range a.entries {
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
+// Forget discards the cached authenticator of the tenant, such as when its credentials have been
+// revoked, so that the next request of the tenant creates a new one
+func (a *TenantAuth) Forget(tenant string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.entries, tenant)
+}
+
+// authenticator returns the cached authenticator of the tenant or creates it, where concurrent
+// requests of a new tenant wait for the one creating it
+func (a *TenantAuth) authenticator(ctx context.Context, tenant string) (Interceptor, error) {
+	a.mu.Lock()
+	entry, exists := a.entries[tenant]
+	if !exists {
+		entry = &tenantAuthEntry{ready: make(chan struct{})}
+		a.entries[tenant] = entry
+	}
+	a.mu.Unlock()
+
+	if exists {
+		select {
+		case <-entry.ready:
+			return entry.interceptor, entry.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	entry.interceptor, entry.err = a.factory(ctx, tenant)
+	if entry.err != nil {
+		entry.err = fmt.Errorf("failed to setup authentication of tenant %s: %w", tenant, entry.err)
+		// failures are not cached, so the next request tries again
+		a.mu.Lock()
+		if a.entries[tenant] == entry {
+			delete(a.entries, tenant)
+		}
+		a.mu.Unlock()
+	}
+	close(entry.ready)
+	return entry.interceptor, entry.err
+}
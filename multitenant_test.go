@@ -0,0 +1,66 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleTenantAuth() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "customer1", Apikey: "key1"})
+	identity.AddUser(restclienttest.IdentityUser{Username: "customer2", Apikey: "key2"})
+
+	// the service being called with the tokens
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer ts.Close()
+
+	// Real example starts here
+	apikeys := map[string]string{"customer1": "key1", "customer2": "key2"}
+	auth := restclient.NewTenantAuth(func(ctx context.Context, tenant string) (restclient.Interceptor, error) {
+		apikey, ok := apikeys[tenant]
+		if !ok {
+			return nil, errors.New("unknown customer")
+		}
+		return restclient.IdentityV2Authenticator(identity.URL, tenant, "", apikey)
+	})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(auth.Intercept)
+
+	for _, tenant := range []string{"customer1", "customer2", "customer1", "customer3"} {
+		ctx := restclient.WithCredentialTenant(context.Background(), tenant)
+		err := client.ExchangeWithContext(ctx, "GET", "/servers", nil, nil, nil)
+		fmt.Println(tenant, err, identity.TokensIssued())
+	}
+
+	err := client.Exchange("GET", "/servers", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrNoCredentialTenant))
+	// Output:
+	// customer1 <nil> 1
+	// customer2 <nil> 2
+	// customer1 <nil> 2
+	// customer3 failed to send request: failed to setup authentication of tenant customer3: unknown customer 2
+	// true
+}
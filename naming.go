@@ -0,0 +1,139 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldNaming maps the Go name of a struct field, such as UserId, to its name in JSON, such as
+// user_id. It is set as the FieldNaming of a JsonCodec, so that fields follow the naming
+// convention of an API without a json tag on every field, such as
+//
+//	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{FieldNaming: restclient.SnakeCase})
+//
+// Fields with a name in their json tag keep that name.
+type FieldNaming func(goName string) string
+
+var (
+	// SnakeCase maps field names to lower case words separated by underscores, where
+	// UserID becomes user_id and HTTPServer becomes http_server
+	SnakeCase FieldNaming = snakeCase
+	// CamelCase maps field names to camel case with a lower case first word, where
+	// UserID becomes userID and HTTPServer becomes httpServer
+	CamelCase FieldNaming = camelCase
+)
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			// starts a word after a lower case letter or digit, or at the end of an acronym
+			endsAcronym := unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || endsAcronym {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
+
+func camelCase(name string) string {
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsUpper(r) {
+			break
+		}
+		// the last capital of a leading acronym starts the next word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// renameJsonFields renames the keys of the JSON data that correspond to the untagged fields of the
+// structs of type t. When encoding, the keys are renamed from the Go name to that given by naming
+// and, otherwise, the reverse for decoding.
+func renameJsonFields(data []byte, t reflect.Type, naming FieldNaming, encoding bool) ([]byte, error) {
+	if t == nil {
+		return data, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// retains the precision of large numbers
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	renameFields(value, t, naming, encoding)
+	return json.Marshal(value)
+}
+
+func renameFields(value interface{}, t reflect.Type, naming FieldNaming, encoding bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isJsonMarshaler(t) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		// encodes itself
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for name, field := range jsonFields(t) {
+			key := name
+			if !field.Tagged {
+				from, to := name, naming(name)
+				if !encoding {
+					from, to = to, from
+				}
+				if fieldValue, ok := object[from]; ok && from != to {
+					delete(object, from)
+					object[to] = fieldValue
+				}
+				key = to
+			}
+			if fieldValue, ok := object[key]; ok {
+				renameFields(fieldValue, field.Type, naming, encoding)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				renameFields(item, t.Elem(), naming, encoding)
+			}
+		}
+	case reflect.Map:
+		if object, ok := value.(map[string]interface{}); ok {
+			for _, item := range object {
+				renameFields(item, t.Elem(), naming, encoding)
+			}
+		}
+	}
+}
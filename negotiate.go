@@ -0,0 +1,86 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AcceptedType is a content type that is acceptable for a response along with its relative
+// preference, which is conveyed as the quality value of the Accept header
+type AcceptedType struct {
+	// ContentType can also be a media range, such as "text/*"
+	ContentType MimeType
+	// Quality is the preference between 0.001 and 1, where zero is treated as 1
+	Quality float64
+}
+
+// NewNegotiatedEntity creates a response entity that accepts any of the given content types, in order
+// of preference. The response is decoded by the codec registered for the content type given by the
+// response, which is then set as the ContentType of the entity. As such, content should be a
+// reference that all the accepted types can decode into.
+func NewNegotiatedEntity(content interface{}, accepted ...AcceptedType) *Entity {
+	return &Entity{
+		Content: content,
+		Accept:  accepted,
+	}
+}
+
+// acceptHeader formats the Accept header value of the accepted types
+func acceptHeader(accepted []AcceptedType) string {
+	values := make([]string, len(accepted))
+	for i, a := range accepted {
+		values[i] = string(a.ContentType)
+		if a.Quality > 0 && a.Quality < 1 {
+			values[i] += ";q=" + strconv.FormatFloat(a.Quality, 'f', -1, 64)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// negotiateContentType sets the entity's ContentType to that of the response, provided it was
+// one of those accepted
+func negotiateContentType(respOut *Entity, resp *http.Response) error {
+	contentType := resp.Header.Get(headerContentType)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("failed to parse response content type %q: %w", contentType, err)
+	}
+	for _, a := range respOut.Accept {
+		if mediaRangeMatches(string(a.ContentType), mediaType) {
+			respOut.ContentType = MimeType(mediaType)
+			return nil
+		}
+	}
+	return fmt.Errorf("response content type %s was not one of those accepted: %s",
+		mediaType, acceptHeader(respOut.Accept))
+}
+
+func mediaRangeMatches(mediaRange string, mediaType string) bool {
+	mediaRange = strings.ToLower(mediaRange)
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleNewNegotiatedEntity() {
+	// Setup a test HTTP server that prefers to respond with CSV
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("Accept:", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		_, _ = w.Write([]byte("name,count\nwidgets,3\ngadgets,5\n"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type Inventory struct {
+		Name  string `json:"name" csv:"name"`
+		Count int    `json:"count" csv:"count"`
+	}
+	var inventory []Inventory
+
+	respOut := restclient.NewNegotiatedEntity(&inventory,
+		restclient.AcceptedType{ContentType: restclient.JsonType},
+		restclient.AcceptedType{ContentType: restclient.CsvType, Quality: 0.5})
+	err := client.Exchange("GET", "/inventory", nil, nil, respOut)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(respOut.ContentType, inventory)
+	// Output:
+	// Accept: application/json, text/csv;q=0.5
+	// text/csv [{widgets 3} {gadgets 5}]
+}
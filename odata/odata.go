@@ -0,0 +1,272 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package odata builds the system query options of OData-flavored APIs, such as $filter and
+$orderby, as url.Values for use with restclient.Client.Exchange.
+
+	query := odata.NewQuery().
+		Filter(odata.And(odata.Eq("Status", "Active"), odata.Gt("Price", 10))).
+		Select("Id", "Name").
+		OrderBy("Name").
+		Top(20).
+		Values()
+	err := client.Exchange("GET", "Products", query, nil, restclient.NewJsonEntity(&products))
+*/
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a fluent builder of OData system query options. The zero value is ready to use.
+type Query struct {
+	filter  Expr
+	selects []string
+	expand  []string
+	orderBy []string
+	top     int
+	hasTop  bool
+	skip    int
+	count   bool
+	search  string
+}
+
+// NewQuery creates an empty Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Filter sets the $filter option, which replaces any previous filter. A nil or empty expression
+// omits the option.
+func (q *Query) Filter(expr Expr) *Query {
+	q.filter = expr
+	return q
+}
+
+// Select adds properties to the $select option
+func (q *Query) Select(properties ...string) *Query {
+	q.selects = append(q.selects, properties...)
+	return q
+}
+
+// Expand adds navigation properties to the $expand option
+func (q *Query) Expand(properties ...string) *Query {
+	q.expand = append(q.expand, properties...)
+	return q
+}
+
+// OrderBy adds properties to the $orderby option in ascending order
+func (q *Query) OrderBy(properties ...string) *Query {
+	q.orderBy = append(q.orderBy, properties...)
+	return q
+}
+
+// OrderByDesc adds properties to the $orderby option in descending order
+func (q *Query) OrderByDesc(properties ...string) *Query {
+	for _, property := range properties {
+		q.orderBy = append(q.orderBy, property+" desc")
+	}
+	return q
+}
+
+// Top sets the $top option, the maximum number of results
+func (q *Query) Top(n int) *Query {
+	q.top = n
+	q.hasTop = true
+	return q
+}
+
+// Skip sets the $skip option, the number of results to skip
+func (q *Query) Skip(n int) *Query {
+	q.skip = n
+	return q
+}
+
+// Count sets the $count option, which requests the total number of matching results
+func (q *Query) Count() *Query {
+	q.count = true
+	return q
+}
+
+// Search sets the $search option
+func (q *Query) Search(terms string) *Query {
+	q.search = terms
+	return q
+}
+
+// Values returns the query options, which are escaped when encoded by the url package
+func (q *Query) Values() url.Values {
+	values := url.Values{}
+	if q.filter != nil && q.filter.String() != "" {
+		values.Set("$filter", q.filter.String())
+	}
+	if len(q.selects) > 0 {
+		values.Set("$select", strings.Join(q.selects, ","))
+	}
+	if len(q.expand) > 0 {
+		values.Set("$expand", strings.Join(q.expand, ","))
+	}
+	if len(q.orderBy) > 0 {
+		values.Set("$orderby", strings.Join(q.orderBy, ","))
+	}
+	if q.hasTop {
+		values.Set("$top", strconv.Itoa(q.top))
+	}
+	if q.skip > 0 {
+		values.Set("$skip", strconv.Itoa(q.skip))
+	}
+	if q.count {
+		values.Set("$count", "true")
+	}
+	if q.search != "" {
+		values.Set("$search", q.search)
+	}
+	return values
+}
+
+// Expr is a $filter expression
+type Expr interface {
+	String() string
+}
+
+type rawExpr string
+
+func (e rawExpr) String() string {
+	return string(e)
+}
+
+// Raw is an expression used as is, such as for functions not provided by this package
+func Raw(expr string) Expr {
+	return rawExpr(expr)
+}
+
+func comparison(property string, operator string, value interface{}) Expr {
+	return rawExpr(property + " " + operator + " " + Literal(value))
+}
+
+// Eq compares the property to be equal to the value, which is formatted by Literal
+func Eq(property string, value interface{}) Expr {
+	return comparison(property, "eq", value)
+}
+
+// Ne compares the property to be not equal to the value
+func Ne(property string, value interface{}) Expr {
+	return comparison(property, "ne", value)
+}
+
+// Gt compares the property to be greater than the value
+func Gt(property string, value interface{}) Expr {
+	return comparison(property, "gt", value)
+}
+
+// Ge compares the property to be greater than or equal to the value
+func Ge(property string, value interface{}) Expr {
+	return comparison(property, "ge", value)
+}
+
+// Lt compares the property to be less than the value
+func Lt(property string, value interface{}) Expr {
+	return comparison(property, "lt", value)
+}
+
+// Le compares the property to be less than or equal to the value
+func Le(property string, value interface{}) Expr {
+	return comparison(property, "le", value)
+}
+
+// In compares the property to be one of the values
+func In(property string, values ...interface{}) Expr {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = Literal(value)
+	}
+	return rawExpr(property + " in (" + strings.Join(literals, ",") + ")")
+}
+
+// Contains matches a string property containing the value
+func Contains(property string, value string) Expr {
+	return rawExpr("contains(" + property + "," + Literal(value) + ")")
+}
+
+// StartsWith matches a string property starting with the value
+func StartsWith(property string, value string) Expr {
+	return rawExpr("startswith(" + property + "," + Literal(value) + ")")
+}
+
+// EndsWith matches a string property ending with the value
+func EndsWith(property string, value string) Expr {
+	return rawExpr("endswith(" + property + "," + Literal(value) + ")")
+}
+
+func logical(operator string, exprs []Expr) Expr {
+	parts := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		if expr != nil && expr.String() != "" {
+			parts = append(parts, expr.String())
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1:
+		return rawExpr(parts[0])
+	}
+	return rawExpr("(" + strings.Join(parts, " "+operator+" ") + ")")
+}
+
+// And combines the expressions such that all must match. Nil and empty expressions are ignored and,
+// when there are no others, the result is nil, which Filter treats as no filter.
+func And(exprs ...Expr) Expr {
+	return logical("and", exprs)
+}
+
+// Or combines the expressions such that any must match. Nil and empty expressions are ignored as
+// for And.
+func Or(exprs ...Expr) Expr {
+	return logical("or", exprs)
+}
+
+// Not negates the expression, where a nil or empty expression remains nil
+func Not(expr Expr) Expr {
+	if expr == nil || expr.String() == "" {
+		return nil
+	}
+	return rawExpr("not (" + expr.String() + ")")
+}
+
+// Literal formats the value as an OData literal. Strings are quoted with embedded quotes doubled,
+// times are formatted as RFC 3339, and nil is null.
+func Literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return Literal(v.String())
+	default:
+		return fmt.Sprint(v)
+	}
+}
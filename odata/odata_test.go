@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package odata_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient/odata"
+	"net/url"
+	"time"
+)
+
+func ExampleQuery() {
+	query := odata.NewQuery().
+		Filter(odata.And(
+			odata.Eq("Status", "Active"),
+			odata.Gt("Price", 10.5),
+			odata.Or(odata.Contains("Name", "O'Brien"), odata.In("Category", "Tools", "Garden")),
+		)).
+		Select("Id", "Name").
+		OrderByDesc("Created").
+		Top(20).
+		Skip(40).
+		Values()
+
+	fmt.Println(query.Get("$filter"))
+	fmt.Println(query.Encode())
+	// Output:
+	// (Status eq 'Active' and Price gt 10.5 and (contains(Name,'O''Brien') or Category in ('Tools','Garden')))
+	// %24filter=%28Status+eq+%27Active%27+and+Price+gt+10.5+and+%28contains%28Name%2C%27O%27%27Brien%27%29+or+Category+in+%28%27Tools%27%2C%27Garden%27%29%29%29&%24orderby=Created+desc&%24select=Id%2CName&%24skip=40&%24top=20
+}
+
+func ExampleAnd() {
+	// builds the filter from optional criteria, any of which may be unset
+	search := func(status string, category string) url.Values {
+		var byStatus, byCategory odata.Expr
+		if status != "" {
+			byStatus = odata.Eq("Status", status)
+		}
+		if category != "" {
+			byCategory = odata.Eq("Category", category)
+		}
+		return odata.NewQuery().Filter(odata.And(byStatus, byCategory)).Top(10).Values()
+	}
+
+	fmt.Println(search("Active", "Tools").Encode())
+	fmt.Println(search("Active", "").Encode())
+	fmt.Println(search("", "").Encode())
+	// Output:
+	// %24filter=%28Status+eq+%27Active%27+and+Category+eq+%27Tools%27%29&%24top=10
+	// %24filter=Status+eq+%27Active%27&%24top=10
+	// %24top=10
+}
+
+func ExampleLiteral() {
+	fmt.Println(odata.Literal("it's"))
+	fmt.Println(odata.Literal(time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)))
+	fmt.Println(odata.Literal(nil))
+	fmt.Println(url.Values{"$filter": {odata.Not(odata.Eq("Deleted", true)).String()}}.Encode())
+	// Output:
+	// 'it''s'
+	// 2020-05-01T12:00:00Z
+	// null
+	// %24filter=not+%28Deleted+eq+true%29
+}
@@ -0,0 +1,623 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package openapi validates exchanges against an OpenAPI 3 specification.
+
+Load a specification, in JSON or YAML form, and add the interceptor created by Spec.Interceptor to a
+restclient.Client. Outgoing requests are validated for a documented path and method, the presence
+and form of path, query, and header parameters, and the request body's content type and schema.
+Incoming responses are validated for a documented status code and the response body's schema.
+This is most useful during development and testing to catch drift between client code and the API.
+
+Schemas are validated with the jsonschema package, so bodies are only validated against schemas
+of JSON media types. The interceptor only buffers JSON bodies up to Spec.MaxBodySize, so that other
+bodies, such as large downloads, are still streamed; the content type of those is validated, but
+not their content.
+*/
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/jsonschema"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// DefaultMaxBodySize is the size of the largest body validated by the interceptor by default
+const DefaultMaxBodySize = 1 << 20
+
+// Spec is a loaded OpenAPI 3 specification
+type Spec struct {
+	// MaxBodySize is the size of the largest JSON body that the interceptor buffers to validate,
+	// which defaults to DefaultMaxBodySize. Larger bodies are streamed without validating their content.
+	MaxBodySize int64
+
+	doc      map[string]interface{}
+	basePath string
+	routes   []*route
+}
+
+type route struct {
+	template string
+	segments []string
+	literals int
+	item     map[string]interface{}
+}
+
+// ValidationError conveys the problems found validating a request or response
+type ValidationError struct {
+	// Direction is either "request" or "response"
+	Direction string
+	Method    string
+	Path      string
+	Problems  []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("OpenAPI %s validation failed for %s %s: %s",
+		e.Direction, e.Method, e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Load reads and parses the specification in the named JSON or YAML file
+func Load(filename string) (*Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read specification: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses the specification given in JSON or YAML form
+func Parse(data []byte) (*Spec, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		// YAML is a superset of JSON, but the result needs normalizing into JSON's generic form
+		var yamlDoc interface{}
+		if yamlErr := yaml.Unmarshal(data, &yamlDoc); yamlErr != nil {
+			return nil, fmt.Errorf("failed to parse specification: %w", yamlErr)
+		}
+		normalized, err := json.Marshal(normalizeYaml(yamlDoc))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse specification: %w", err)
+		}
+		if err := json.Unmarshal(normalized, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse specification: %w", err)
+		}
+	}
+
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported specification version %q", version)
+	}
+
+	spec := &Spec{doc: doc}
+	if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			if serverUrl, ok := server["url"].(string); ok {
+				if parsed, err := url.Parse(serverUrl); err == nil {
+					spec.basePath = strings.TrimSuffix(parsed.Path, "/")
+				}
+			}
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for template, item := range paths {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref, ok := itemMap["$ref"].(string); ok {
+			if resolved, ok := spec.resolve(ref).(map[string]interface{}); ok {
+				itemMap = resolved
+			}
+		}
+		r := &route{
+			template: template,
+			segments: splitPath(template),
+			item:     itemMap,
+		}
+		for _, segment := range r.segments {
+			if !isTemplateSegment(segment) {
+				r.literals++
+			}
+		}
+		spec.routes = append(spec.routes, r)
+	}
+	// prefer the most specific templates, such as /servers/detail over /servers/{id}
+	sort.Slice(spec.routes, func(i, j int) bool {
+		if spec.routes[i].literals != spec.routes[j].literals {
+			return spec.routes[i].literals > spec.routes[j].literals
+		}
+		return spec.routes[i].template < spec.routes[j].template
+	})
+
+	return spec, nil
+}
+
+// Document returns the specification in the generic form of decoded JSON, such as for tools that
+// generate code from it
+func (s *Spec) Document() map[string]interface{} {
+	return s.doc
+}
+
+// Resolve locates the node referenced by a local reference, such as "#/components/schemas/Server".
+// Returns nil if the reference doesn't resolve.
+func (s *Spec) Resolve(ref string) interface{} {
+	return s.resolve(ref)
+}
+
+// Interceptor creates an Interceptor that validates each request before it is sent and each response
+// as it is received. When report is nil, a violation fails the exchange with a *ValidationError,
+// which suits development mode. Otherwise, violations are passed to report and the exchange proceeds,
+// such as for logging drift in production. A request whose path or method is not documented is
+// reported once, without validating its response.
+func (s *Spec) Interceptor(report func(err *ValidationError)) restclient.Interceptor {
+	return func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		if _, _, _, problem := s.findOperation(req); problem != "" {
+			verr := &ValidationError{Direction: "request", Method: req.Method, Path: req.URL.Path,
+				Problems: []string{problem}}
+			if report == nil {
+				return nil, verr
+			}
+			report(verr)
+			return next(req)
+		}
+
+		var body []byte
+		validateBody := true
+		if req.Body != nil && req.Body != http.NoBody {
+			var restored io.ReadCloser
+			var err error
+			body, restored, validateBody, err = s.bufferBody(req.Body, req.Header)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+			req.Body = restored
+			if validateBody && req.GetBody == nil {
+				req.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(body)), nil
+				}
+			}
+		}
+		if verr := s.validateRequest(req, body, validateBody); verr != nil {
+			if report == nil {
+				return nil, verr
+			}
+			report(verr)
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+
+		respBody, restored, validateBody, err := s.bufferBody(resp.Body, resp.Header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		resp.Body = restored
+
+		if verr := s.validateResponse(req, resp, respBody, validateBody); verr != nil {
+			if report == nil {
+				_ = resp.Body.Close()
+				return nil, verr
+			}
+			report(verr)
+		}
+		return resp, nil
+	}
+}
+
+// bufferBody reads a JSON body, up to MaxBodySize, for validation and returns a replacement for body.
+// Other bodies are not read and complete is false.
+func (s *Spec) bufferBody(body io.ReadCloser, header http.Header) (content []byte, restored io.ReadCloser,
+	complete bool, err error) {
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if !strings.Contains(mediaType, "json") {
+		return nil, body, false, nil
+	}
+	limit := s.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	content, err = ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		_ = body.Close()
+		return nil, nil, false, err
+	}
+	if int64(len(content)) > limit {
+		// too large to validate, so the rest is streamed after what was read
+		return nil, struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(content), body), body}, false, nil
+	}
+	_ = body.Close()
+	return content, ioutil.NopCloser(bytes.NewReader(content)), true, nil
+}
+
+// ValidateRequest validates the request, with the given body content, against the specification.
+// Returns a *ValidationError describing any problems.
+func (s *Spec) ValidateRequest(req *http.Request, body []byte) error {
+	if verr := s.validateRequest(req, body, true); verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// validateRequest validates the content of the body only when validateBody is set. Otherwise, the
+// request is presumed to have a body, whose content type is still validated.
+func (s *Spec) validateRequest(req *http.Request, body []byte, validateBody bool) *ValidationError {
+	verr := &ValidationError{Direction: "request", Method: req.Method, Path: req.URL.Path}
+
+	r, operation, pathParams, problem := s.findOperation(req)
+	if problem != "" {
+		verr.Problems = append(verr.Problems, problem)
+		return verr
+	}
+
+	for _, param := range s.parameters(r, operation) {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		required, _ := param["required"].(bool)
+
+		var value string
+		var present bool
+		switch in {
+		case "path":
+			value, present = pathParams[name]
+		case "query":
+			var values []string
+			values, present = req.URL.Query()[name]
+			value = strings.Join(values, ",")
+		case "header":
+			value = req.Header.Get(name)
+			present = value != ""
+		default:
+			continue
+		}
+
+		if !present {
+			if required {
+				verr.Problems = append(verr.Problems, fmt.Sprintf("missing required %s parameter %q", in, name))
+			}
+			continue
+		}
+		if schemaNode, ok := param["schema"]; ok {
+			if problem := s.validateParameter(schemaNode, value); problem != "" {
+				verr.Problems = append(verr.Problems, fmt.Sprintf("%s parameter %q: %s", in, name, problem))
+			}
+		}
+	}
+
+	if requestBody, ok := s.deref(operation["requestBody"]).(map[string]interface{}); ok {
+		required, _ := requestBody["required"].(bool)
+		if validateBody && len(body) == 0 {
+			if required {
+				verr.Problems = append(verr.Problems, "missing required request body")
+			}
+		} else {
+			content, _ := requestBody["content"].(map[string]interface{})
+			verr.Problems = append(verr.Problems,
+				s.validateContent(content, req.Header.Get("Content-Type"), body, "request body")...)
+		}
+	}
+
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// ValidateResponse validates the response, with the given body content, to the request against the
+// specification. Returns a *ValidationError describing any problems.
+func (s *Spec) ValidateResponse(req *http.Request, resp *http.Response, body []byte) error {
+	if verr := s.validateResponse(req, resp, body, true); verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// validateResponse validates the content of the body only when validateBody is set, as described
+// for validateRequest
+func (s *Spec) validateResponse(req *http.Request, resp *http.Response, body []byte, validateBody bool) *ValidationError {
+	verr := &ValidationError{Direction: "response", Method: req.Method, Path: req.URL.Path}
+
+	_, operation, _, problem := s.findOperation(req)
+	if problem != "" {
+		verr.Problems = append(verr.Problems, problem)
+		return verr
+	}
+
+	responses, _ := operation["responses"].(map[string]interface{})
+	status := strconv.Itoa(resp.StatusCode)
+	response, ok := responses[status]
+	if !ok {
+		response, ok = responses[status[:1]+"XX"]
+	}
+	if !ok {
+		response, ok = responses["default"]
+	}
+	if !ok {
+		verr.Problems = append(verr.Problems, fmt.Sprintf("undocumented response status %d", resp.StatusCode))
+		return verr
+	}
+
+	if responseMap, ok := s.deref(response).(map[string]interface{}); ok && hasResponseBody(resp, body, validateBody) {
+		if content, ok := responseMap["content"].(map[string]interface{}); ok {
+			verr.Problems = append(verr.Problems,
+				s.validateContent(content, resp.Header.Get("Content-Type"), body, "response body")...)
+		}
+	}
+
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// hasResponseBody determines if there is a body to validate, where one that wasn't buffered is
+// presumed to be present when the response has a content type
+func hasResponseBody(resp *http.Response, body []byte, validateBody bool) bool {
+	if validateBody {
+		return len(body) > 0
+	}
+	return resp.Header.Get("Content-Type") != ""
+}
+
+func (s *Spec) findOperation(req *http.Request) (*route, map[string]interface{}, map[string]string, string) {
+	// the base path of the first server is optional, since test servers are usually mounted at the root
+	path := req.URL.Path
+	if s.basePath != "" && strings.HasPrefix(path, s.basePath+"/") {
+		path = strings.TrimPrefix(path, s.basePath)
+	}
+	segments := splitPath(path)
+
+	for _, r := range s.routes {
+		params, ok := r.match(segments)
+		if !ok {
+			continue
+		}
+		operation, ok := r.item[strings.ToLower(req.Method)].(map[string]interface{})
+		if !ok {
+			return nil, nil, nil, fmt.Sprintf("method %s is not documented for path %s", req.Method, r.template)
+		}
+		return r, operation, params, ""
+	}
+	return nil, nil, nil, "path is not documented"
+}
+
+// parameters merges the path item's parameters with the operation's, where the latter take precedence
+func (s *Spec) parameters(r *route, operation map[string]interface{}) []map[string]interface{} {
+	merged := make(map[string]map[string]interface{})
+	var order []string
+	for _, source := range []interface{}{r.item["parameters"], operation["parameters"]} {
+		list, _ := source.([]interface{})
+		for _, p := range list {
+			param, ok := s.deref(p).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%v:%v", param["in"], param["name"])
+			if _, exists := merged[key]; !exists {
+				order = append(order, key)
+			}
+			merged[key] = param
+		}
+	}
+	params := make([]map[string]interface{}, len(order))
+	for i, key := range order {
+		params[i] = merged[key]
+	}
+	return params
+}
+
+// validateParameter converts the string form of the parameter according to its schema's type and
+// validates the converted value
+func (s *Spec) validateParameter(schemaNode interface{}, raw string) string {
+	schemaMap, _ := s.deref(schemaNode).(map[string]interface{})
+	value := convertParameter(schemaMap, raw)
+	if value == nil {
+		return fmt.Sprintf("%q is not a valid %v", raw, schemaMap["type"])
+	}
+	if err := s.validateSchema(schemaNode, value); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func convertParameter(schema map[string]interface{}, raw string) interface{} {
+	switch schema["type"] {
+	case "integer":
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return float64(i)
+		}
+		return nil
+	case "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+		return nil
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+		return nil
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		var values []interface{}
+		for _, part := range strings.Split(raw, ",") {
+			converted := convertParameter(items, part)
+			if converted == nil {
+				return nil
+			}
+			values = append(values, converted)
+		}
+		return values
+	default:
+		return raw
+	}
+}
+
+func (s *Spec) validateContent(content map[string]interface{}, contentType string, body []byte, what string) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []string{fmt.Sprintf("%s has invalid content type %q", what, contentType)}
+	}
+
+	media, ok := content[mediaType]
+	if !ok {
+		media, ok = content[strings.Split(mediaType, "/")[0]+"/*"]
+	}
+	if !ok {
+		media, ok = content["*/*"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("%s has undocumented content type %s", what, mediaType)}
+	}
+
+	mediaMap, _ := media.(map[string]interface{})
+	schemaNode, ok := mediaMap["schema"]
+	// a nil body is one that wasn't buffered for validation
+	if !ok || !strings.Contains(mediaType, "json") || body == nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("%s is not valid JSON: %v", what, err)}
+	}
+	if err := s.validateSchema(schemaNode, value); err != nil {
+		if schemaErr, ok := err.(*jsonschema.ValidationError); ok {
+			var problems []string
+			for _, fieldError := range schemaErr.Errors {
+				problems = append(problems, what+" "+fieldError.String())
+			}
+			return problems
+		}
+		return []string{fmt.Sprintf("%s: %v", what, err)}
+	}
+	return nil
+}
+
+func (s *Spec) validateSchema(schemaNode interface{}, value interface{}) error {
+	// wrap the schema in a document rooted at the spec, so that references resolve
+	schema, err := jsonschema.FromDocument(map[string]interface{}{
+		"components": s.doc["components"],
+		"schema":     schemaNode,
+	}, "/schema")
+	if err != nil {
+		return err
+	}
+	return schema.Validate(value)
+}
+
+// deref resolves a node that is a reference object
+func (s *Spec) deref(node interface{}) interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if ref, ok := m["$ref"].(string); ok {
+			return s.resolve(ref)
+		}
+	}
+	return node
+}
+
+func (s *Spec) resolve(ref string) interface{} {
+	var node interface{} = s.doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[token]
+	}
+	return node
+}
+
+func (r *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if isTemplateSegment(segment) {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = value
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return []string{}
+	}
+	return strings.Split(trimmed, "/")
+}
+
+func isTemplateSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// normalizeYaml converts the maps produced by the YAML decoder into string keyed maps
+func normalizeYaml(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = normalizeYaml(v)
+		}
+		return n
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			converted[fmt.Sprint(k)] = normalizeYaml(v)
+		}
+		return converted
+	case []interface{}:
+		for i, v := range n {
+			n[i] = normalizeYaml(v)
+		}
+		return n
+	default:
+		return node
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/openapi"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+const serversSpec = `
+openapi: 3.0.3
+info:
+  title: Servers
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /servers:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Server'
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Server'
+  /servers/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Server'
+components:
+  schemas:
+    Server:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+`
+
+func ExampleSpec_Interceptor() {
+	// Setup a test HTTP server that doesn't quite conform to the spec
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(`{"hostname": "web"}`))
+	}))
+	defer ts.Close()
+
+	spec, err := openapi.Parse([]byte(serversSpec))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	err = client.SetBaseUrl(ts.URL + "/v1/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	client.AddInterceptor(spec.Interceptor(nil))
+
+	type Server struct {
+		Name string `json:"name"`
+	}
+	var server Server
+
+	err = client.Exchange("POST", "servers", nil, restclient.NewJsonEntity(Server{Name: "web"}), restclient.NewJsonEntity(&server))
+	fmt.Println(err)
+
+	err = client.Exchange("GET", "servers/abc", nil, nil, restclient.NewJsonEntity(&server))
+	fmt.Println(err)
+
+	err = client.Exchange("DELETE", "servers/1", nil, nil, nil)
+	fmt.Println(err)
+
+	// Output:
+	// failed to send request: OpenAPI response validation failed for POST /v1/servers: response body /name: required property is missing
+	// failed to send request: OpenAPI request validation failed for GET /v1/servers/abc: path parameter "id": "abc" is not a valid integer
+	// failed to send request: OpenAPI request validation failed for DELETE /v1/servers/1: method DELETE is not documented for path /servers/{id}
+}
+
+func ExampleSpec_Interceptor_report() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer ts.Close()
+
+	spec, err := openapi.Parse([]byte(serversSpec))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	err = client.SetBaseUrl(ts.URL + "/v1/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// In production, report drift rather than failing
+	client.AddInterceptor(spec.Interceptor(func(err *openapi.ValidationError) {
+		fmt.Println("DRIFT", err.Direction, err.Problems)
+	}))
+
+	err = client.Exchange("GET", "servers/1", nil, nil, nil)
+	fmt.Println(err)
+
+	// an undocumented path is only reported for the request
+	err = client.Exchange("GET", "images", nil, nil, nil)
+	fmt.Println(err)
+
+	// Output:
+	// DRIFT response [undocumented response status 418]
+	// 418 I'm a teapot body=[]
+	// DRIFT request [path is not documented]
+	// 418 I'm a teapot body=[]
+}
+
+func ExampleSpec_MaxBodySize() {
+	// Setup a test HTTP server whose responses omit the required name
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/servers/1" {
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		} else {
+			_, _ = fmt.Fprintf(w, `{"id": 2, "notes": %q}`, strings.Repeat("x", 100))
+		}
+	}))
+	defer ts.Close()
+
+	spec, err := openapi.Parse([]byte(serversSpec))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// larger bodies are streamed without validating their content
+	spec.MaxBodySize = 64
+
+	client := restclient.NewClient()
+	err = client.SetBaseUrl(ts.URL + "/v1/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	client.AddInterceptor(spec.Interceptor(nil))
+
+	var server struct {
+		Id    int
+		Notes string
+	}
+	err = client.Exchange("GET", "servers/1", nil, nil, restclient.NewJsonEntity(&server))
+	fmt.Println(err)
+
+	err = client.Exchange("GET", "servers/2", nil, nil, restclient.NewJsonEntity(&server))
+	fmt.Println(server.Id, len(server.Notes), err)
+
+	// Output:
+	// failed to send request: OpenAPI response validation failed for GET /v1/servers/1: response body /name: required property is missing
+	// 2 100 <nil>
+}
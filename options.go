@@ -0,0 +1,162 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOption customizes an individual exchange
+type RequestOption func(o *requestOptions)
+
+type requestOptions struct {
+	responseInfo *ResponseInfo
+	etag         *string
+	respHeader   *http.Header
+	isSuccess    SuccessPredicate
+	// header holds headers to set on the request
+	header        http.Header
+	operationName string
+	idleTimeout   time.Duration
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	options := &requestOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// ResponseInfo conveys metadata about the response of an exchange
+type ResponseInfo struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// RateLimit is populated when the response included rate limit headers
+	RateLimit *RateLimitInfo
+	// Deprecation is populated when the response included Deprecation, Sunset, or Warning headers
+	Deprecation *DeprecationInfo
+	// Trailer holds the trailers sent after the body, such as a checksum computed by a streaming
+	// backend. When the response announced trailers, it is populated at the end of the exchange by
+	// reading the remainder of the body of a successful response, up to 64 KiB.
+	Trailer http.Header
+}
+
+// WithResponseInfo populates info with the metadata of the response. It is populated for successful
+// and failed responses, but not when the request could not be sent.
+func WithResponseInfo(info *ResponseInfo) RequestOption {
+	return func(o *requestOptions) {
+		o.responseInfo = info
+	}
+}
+
+// WithResponseHeaders populates header with the headers of the response, such as to obtain the
+// Location of a created resource. Like WithResponseInfo, it is populated for successful and failed
+// responses.
+func WithResponseHeaders(header *http.Header) RequestOption {
+	return func(o *requestOptions) {
+		o.respHeader = header
+	}
+}
+
+// WithHeader sets a header of the request, such as a tracing or tenant header, that is specific to
+// the exchange. It replaces any header of the same name set by the entities, but interceptors
+// may still replace it.
+func WithHeader(name string, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.setHeader(name, value)
+	}
+}
+
+// WithHeaders sets the given headers of the request in the same way as WithHeader
+func WithHeaders(header http.Header) RequestOption {
+	return func(o *requestOptions) {
+		for name, values := range header {
+			for i, value := range values {
+				if i == 0 {
+					o.setHeader(name, value)
+				} else {
+					o.header.Add(name, value)
+				}
+			}
+		}
+	}
+}
+
+func (o *requestOptions) setHeader(name string, value string) {
+	if o.header == nil {
+		o.header = make(http.Header)
+	}
+	o.header.Set(name, value)
+}
+
+// WithOperationName labels the exchange with the stable name of its operation, such as "ListServers",
+// which metrics and audit records, such as of LatencyRecorder and Audit, use rather than the URL,
+// whose embedded IDs would otherwise explode the cardinality of their labels. Interceptors obtain
+// the name with OperationFromContext.
+func WithOperationName(name string) RequestOption {
+	return func(o *requestOptions) {
+		o.operationName = name
+	}
+}
+
+// WithIdleTimeout sets a watchdog on the exchange, such as for a long poll, streaming download, or
+// feed whose connection may be silently half-open. The exchange is aborted with
+// ErrResponseHeaderTimeout when the response headers don't arrive within the timeout of the request
+// being written and with ErrBodyReadTimeout when no bytes of the response body arrive within the
+// timeout. It replaces the BodyReadTimeout of the Client, where a negative timeout disables the
+// watchdog.
+func WithIdleTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.idleTimeout = timeout
+	}
+}
+
+// applyContext conveys the options needed by interceptors through the context of the request
+func (o *requestOptions) applyContext(ctx context.Context) context.Context {
+	if o.operationName != "" {
+		ctx = ContextWithAttribute(ctx, OperationAttribute, o.operationName)
+	}
+	return ctx
+}
+
+func (o *requestOptions) applyHeaders(req *http.Request) {
+	for name, values := range o.header {
+		req.Header[name] = values
+	}
+}
+
+func (o *requestOptions) captureResponse(resp *http.Response) {
+	if o.etag != nil {
+		*o.etag = resp.Header.Get("ETag")
+	}
+	if o.respHeader != nil {
+		*o.respHeader = resp.Header
+	}
+	if o.responseInfo != nil {
+		*o.responseInfo = ResponseInfo{
+			StatusCode:  resp.StatusCode,
+			Status:      resp.Status,
+			Header:      resp.Header,
+			RateLimit:   ParseRateLimit(resp.Header),
+			Deprecation: ParseDeprecation(resp.Header),
+		}
+	}
+}
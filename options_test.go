@@ -0,0 +1,172 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleWithResponseInfo() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "50")
+		w.Header().Set("RateLimit-Remaining", "49")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var info restclient.ResponseInfo
+	err := client.Exchange("POST", "/jobs", nil, nil, nil,
+		restclient.WithResponseInfo(&info))
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(info.StatusCode, info.RateLimit.Remaining)
+	// Output:
+	// 202 49
+}
+
+func ExampleWithResponseHeaders() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/servers/abc")
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var header http.Header
+	err := client.Exchange("POST", "/servers", nil, nil, nil,
+		restclient.WithResponseHeaders(&header))
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(header.Get("Location"), header.Get("X-Request-Id"))
+	// Output:
+	// /servers/abc req-1
+}
+
+func ExampleWithHeader() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.Header.Get("X-Tenant-Id"), r.Header.Get("X-Request-Id"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	err := client.Exchange("GET", "/servers", nil, nil, nil,
+		restclient.WithHeader("X-Tenant-Id", "123456"),
+		restclient.WithHeaders(http.Header{"X-Request-Id": {"req-1"}}))
+	fmt.Println(err)
+	// Output:
+	// RECV 123456 req-1
+	// <nil>
+}
+
+func ExampleResponseInfo_trailer() {
+	// Setup a test HTTP server that streams content followed by its checksum
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("line 1\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("line 2\n"))
+		w.Header().Set("X-Checksum", "c0ffee")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var info restclient.ResponseInfo
+	content := restclient.NewTextEntity("")
+	err := client.Exchange("GET", "/logs", nil, nil, content, restclient.WithResponseInfo(&info))
+	fmt.Printf("%q %s %v\n", content.Content, info.Trailer.Get("X-Checksum"), err)
+	// Output:
+	// "line 1\nline 2\n" c0ffee <nil>
+}
+
+func ExampleWithResponseInfo_unreadBody() {
+	// Setup a test HTTP server that streams an endless feed
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Feed-Id", "feed-1")
+		for {
+			if _, err := w.Write([]byte("event\n")); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// without announced trailers, the body that wasn't read is not drained
+	var info restclient.ResponseInfo
+	err := client.Exchange("GET", "/feed", nil, nil, nil, restclient.WithResponseInfo(&info))
+	fmt.Println(info.Header.Get("X-Feed-Id"), err)
+	// Output:
+	// feed-1 <nil>
+}
+
+func ExampleWithOperationName() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// Real example starts here
+	latency := restclient.NewLatencyRecorder(restclient.LatencyConfig{})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(latency.Intercept)
+
+	for _, name := range []string{"web-1", "web-2", "db-1"} {
+		err := client.Exchange("GET", "/servers/"+name, nil, nil, nil,
+			restclient.WithOperationName("GetServer"))
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	for _, endpoint := range latency.Snapshot() {
+		fmt.Println(endpoint.Method, endpoint.Template, endpoint.Count)
+	}
+	// Output:
+	// GET GetServer 3
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const outboxSuffix = ".json"
+
+// Outbox durably queues mutating exchanges, such as those of an edge agent whose network may be
+// down, in a directory and flushes them in order, with retries, once connectivity returns. Each
+// exchange is persisted as an ExchangeSpec, so the client's interceptors, such as authentication,
+// are applied when the exchange is flushed.
+//
+// Zero values of the exported fields are replaced with the defaults noted on each field.
+type Outbox struct {
+	// InitialBackoff is the delay of Run after a flush could not complete, which defaults to 1s
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay of Run between flushes, which defaults to 5m
+	MaxBackoff time.Duration
+	// Clock is used for the backoff of Run, which defaults to SystemClock
+	Clock Clock
+	// OnDiscard, if set, is called with an exchange that was discarded because it failed in a way
+	// that is not worth retrying, such as with a 400 Bad Request response or ErrPolicyViolation
+	OnDiscard func(spec *ExchangeSpec, err error)
+
+	client *Client
+	dir    string
+	notify chan struct{}
+
+	mu       sync.Mutex
+	sequence uint64
+	// flushMu ensures exchanges are flushed by one caller at a time, which retains their order
+	flushMu sync.Mutex
+}
+
+// NewOutbox creates an Outbox that sends its exchanges with the client and persists them in dir,
+// which is created if needed. Exchanges remaining in dir from a prior process are retained.
+func NewOutbox(client *Client, dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	o := &Outbox{
+		client: client,
+		dir:    dir,
+		notify: make(chan struct{}, 1),
+	}
+	names, err := o.entries()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		o.sequence, _ = strconv.ParseUint(strings.TrimSuffix(names[len(names)-1], outboxSuffix), 10, 64)
+	}
+	return o, nil
+}
+
+// Enqueue persists the exchange described by the arguments, which are as described for
+// ExchangeWithContext, to be sent by a subsequent Flush or Run
+func (o *Outbox) Enqueue(ctx context.Context, method string, urlIn string, query url.Values, reqIn *Entity,
+	opts ...RequestOption) error {
+
+	spec, err := o.client.CaptureExchange(ctx, method, urlIn, query, reqIn, opts...)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode exchange: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sequence++
+	filename := filepath.Join(o.dir, fmt.Sprintf("%020d%s", o.sequence, outboxSuffix))
+
+	// written to a temporary file first, so that a partial exchange is never flushed
+	tempFile, err := ioutil.TempFile(o.dir, "enqueue*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(content)
+	if syncErr := tempFile.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ExchangeOrEnqueue performs the exchange immediately when the outbox is empty, otherwise it is
+// enqueued behind the pending exchanges to retain their order. An exchange that fails in a way
+// that is worth retrying, such as being unable to connect, is also enqueued. The returned queued
+// indicates if the exchange was enqueued, in which case respOut is not populated.
+//
+// The request content is encoded again to be enqueued after a failure, so it must not be an
+// io.Reader or ContentWriter.
+func (o *Outbox) ExchangeOrEnqueue(ctx context.Context, method string, urlIn string, query url.Values,
+	reqIn *Entity, respOut *Entity, opts ...RequestOption) (queued bool, err error) {
+
+	pending, err := o.Len()
+	if err != nil {
+		return false, err
+	}
+	if pending == 0 {
+		err = o.client.ExchangeWithContext(ctx, method, urlIn, query, reqIn, respOut, opts...)
+		if err == nil || ctx.Err() != nil || !isOutboxRetryable(err) {
+			return false, err
+		}
+	}
+	if err := o.Enqueue(ctx, method, urlIn, query, reqIn, opts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Len returns the number of pending exchanges
+func (o *Outbox) Len() (int, error) {
+	names, err := o.entries()
+	return len(names), err
+}
+
+// Flush sends the pending exchanges in order. It stops at the first exchange that fails in a way
+// that is worth retrying, such as being unable to connect, which remains pending, and returns its
+// error. An exchange that fails otherwise is discarded and given to OnDiscard.
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	names, err := o.entries()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		filename := filepath.Join(o.dir, name)
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read outbox entry: %w", err)
+		}
+		var spec ExchangeSpec
+		if err := json.Unmarshal(content, &spec); err != nil {
+			return fmt.Errorf("failed to decode outbox entry %s: %w", name, err)
+		}
+
+		err = o.client.Replay(ctx, &spec, nil)
+		if err != nil && (ctx.Err() != nil || isOutboxRetryable(err)) {
+			return err
+		}
+		if err := os.Remove(filename); err != nil {
+			return fmt.Errorf("failed to remove outbox entry: %w", err)
+		}
+		if err != nil && o.OnDiscard != nil {
+			o.OnDiscard(&spec, err)
+		}
+	}
+	return nil
+}
+
+// Run flushes the pending exchanges as they are enqueued, with exponential backoff between
+// flushes that could not complete, until ctx is done
+func (o *Outbox) Run(ctx context.Context) error {
+	initialBackoff := o.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	maxBackoff := o.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Minute
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	backoff := initialBackoff
+	for {
+		var wait <-chan time.Time
+		stopWait := func() {}
+		if err := o.Flush(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait, stopWait = startTimer(clock, backoff)
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		} else {
+			backoff = initialBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			stopWait()
+			return ctx.Err()
+		case <-wait:
+		case <-o.notify:
+			if wait != nil {
+				// continues waiting out the backoff, since the new exchange is behind the failed one
+				select {
+				case <-ctx.Done():
+					stopWait()
+					return ctx.Err()
+				case <-wait:
+				}
+			}
+		}
+	}
+}
+
+// entries returns the names of the pending exchanges, in order
+func (o *Outbox) entries() ([]string, error) {
+	files, err := ioutil.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), outboxSuffix) {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isOutboxRetryable determines if the error of an exchange is worth retrying later, which is limited
+// to failures of the transport, timeouts, and failed responses indicating the server is unavailable
+// or overloaded. Other errors, such as ErrPolicyViolation or an invalid URL, would recur on every
+// flush and block the exchanges behind them.
+func isOutboxRetryable(err error) bool {
+	var failed *FailedResponseError
+	if errors.As(err, &failed) {
+		switch failed.StatusCode {
+		case http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	for _, transient := range []error{ErrClientTimeout, ErrBodyReadTimeout, ErrTooManyRequests,
+		ErrClientShutdown, io.ErrUnexpectedEOF} {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// rather than an invalid URL, the transport failed to send the request
+		return urlErr.Op != "parse"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
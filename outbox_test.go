@@ -0,0 +1,143 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+)
+
+func ExampleOutbox() {
+	// Setup a test HTTP server that is initially unavailable and rejects invalid metrics
+	available := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Println("RECV", r.Method, r.URL.Path, strings.TrimSpace(string(body)))
+	}))
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	outbox, err := restclient.NewOutbox(client, dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, metric := range []string{"cpu", "invalid", "disk"} {
+		queued, err := outbox.ExchangeOrEnqueue(context.Background(), "POST", "/metrics", nil,
+			restclient.NewJsonEntity(map[string]string{"metric": metric}), nil)
+		fmt.Println(metric, queued, err)
+	}
+
+	// such as when the agent restarts
+	outbox, err = restclient.NewOutbox(client, dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outbox.OnDiscard = func(spec *restclient.ExchangeSpec, err error) {
+		fmt.Println("DISCARD", spec.Method, strings.TrimSpace(string(spec.Body)), err)
+	}
+	fmt.Println(outbox.Len())
+
+	available = true
+	err = outbox.Flush(context.Background())
+	fmt.Println(err)
+	fmt.Println(outbox.Len())
+	// Output:
+	// cpu true <nil>
+	// invalid true <nil>
+	// disk true <nil>
+	// 3 <nil>
+	// RECV POST /metrics {"metric":"cpu"}
+	// DISCARD POST {"metric":"invalid"} 400 Bad Request body=[]
+	// RECV POST /metrics {"metric":"disk"}
+	// <nil>
+	// 0 <nil>
+}
+
+func ExampleOutbox_Flush() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	outbox, err := restclient.NewOutbox(client, dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outbox.OnDiscard = func(spec *restclient.ExchangeSpec, err error) {
+		fmt.Println("DISCARD", spec.Method, err)
+	}
+
+	for _, path := range []string{"/admin/reset", "/metrics"} {
+		err := outbox.Enqueue(context.Background(), "POST", path, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// an exchange that would fail on every flush doesn't block those behind it
+	client.Policy = &restclient.RequestPolicy{
+		Deny: []restclient.PolicyRule{{Path: "/admin/**"}},
+	}
+	fmt.Println(outbox.Flush(context.Background()))
+
+	// an exchange that couldn't be sent remains pending
+	err = outbox.Enqueue(context.Background(), "POST", "/metrics", nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ts.Close()
+	fmt.Println(outbox.Flush(context.Background()) != nil)
+	fmt.Println(outbox.Len())
+	// Output:
+	// DISCARD POST request denied by policy: POST /admin/reset matches denied * /admin/**
+	// RECV POST /metrics
+	// <nil>
+	// true
+	// 1 <nil>
+}
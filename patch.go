@@ -0,0 +1,355 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// MergePatchType is the content type of JSON merge patches, as specified by RFC 7396, where
+// members set to null are removed from the target resource
+const MergePatchType MimeType = "application/merge-patch+json"
+
+func init() {
+	RegisterCodec(MergePatchType, mergePatchCodec{})
+}
+
+// NewMergePatchEntity creates an entity of MergePatchType whose content is encoded by
+// MarshalPatch, such as
+//
+//	type ServerPatch struct {
+//		Name        restclient.Optional `json:"name"`
+//		Description restclient.Nullable `json:"description"`
+//	}
+//
+//	// leaves the name as is and removes the description
+//	patch := &ServerPatch{Description: restclient.NullValue()}
+//	err := client.Exchange("PATCH", "/servers/123", nil, restclient.NewMergePatchEntity(patch), nil)
+func NewMergePatchEntity(content interface{}) *Entity {
+	return &Entity{
+		ContentType: MergePatchType,
+		Content:     content,
+	}
+}
+
+// patchField is implemented by the field types whose absence is distinguished from their zero value
+type patchField interface {
+	isSet() bool
+	patchValue() interface{}
+}
+
+// Optional is a field of a patch that is either absent, which is the zero value, or set to a value.
+// Absent fields are omitted by MarshalPatch, which plain structs can't distinguish from fields
+// set to their zero value.
+type Optional struct {
+	value interface{}
+	set   bool
+}
+
+// OptionalOf creates an Optional that is set to the value
+func OptionalOf(value interface{}) Optional {
+	return Optional{value: value, set: true}
+}
+
+// IsSet reports if the field is present
+func (o Optional) IsSet() bool {
+	return o.set
+}
+
+// Value returns the value of the field, which is nil when absent
+func (o Optional) Value() interface{} {
+	return o.value
+}
+
+// MarshalJSON encodes the value, where an absent field, when not encoded by MarshalPatch, is null
+func (o Optional) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON sets the field to the decoded value
+func (o *Optional) UnmarshalJSON(data []byte) error {
+	o.set = true
+	return json.Unmarshal(data, &o.value)
+}
+
+func (o Optional) isSet() bool {
+	return o.set
+}
+
+func (o Optional) patchValue() interface{} {
+	return o.value
+}
+
+// Nullable is a field of a patch that is either absent, which is the zero value, explicitly null,
+// or set to a value. Absent fields are omitted by MarshalPatch, whereas null fields are encoded
+// as null, such as to clear the field.
+type Nullable struct {
+	value interface{}
+	set   bool
+}
+
+// NullableOf creates a Nullable that is set to the value, which is null when nil
+func NullableOf(value interface{}) Nullable {
+	return Nullable{value: value, set: true}
+}
+
+// NullValue creates a Nullable that is explicitly null
+func NullValue() Nullable {
+	return Nullable{set: true}
+}
+
+// IsSet reports if the field is present, including when it is null
+func (n Nullable) IsSet() bool {
+	return n.set
+}
+
+// IsNull reports if the field is present and null
+func (n Nullable) IsNull() bool {
+	return n.set && n.value == nil
+}
+
+// Value returns the value of the field, which is nil when absent or null
+func (n Nullable) Value() interface{} {
+	return n.value
+}
+
+// MarshalJSON encodes the value, where an absent field, when not encoded by MarshalPatch, is null
+func (n Nullable) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.value)
+}
+
+// UnmarshalJSON sets the field to the decoded value, which is nil when null
+func (n *Nullable) UnmarshalJSON(data []byte) error {
+	n.set = true
+	n.value = nil
+	return json.Unmarshal(data, &n.value)
+}
+
+func (n Nullable) isSet() bool {
+	return n.set
+}
+
+func (n Nullable) patchValue() interface{} {
+	return n.value
+}
+
+// MarshalPatch encodes the content as JSON, like json.Marshal, except that struct fields that are
+// an absent Optional or Nullable are omitted. It can be used for APIs that take patches as
+// JsonType, such as
+//
+//	body, err := restclient.MarshalPatch(patch)
+//	...
+//	err = client.Exchange("PATCH", "/servers/123", nil, restclient.NewJsonEntity(json.RawMessage(body)), nil)
+func MarshalPatch(content interface{}) ([]byte, error) {
+	return json.Marshal(toPatchValue(reflect.ValueOf(content)))
+}
+
+var (
+	patchFieldType      = reflect.TypeOf((*patchField)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isJsonMarshaler determines if the type encodes itself, such as time.Time
+func isJsonMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// toPatchValue converts values that contain patch fields into generic maps and slices, which omit
+// the absent fields. Other values are left for encoding/json as is.
+func toPatchValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !containsPatchField(v.Type(), map[reflect.Type]bool{}) {
+		return v.Interface()
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	if field, ok := v.Interface().(patchField); ok {
+		return toPatchValue(reflect.ValueOf(field.patchValue()))
+	}
+	if isJsonMarshaler(v.Type()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toPatchValue(v.Elem())
+	case reflect.Struct:
+		object := make(map[string]interface{})
+		addPatchFields(object, v)
+		return object
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = toPatchValue(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		object := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			object[fmt.Sprint(iter.Key().Interface())] = toPatchValue(iter.Value())
+		}
+		return object
+	}
+	return v.Interface()
+}
+
+// addPatchFields adds the fields of the struct to the object, following the json tags of the fields
+func addPatchFields(object map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	var embedded []reflect.Value
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		name := options[0]
+		value := v.Field(i)
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				embedded = append(embedded, value)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if value.Kind() == reflect.Ptr && value.IsNil() && value.Type().Implements(patchFieldType) {
+			// a nil *Optional or *Nullable is absent like its zero value
+			continue
+		}
+		if patch, ok := value.Interface().(patchField); ok && !patch.isSet() {
+			continue
+		}
+		if hasJsonOption(options[1:], "omitempty") && isEmptyJsonValue(value) {
+			continue
+		}
+		object[name] = toPatchValue(value)
+	}
+	// fields of the outer struct take precedence over promoted fields
+	for _, value := range embedded {
+		promoted := make(map[string]interface{})
+		addPatchFields(promoted, value)
+		for name, fieldValue := range promoted {
+			if _, exists := object[name]; !exists {
+				object[name] = fieldValue
+			}
+		}
+	}
+}
+
+func hasJsonOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyJsonValue determines if the value is omitted by omitempty, as by encoding/json
+func isEmptyJsonValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// containsPatchField determines if values of the type may hold a patch field, where the values of
+// interfaces are only known at runtime
+func containsPatchField(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t.Implements(patchFieldType) {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		if isJsonMarshaler(t) {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if (field.PkgPath == "" || field.Anonymous) && containsPatchField(field.Type, seen) {
+				return true
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsPatchField(t.Elem(), seen)
+	}
+	return false
+}
+
+type mergePatchCodec struct{}
+
+func (mergePatchCodec) Encode(w io.Writer, content interface{}) error {
+	b, err := MarshalPatch(content)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (mergePatchCodec) Decode(r io.Reader, content interface{}) error {
+	return json.NewDecoder(r).Decode(content)
+}
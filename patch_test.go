@@ -0,0 +1,114 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleNewMergePatchEntity() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println(r.Header.Get("Content-Type"), string(body))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	type ServerPatch struct {
+		Name        restclient.Optional `json:"name"`
+		Description restclient.Nullable `json:"description"`
+		Metadata    restclient.Nullable `json:"metadata"`
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// leaves the name as is, removes the description, and sets the metadata
+	patch := &ServerPatch{
+		Description: restclient.NullValue(),
+		Metadata:    restclient.NullableOf(map[string]string{"role": "web"}),
+	}
+	err := client.Exchange("PATCH", "/servers/123", nil, restclient.NewMergePatchEntity(patch), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// application/merge-patch+json {"description":null,"metadata":{"role":"web"}}
+}
+
+func ExampleMarshalPatch() {
+	type Flavor struct {
+		Ram  restclient.Optional `json:"ram"`
+		Disk restclient.Optional `json:"disk"`
+	}
+	type ServerPatch struct {
+		Name   string   `json:"name,omitempty"`
+		Flavor *Flavor  `json:"flavor,omitempty"`
+		Tags   []string `json:"tags"`
+	}
+
+	body, err := restclient.MarshalPatch(&ServerPatch{
+		Flavor: &Flavor{Ram: restclient.OptionalOf(0)},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(body))
+	// Output:
+	// {"flavor":{"ram":0},"tags":null}
+}
+
+func ExampleNullable_UnmarshalJSON() {
+	var patch struct {
+		Name        restclient.Nullable `json:"name"`
+		Description restclient.Nullable `json:"description"`
+	}
+	err := json.Unmarshal([]byte(`{"description":null}`), &patch)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(patch.Name.IsSet(), patch.Description.IsSet(), patch.Description.IsNull())
+	// Output:
+	// false true true
+}
+
+func ExampleMarshalPatch_pointers() {
+	type ServerPatch struct {
+		Name        *restclient.Optional `json:"name"`
+		Description *restclient.Nullable `json:"description"`
+		Metadata    *restclient.Nullable `json:"metadata"`
+	}
+
+	// nil pointers are absent like the zero values of the fields
+	description := restclient.NullValue()
+	body, err := restclient.MarshalPatch(&ServerPatch{Description: &description})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(body))
+	// Output:
+	// {"description":null}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrPolicyViolation is matched, via errors.Is, by errors of exchanges, and requests of interceptors,
+// that are not permitted by the client's Policy
+var ErrPolicyViolation = errors.New("request denied by policy")
+
+// PolicyRule matches requests by method and path, where the empty fields match any request
+type PolicyRule struct {
+	// Methods are the methods matched, such as GET, or any method when empty
+	Methods []string
+	// Path is a glob pattern of the URL path, such as "/servers/*/actions", where "*" matches
+	// within a segment as with path.Match and "**" matches any number of segments
+	Path string
+	// PathRegexp is matched against the URL path when set
+	PathRegexp *regexp.Regexp
+}
+
+func (r *PolicyRule) matches(method string, urlPath string) bool {
+	if len(r.Methods) > 0 {
+		matched := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, method) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.Path != "" && !globMatch(r.Path, urlPath) {
+		return false
+	}
+	if r.PathRegexp != nil && !r.PathRegexp.MatchString(urlPath) {
+		return false
+	}
+	return true
+}
+
+func (r *PolicyRule) String() string {
+	description := "*"
+	if len(r.Methods) > 0 {
+		description = strings.Join(r.Methods, ",")
+	}
+	if r.Path != "" {
+		description += " " + r.Path
+	}
+	if r.PathRegexp != nil {
+		description += " matching " + r.PathRegexp.String()
+	}
+	return description
+}
+
+// RequestPolicy permits the requests of a client, such as to limit the reach of powerful automation
+// credentials to the operations that a tool is meant to perform
+type RequestPolicy struct {
+	// Allow, when not empty, permits only requests that match one of its rules
+	Allow []PolicyRule
+	// Deny rejects requests that match one of its rules, even when allowed
+	Deny []PolicyRule
+}
+
+// Check determines if the request is permitted, where the error matches ErrPolicyViolation when it is not.
+// The rules are matched against the cleaned URL path. A path containing a ".." segment or an escaped
+// slash is rejected, since servers may resolve it to a path other than the one matched.
+func (p *RequestPolicy) Check(method string, u *url.URL) error {
+	urlPath, err := policyPath(u)
+	if err != nil {
+		return fmt.Errorf("%w: %s %s %s", ErrPolicyViolation, method, u.EscapedPath(), err)
+	}
+	for i := range p.Deny {
+		if p.Deny[i].matches(method, urlPath) {
+			return fmt.Errorf("%w: %s %s matches denied %s", ErrPolicyViolation, method, urlPath, &p.Deny[i])
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for i := range p.Allow {
+		if p.Allow[i].matches(method, urlPath) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s %s is not allowed", ErrPolicyViolation, method, urlPath)
+}
+
+// policyPath returns the cleaned path of the URL, which is unambiguous to the rules
+func policyPath(u *url.URL) (string, error) {
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == ".." {
+			return "", errors.New("has a parent directory segment")
+		}
+	}
+	if strings.Contains(strings.ToUpper(u.EscapedPath()), "%2F") {
+		return "", errors.New("has an escaped slash")
+	}
+	if u.Path == "" {
+		return "", nil
+	}
+	return path.Clean(u.Path), nil
+}
+
+// globMatch matches the slash separated segments of name with those of the pattern
+func globMatch(pattern string, name string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(name, "/"), "/"))
+}
+
+func matchSegments(patterns []string, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for skip := 0; skip <= len(names); skip++ {
+				if matchSegments(patterns[1:], names[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if matched, err := path.Match(patterns[0], names[0]); err != nil || !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+func (c *Client) checkPolicy(method string, u *url.URL) error {
+	if c.Policy == nil {
+		return nil
+	}
+	return c.Policy.Check(method, u)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+)
+
+func ExampleRequestPolicy() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	// the tool only reads servers and reboots them, but never the database servers
+	client.Policy = &restclient.RequestPolicy{
+		Allow: []restclient.PolicyRule{
+			{Methods: []string{"GET"}, Path: "/v2/*/servers/**"},
+			{Methods: []string{"POST"}, Path: "/v2/*/servers/*/action"},
+		},
+		Deny: []restclient.PolicyRule{
+			{PathRegexp: regexp.MustCompile(`/servers/db-`)},
+		},
+	}
+
+	fmt.Println(client.Exchange("GET", "/v2/123456/servers/web-1", nil, nil, nil))
+	fmt.Println(client.Exchange("POST", "/v2/123456/servers/web-1/action", nil, nil, nil))
+	for _, err := range []error{
+		client.Exchange("DELETE", "/v2/123456/servers/web-1", nil, nil, nil),
+		client.Exchange("POST", "/v2/123456/servers/db-1/action", nil, nil, nil),
+	} {
+		fmt.Println(errors.Is(err, restclient.ErrPolicyViolation), err)
+	}
+	// Output:
+	// RECV GET /v2/123456/servers/web-1
+	// <nil>
+	// RECV POST /v2/123456/servers/web-1/action
+	// <nil>
+	// true request denied by policy: DELETE /v2/123456/servers/web-1 is not allowed
+	// true request denied by policy: POST /v2/123456/servers/db-1/action matches denied * matching /servers/db-
+}
+
+func ExampleRequestPolicy_Check() {
+	policy := &restclient.RequestPolicy{
+		Deny: []restclient.PolicyRule{
+			{Methods: []string{"DELETE"}, Path: "/admin/**"},
+		},
+	}
+
+	for _, rawUrl := range []string{
+		"https://example.com/admin/users",
+		"https://example.com/servers/../admin/users",
+		"https://example.com/servers/..%2Fadmin%2Fusers",
+		"https://example.com/admin%2Fusers",
+		"https://example.com//admin/./users",
+		"https://example.com/servers/1",
+	} {
+		u, _ := url.Parse(rawUrl)
+		fmt.Println(policy.Check("DELETE", u))
+	}
+	// Output:
+	// request denied by policy: DELETE /admin/users matches denied DELETE /admin/**
+	// request denied by policy: DELETE /servers/../admin/users has a parent directory segment
+	// request denied by policy: DELETE /servers/..%2Fadmin%2Fusers has a parent directory segment
+	// request denied by policy: DELETE /admin%2Fusers has an escaped slash
+	// request denied by policy: DELETE /admin/users matches denied DELETE /admin/**
+	// <nil>
+}
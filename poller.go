@@ -0,0 +1,213 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PollStatus is the state of a long-running operation as extracted by a Poller's Status function
+type PollStatus int
+
+const (
+	// PollPending indicates the operation is still in progress
+	PollPending PollStatus = iota
+	// PollSucceeded indicates the operation completed successfully
+	PollSucceeded
+	// PollFailed indicates the operation completed unsuccessfully
+	PollFailed
+)
+
+func (s PollStatus) String() string {
+	switch s {
+	case PollPending:
+		return "pending"
+	case PollSucceeded:
+		return "succeeded"
+	case PollFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("PollStatus(%d)", int(s))
+}
+
+var (
+	// ErrOperationFailed is matched, via errors.Is, by the error of Poller.Poll when the operation
+	// reached the PollFailed status
+	ErrOperationFailed = errors.New("operation failed")
+	// ErrPollTimeout is matched, via errors.Is, by the error of Poller.Poll when the operation did not
+	// complete within the poller's Timeout
+	ErrPollTimeout = errors.New("polling timed out")
+)
+
+// PollProgress conveys the state of a Poller to its OnProgress callback after each poll
+type PollProgress struct {
+	// Attempt is the number of the poll, starting from 1
+	Attempt int
+	Status  PollStatus
+	// Elapsed is the time since polling started
+	Elapsed time.Duration
+	// Next is the delay before the next poll, if the operation is still pending
+	Next time.Duration
+}
+
+// Poller polls the status of a long-running operation, such as a server build or DNS job, with
+// backoff until it succeeds, fails, or times out. Zero values are replaced with the defaults noted
+// on each field.
+type Poller struct {
+	Client *Client
+	// StatusUrl is retrieved with GET on each poll and may be relative to the client's BaseUrl
+	StatusUrl string
+	// Content receives the content of each poll, such as from NewJsonEntity(&job)
+	Content *Entity
+	// Status extracts the status of the operation from Content after each poll. Returning an error
+	// stops polling and is returned by Poll.
+	Status func() (PollStatus, error)
+	// Interval is the delay between polls, which defaults to 1s
+	Interval time.Duration
+	// MaxInterval caps the delay between polls, which defaults to 30s
+	MaxInterval time.Duration
+	// Multiplier increases the interval after each poll, which defaults to 1 for a fixed interval
+	Multiplier float64
+	// Timeout, if positive, bounds the overall time spent polling
+	Timeout time.Duration
+	// Clock is used for the delays between polls and the timeout, which defaults to SystemClock
+	Clock Clock
+	// OnProgress, if set, is called after each poll, such as to report progress to the user
+	OnProgress func(progress PollProgress)
+	// Options are applied to each poll's exchange
+	Options []RequestOption
+
+	// initialHeader is that of the response that started the operation, whose Retry-After is the
+	// default interval
+	initialHeader http.Header
+}
+
+// NewPoller creates a Poller for the operation started by an exchange whose response info was
+// captured with WithResponseInfo. The status URL is taken from the response's Location header and
+// a Retry-After header, if present, is used as the polling interval when Interval is not set. Like
+// any interval, it is capped by MaxInterval.
+func (c *Client) NewPoller(initial *ResponseInfo, content *Entity, status func() (PollStatus, error)) (*Poller, error) {
+	if status == nil {
+		return nil, errors.New("status function is required")
+	}
+	location := initial.Header.Get("Location")
+	if location == "" {
+		return nil, fmt.Errorf("response with status %d did not provide a Location to poll", initial.StatusCode)
+	}
+	return &Poller{
+		Client:        c,
+		StatusUrl:     location,
+		Content:       content,
+		Status:        status,
+		initialHeader: initial.Header,
+	}, nil
+}
+
+// Poll retrieves the status URL until the operation reaches PollSucceeded, in which case nil is
+// returned, or PollFailed, in which case an error matching ErrOperationFailed is returned. The first
+// poll happens immediately. Polling stops early when ctx is done or the Timeout elapses.
+func (p *Poller) Poll(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p.Status == nil {
+		return errors.New("poller has no status function")
+	}
+	clock := p.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	interval := p.Interval
+	if interval <= 0 && p.initialHeader != nil {
+		// an HTTP date is relative to the time polling starts
+		if retryAfter, ok := parseRetryAfter(p.initialHeader, clock.Now()); ok {
+			interval = retryAfter
+		}
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := p.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 30 * time.Second
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	var timeout <-chan time.Time
+	if p.Timeout > 0 {
+		var stopTimeout func()
+		timeout, stopTimeout = startTimer(clock, p.Timeout)
+		defer stopTimeout()
+	}
+
+	start := clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := p.Client.ExchangeWithContext(ctx, "GET", p.StatusUrl, nil, nil, p.Content, p.Options...)
+		if err != nil {
+			return err
+		}
+		status, err := p.Status()
+		if err != nil {
+			return err
+		}
+
+		progress := PollProgress{
+			Attempt: attempt,
+			Status:  status,
+			Elapsed: clock.Now().Sub(start),
+		}
+		if status == PollPending {
+			progress.Next = interval
+		}
+		if p.OnProgress != nil {
+			p.OnProgress(progress)
+		}
+
+		switch status {
+		case PollSucceeded:
+			return nil
+		case PollFailed:
+			return fmt.Errorf("%w: %s", ErrOperationFailed, p.StatusUrl)
+		}
+
+		wait, stopWait := startTimer(clock, interval)
+		select {
+		case <-wait:
+		case <-timeout:
+			stopWait()
+			return fmt.Errorf("%w after %s: %s", ErrPollTimeout, p.Timeout, p.StatusUrl)
+		case <-ctx.Done():
+			stopWait()
+			return ctx.Err()
+		}
+
+		interval = time.Duration(float64(interval) * multiplier)
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExamplePoller() {
+	// Setup a test HTTP server where a DNS job completes after a few polls
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "/status/job1")
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusAccepted)
+		case "GET":
+			polls++
+			status := "RUNNING"
+			if polls >= 3 {
+				status = "COMPLETED"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"jobId": "job1", "status": %q}`, status)
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, 2*time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var info restclient.ResponseInfo
+	err := client.Exchange("POST", "/domains", nil,
+		restclient.NewJsonEntity(map[string]string{"name": "example.com"}), nil,
+		restclient.WithResponseInfo(&info))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var job struct {
+		JobId  string
+		Status string
+	}
+	poller, err := client.NewPoller(&info, restclient.NewJsonEntity(&job), func() (restclient.PollStatus, error) {
+		switch job.Status {
+		case "COMPLETED":
+			return restclient.PollSucceeded, nil
+		case "ERROR":
+			return restclient.PollFailed, nil
+		}
+		return restclient.PollPending, nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	poller.Clock = clock
+	poller.OnProgress = func(progress restclient.PollProgress) {
+		fmt.Println("poll", progress.Attempt, progress.Status, progress.Next)
+	}
+
+	err = poller.Poll(context.Background())
+	fmt.Println(err, job.Status)
+	// Output:
+	// poll 1 pending 2s
+	// poll 2 pending 2s
+	// poll 3 succeeded 0s
+	// <nil> COMPLETED
+}
+
+func ExamplePoller_timeout() {
+	// Setup a test HTTP server where the job never completes
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": "RUNNING"}`))
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Now())
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, 10*time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var job struct {
+		Status string
+	}
+	poller := &restclient.Poller{
+		Client:    client,
+		StatusUrl: "/status/job1",
+		Content:   restclient.NewJsonEntity(&job),
+		Status: func() (restclient.PollStatus, error) {
+			if job.Status == "COMPLETED" {
+				return restclient.PollSucceeded, nil
+			}
+			return restclient.PollPending, nil
+		},
+		Interval:   10 * time.Second,
+		Multiplier: 2,
+		Timeout:    time.Minute,
+		Clock:      clock,
+	}
+
+	err := poller.Poll(context.Background())
+	fmt.Println(errors.Is(err, restclient.ErrPollTimeout))
+	// Output:
+	// true
+}
+
+// advanceWhenWaiting advances the clock by d whenever code under test is waiting on it
+func advanceWhenWaiting(clock *restclienttest.FakeClock, d time.Duration, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Millisecond):
+			if clock.Waiters() > 0 {
+				clock.Advance(d)
+			}
+		}
+	}
+}
+
+func ExampleClient_NewPoller_retryAfterDate() {
+	// Setup a test HTTP server whose Retry-After is a date
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "/status/job1")
+			w.Header().Set("Retry-After", "Wed, 01 Jan 2020 00:00:05 GMT")
+			w.WriteHeader(http.StatusAccepted)
+		case "GET":
+			polls++
+			w.Header().Set("Content-Type", "application/json")
+			if polls >= 2 {
+				_, _ = w.Write([]byte(`{"status": "COMPLETED"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"status": "RUNNING"}`))
+			}
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, 5*time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var info restclient.ResponseInfo
+	err := client.Exchange("POST", "/domains", nil, nil, nil, restclient.WithResponseInfo(&info))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = client.NewPoller(&info, nil, nil)
+	fmt.Println(err)
+
+	var job struct {
+		Status string
+	}
+	poller, err := client.NewPoller(&info, restclient.NewJsonEntity(&job), func() (restclient.PollStatus, error) {
+		if job.Status == "COMPLETED" {
+			return restclient.PollSucceeded, nil
+		}
+		return restclient.PollPending, nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the date is relative to the poller's clock
+	poller.Clock = clock
+	poller.OnProgress = func(progress restclient.PollProgress) {
+		fmt.Println("poll", progress.Attempt, progress.Status, progress.Next)
+	}
+
+	fmt.Println(poller.Poll(context.Background()))
+	// Output:
+	// status function is required
+	// poll 1 pending 5s
+	// poll 2 succeeded 0s
+	// <nil>
+}
+
+func ExampleClient_NewPoller_retryAfterCapped() {
+	// Setup a test HTTP server whose Retry-After is an hour
+	polls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "/status/job1")
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusAccepted)
+		case "GET":
+			polls++
+			w.Header().Set("Content-Type", "application/json")
+			if polls >= 2 {
+				_, _ = w.Write([]byte(`{"status": "COMPLETED"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"status": "RUNNING"}`))
+			}
+		}
+	}))
+	defer ts.Close()
+	clock := restclienttest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	stop := make(chan struct{})
+	defer close(stop)
+	go advanceWhenWaiting(clock, 10*time.Second, stop)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var info restclient.ResponseInfo
+	err := client.Exchange("POST", "/domains", nil, nil, nil, restclient.WithResponseInfo(&info))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var job struct {
+		Status string
+	}
+	poller, err := client.NewPoller(&info, restclient.NewJsonEntity(&job), func() (restclient.PollStatus, error) {
+		if job.Status == "COMPLETED" {
+			return restclient.PollSucceeded, nil
+		}
+		return restclient.PollPending, nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	poller.Clock = clock
+	poller.MaxInterval = 10 * time.Second
+	poller.OnProgress = func(progress restclient.PollProgress) {
+		fmt.Println("poll", progress.Attempt, progress.Status, progress.Next)
+	}
+
+	fmt.Println(poller.Poll(context.Background()))
+	// Output:
+	// poll 1 pending 10s
+	// poll 2 succeeded 0s
+	// <nil>
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize avoids retaining the buffers of unusually large bodies in the pool
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
+
+// copyBytes returns an exactly sized copy of the buffer's content, so that the buffer can be
+// returned to the pool
+func copyBytes(buffer *bytes.Buffer) []byte {
+	b := make([]byte, buffer.Len())
+	copy(b, buffer.Bytes())
+	return b
+}
+
+// readAll reads r until EOF using a pooled buffer and returns an exactly sized copy of the content,
+// which is the content read so far when an error is returned
+func readAll(r io.Reader) ([]byte, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+	_, err := buffer.ReadFrom(r)
+	return copyBytes(buffer), err
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Preconnect establishes a connection, including DNS resolution, TCP connect, and TLS handshake, to
+// the client's BaseUrl or, when given, each of the urls ahead of time. The connections are retained
+// by the transport of the client's HttpClient, so that the first real exchange doesn't pay the latency
+// of a cold start.
+//
+// A connection is established by a HEAD request that bypasses the interceptors, since its
+// response is ignored. Only failures to connect are returned.
+func (c *Client) Preconnect(ctx context.Context, urls ...string) error {
+	if len(urls) == 0 {
+		if c.BaseUrl == nil {
+			return errors.New("preconnect requires a BaseUrl or urls")
+		}
+		urls = []string{c.BaseUrl.String()}
+	}
+
+	for _, urlIn := range urls {
+		reqUrl, err := c.buildReqUrl(urlIn, nil)
+		if err != nil {
+			return err
+		}
+		if err := c.checkUrlAllowed(reqUrl); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, "HEAD", reqUrl.String(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to setup preconnect request: %w", err)
+		}
+		req.Header.Set(headerUserAgent, c.userAgent())
+
+		resp, err := withoutRedirects(c.httpClient()).Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to preconnect to %s: %w", DefaultRedactionPolicy.RedactUrl(reqUrl), err)
+		}
+		// the connection is only retained once the response is consumed
+		discardBody(resp)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"encoding/json"
+	"mime"
+)
+
+// ProblemType is the content type of RFC 7807 problem details
+const ProblemType MimeType = "application/problem+json"
+
+// ProblemDetails conveys the RFC 7807 problem details of a failed response. It is populated as the
+// Problem of a FailedResponseError when the response's content type is ProblemType.
+type ProblemDetails struct {
+	// Type is a URI reference identifying the problem type, which is "about:blank" when absent
+	Type string `json:"type"`
+	// Title is a short, human-readable summary of the problem type
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code given by the origin server
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference identifying the specific occurrence of the problem
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds the remaining members, which are specific to the problem type
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type standardMembers ProblemDetails
+	if err := json.Unmarshal(data, (*standardMembers)(p)); err != nil {
+		return err
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, name := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, name)
+	}
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// parseProblemDetails decodes the body when the content type is ProblemType, otherwise returns nil
+func parseProblemDetails(contentType string, body []byte) *ProblemDetails {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || MimeType(mediaType) != ProblemType {
+		return nil
+	}
+	var problem ProblemDetails
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return nil
+	}
+	return &problem
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package protocodec enables protobuf encoded entities with the restclient package.
+
+Importing this package registers a codec for ProtobufType, so that an entity with that content type
+and content implementing proto.Message is marshaled into the request body or unmarshaled from the
+response body. This enables use against gRPC-gateway and protobuf-over-REST services.
+
+The content type is not detected from the content, so protobuf entities must be created with
+NewEntity, or otherwise given ProtobufType, such as
+
+	err := client.Exchange("POST", "/ping", nil,
+		protocodec.NewEntity(&pb.PingRequest{}), protocodec.NewEntity(&resp))
+
+A proto.Message given to restclient.NewJsonEntity is encoded with encoding/json instead.
+*/
+package protocodec
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"io/ioutil"
+)
+
+// ProtobufType is the content type of protobuf encoded entities
+const ProtobufType restclient.MimeType = "application/x-protobuf"
+
+func init() {
+	restclient.RegisterCodec(ProtobufType, Codec{})
+}
+
+// Codec is a restclient.Codec that marshals and unmarshals content implementing proto.Message
+type Codec struct{}
+
+func (Codec) Encode(w io.Writer, content interface{}) error {
+	msg, ok := content.(proto.Message)
+	if !ok {
+		return fmt.Errorf("content of type %T is not a proto.Message", content)
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (Codec) Decode(r io.Reader, content interface{}) error {
+	msg, ok := content.(proto.Message)
+	if !ok {
+		return fmt.Errorf("content of type %T is not a proto.Message", content)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, msg)
+}
+
+// NewEntity creates an entity of ProtobufType with the given message as content.
+// When used for a response, msg should be a pointer to the message to unmarshal into.
+func NewEntity(msg proto.Message) *restclient.Entity {
+	return &restclient.Entity{
+		ContentType: ProtobufType,
+		Content:     msg,
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protocodec_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/protocodec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleNewEntity() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var msg wrapperspb.StringValue
+		_ = proto.Unmarshal(body, &msg)
+		fmt.Printf("RECV %s %s\n", r.Header.Get("Content-Type"), msg.Value)
+
+		respBody, _ := proto.Marshal(wrapperspb.String("greetings"))
+		w.Header().Set("Content-Type", string(protocodec.ProtobufType))
+		_, _ = w.Write(respBody)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resp wrapperspb.StringValue
+	err := client.Exchange("POST", "/ping", nil,
+		protocodec.NewEntity(wrapperspb.String("hello")), protocodec.NewEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp.Value)
+	// Output:
+	// RECV application/x-protobuf hello
+	// greetings
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitInfo conveys the rate limit status reported by a server
+type RateLimitInfo struct {
+	// Limit is the number of requests allowed in the current window or -1 if not reported
+	Limit int
+	// Remaining is the number of requests remaining in the current window or -1 if not reported
+	Remaining int
+	// Reset is the time at which the current window resets or the zero time if not reported
+	Reset time.Time
+}
+
+// resets larger than this are considered to be epoch seconds rather than delta seconds
+const epochResetThreshold = 1000000000
+
+// ParseRateLimit extracts the rate limit status from the common X-RateLimit-Limit,
+// X-RateLimit-Remaining, and X-RateLimit-Reset headers or the RateLimit-Limit, RateLimit-Remaining,
+// and RateLimit-Reset headers of the IETF draft. The reset can be given as either seconds since the
+// epoch or seconds from now. Returns nil if none of the headers are present.
+func ParseRateLimit(header http.Header) *RateLimitInfo {
+	limit, hasLimit := rateLimitHeader(header, "Limit")
+	remaining, hasRemaining := rateLimitHeader(header, "Remaining")
+	reset, hasReset := rateLimitHeader(header, "Reset")
+	if !hasLimit && !hasRemaining && !hasReset {
+		return nil
+	}
+
+	info := &RateLimitInfo{
+		Limit:     -1,
+		Remaining: -1,
+	}
+	if hasLimit {
+		info.Limit = int(limit)
+	}
+	if hasRemaining {
+		info.Remaining = int(remaining)
+	}
+	if hasReset {
+		if reset > epochResetThreshold {
+			info.Reset = time.Unix(reset, 0)
+		} else {
+			info.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return info
+}
+
+func rateLimitHeader(header http.Header, name string) (int64, bool) {
+	value := header.Get("X-RateLimit-" + name)
+	if value == "" {
+		value = header.Get("RateLimit-" + name)
+	}
+	if value == "" {
+		return 0, false
+	}
+	// the IETF draft allows for a quota policy after the value, such as "100, 100;w=60"
+	for i, c := range value {
+		if c < '0' || c > '9' {
+			value = value[:i]
+			break
+		}
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return parsed, true
+}
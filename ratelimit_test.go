@@ -0,0 +1,51 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleParseRateLimit() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1577836800")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	err := client.Exchange("GET", "/servers", nil, nil, nil)
+
+	var failedResponse *restclient.FailedResponseError
+	if errors.As(err, &failedResponse) && failedResponse.RateLimit != nil {
+		rateLimit := failedResponse.RateLimit
+		fmt.Printf("%d of %d remaining until %s\n",
+			rateLimit.Remaining, rateLimit.Limit, rateLimit.Reset.UTC())
+	}
+	// Output:
+	// 0 of 100 remaining until 2020-01-01 00:00:00 +0000 UTC
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrMutationBlocked is matched, via errors.Is, by errors of exchanges whose method is other than
+// GET and HEAD while the client is ReadOnly
+var ErrMutationBlocked = errors.New("mutation blocked by read-only mode")
+
+func (c *Client) checkMutationAllowed(method string, u *url.URL) error {
+	if c.ReadOnly && method != "GET" && method != "HEAD" {
+		return fmt.Errorf("%w: %s %s", ErrMutationBlocked, method, DefaultRedactionPolicy.RedactUrl(u))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redactedValue = "REDACTED"
+
+// RedactionPolicy determines which sensitive values are masked when exchanges are recorded,
+// such as by HarRecorder
+type RedactionPolicy struct {
+	// Headers are the case-insensitive names of headers whose values are masked
+	Headers []string
+	// QueryParams are the names of query parameters whose values are masked
+	QueryParams []string
+	// JsonFields are the case-insensitive names of JSON object fields whose values are masked,
+	// at any depth, in JSON bodies
+	JsonFields []string
+}
+
+// DefaultRedactionPolicy masks the common authentication and session headers along with the
+// common API key query parameters
+var DefaultRedactionPolicy = RedactionPolicy{
+	Headers: []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"X-Auth-Token",
+		"X-Subject-Token",
+		"X-Api-Key",
+		"Cookie",
+		"Set-Cookie",
+	},
+	QueryParams: []string{
+		"api_key",
+		"apikey",
+	},
+	JsonFields: []string{
+		"password",
+		"apiKey",
+		"api_key",
+		"secret",
+		"client_secret",
+		"access_token",
+		"refresh_token",
+	},
+}
+
+// RedactHeader returns a copy of the header with the values of sensitive headers masked
+func (p RedactionPolicy) RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted == nil {
+		return nil
+	}
+	for _, name := range p.Headers {
+		key := http.CanonicalHeaderKey(name)
+		if values := redacted[key]; len(values) > 0 {
+			masked := make([]string, len(values))
+			for i := range values {
+				masked[i] = redactedValue
+			}
+			redacted[key] = masked
+		}
+	}
+	return redacted
+}
+
+// RedactUrl returns a copy of the URL with the values of sensitive query parameters and any
+// user password masked
+func (p RedactionPolicy) RedactUrl(u *url.URL) *url.URL {
+	redacted := *u
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			redacted.User = url.UserPassword(u.User.Username(), redactedValue)
+		}
+	}
+	if len(p.QueryParams) > 0 && u.RawQuery != "" {
+		query := u.Query()
+		changed := false
+		for name := range query {
+			for _, sensitive := range p.QueryParams {
+				if strings.EqualFold(name, sensitive) {
+					query.Set(name, redactedValue)
+					changed = true
+				}
+			}
+		}
+		if changed {
+			redacted.RawQuery = query.Encode()
+		}
+	}
+	return &redacted
+}
+
+// RedactJson returns the JSON body with the values of sensitive fields masked. The body is
+// returned as is when it contains none of the fields or is not valid JSON.
+func (p RedactionPolicy) RedactJson(body []byte) []byte {
+	if len(p.JsonFields) == 0 {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// retains the precision of large numbers
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	if !p.redactJsonValue(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactJsonValue masks sensitive fields within the decoded value and reports if any were masked
+func (p RedactionPolicy) redactJsonValue(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if p.isSensitiveField(name) {
+				v[name] = redactedValue
+				changed = true
+			} else if p.redactJsonValue(field) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if p.redactJsonValue(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func (p RedactionPolicy) isSensitiveField(name string) bool {
+	for _, sensitive := range p.JsonFields {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
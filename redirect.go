@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RedirectionResponse is the error of an exchange whose response was a redirection, with a 3xx status
+// and Location, that was not followed, such as when the client's DisableRedirects is set. The
+// caller can then follow the Location with its own policy, such as whether to authenticate with
+// another host. It wraps the FailedResponseError of the response.
+type RedirectionResponse struct {
+	StatusCode int
+	Status     string
+	// Location is the target of the redirection resolved against the URL of the request
+	Location *url.URL
+	Header   http.Header
+
+	failed *FailedResponseError
+}
+
+func (r *RedirectionResponse) Error() string {
+	return fmt.Sprintf("%s redirected to %s", r.Status, DefaultRedactionPolicy.RedactUrl(r.Location))
+}
+
+func (r *RedirectionResponse) Unwrap() error {
+	return r.failed
+}
+
+// newRedirectionResponse returns a RedirectionResponse, if the failed response is a redirection
+func newRedirectionResponse(resp *http.Response, failed *FailedResponseError) *RedirectionResponse {
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return nil
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil
+	}
+	target, err := url.Parse(location)
+	if err != nil {
+		return nil
+	}
+	if resp.Request != nil {
+		target = resp.Request.URL.ResolveReference(target)
+	}
+	return &RedirectionResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Location:   target,
+		Header:     resp.Header,
+		failed:     failed,
+	}
+}
+
+// withoutRedirects returns a copy of httpClient that returns redirection responses as is
+func withoutRedirects(httpClient *http.Client) *http.Client {
+	noRedirects := *httpClient
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &noRedirects
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleRedirectionResponse() {
+	// Setup a test HTTP server that redirects downloads to another host
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://cdn.example.com/images/1?api_key=abc", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.DisableRedirects = true
+
+	err := client.Exchange("GET", "/images/1", nil, nil, nil)
+	var redirection *restclient.RedirectionResponse
+	if errors.As(err, &redirection) {
+		// the caller decides how to follow, such as without the client's authentication
+		fmt.Println(redirection.StatusCode, redirection.Location.Host)
+	}
+	fmt.Println(err)
+	// Output:
+	// 302 cdn.example.com
+	// 302 Found redirected to https://cdn.example.com/images/1?api_key=REDACTED
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrRegionNotFound is matched, via errors.Is, when a RegionalClients has no client for a region
+var ErrRegionNotFound = errors.New("region not found")
+
+// CatalogService is a service listed in the service catalog of an Identity token
+type CatalogService struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Endpoints []CatalogEndpoint `json:"endpoints"`
+}
+
+// CatalogEndpoint is an endpoint of a CatalogService, where the Region is empty for global services
+type CatalogEndpoint struct {
+	Region      string `json:"region,omitempty"`
+	TenantId    string `json:"tenantId,omitempty"`
+	PublicUrl   string `json:"publicURL"`
+	InternalUrl string `json:"internalURL,omitempty"`
+}
+
+type regionalOptions struct {
+	internal bool
+	setup    func(region string, client *Client)
+}
+
+// RegionalOption customizes the clients created by NewRegionalClients
+type RegionalOption func(o *regionalOptions)
+
+// WithInternalUrls uses the internal URLs of the endpoints, such as for ServiceNet, rather than the
+// public URLs
+func WithInternalUrls() RegionalOption {
+	return func(o *regionalOptions) {
+		o.internal = true
+	}
+}
+
+// WithClientSetup calls setup with each client after it is created, such as to set its Timeout or
+// add interceptors after the authenticator
+func WithClientSetup(setup func(region string, client *Client)) RegionalOption {
+	return func(o *regionalOptions) {
+		o.setup = setup
+	}
+}
+
+// RegionalClients holds a Client for each region of a service, such as DFW, ORD, and LON, which
+// simplifies fanning out operations across regions
+type RegionalClients struct {
+	clients map[string]*Client
+}
+
+// RegionError is the error of ForEachRegion, which holds the error of each region that failed
+type RegionError map[string]error
+
+func (e RegionError) Error() string {
+	var regions []string
+	for region := range e {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	var sb strings.Builder
+	for i, region := range regions {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		fmt.Fprintf(&sb, "%s: %s", region, e[region])
+	}
+	return sb.String()
+}
+
+// NewRegionalClients creates a Client for each region of the service type, such as
+// "compute", in the service catalog of the authenticator's token. Each client is authenticated by
+// the authenticator and its BaseUrl is the endpoint of the region, ending with a slash so that
+// request URLs relative to the endpoint, such as "servers", are resolved within it. When the catalog
+// lists more than one endpoint of the service type in a region, the first is used.
+func NewRegionalClients(ctx context.Context, auth *IdentityV2Auth, serviceType string,
+	opts ...RegionalOption) (*RegionalClients, error) {
+
+	var options regionalOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	catalog, err := auth.ServiceCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]*Client)
+	for _, service := range catalog {
+		if service.Type != serviceType {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			endpointUrl := endpoint.PublicUrl
+			if options.internal {
+				endpointUrl = endpoint.InternalUrl
+			}
+			region := strings.ToUpper(endpoint.Region)
+			if _, exists := clients[region]; exists || endpointUrl == "" {
+				continue
+			}
+			if !strings.HasSuffix(endpointUrl, "/") {
+				endpointUrl += "/"
+			}
+
+			client := NewClient()
+			if err := client.SetBaseUrl(endpointUrl); err != nil {
+				return nil, fmt.Errorf("invalid endpoint of %s in %s: %w", serviceType, endpoint.Region, err)
+			}
+			client.AddInterceptor(auth.Intercept)
+			if options.setup != nil {
+				options.setup(endpoint.Region, client)
+			}
+			clients[region] = client
+		}
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("service catalog has no endpoints of %s", serviceType)
+	}
+	return &RegionalClients{clients: clients}, nil
+}
+
+// Regions returns the regions, in sorted order
+func (r *RegionalClients) Regions() []string {
+	regions := make([]string, 0, len(r.clients))
+	for region := range r.clients {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// ForRegion returns the client of the region, which is matched case-insensitively
+func (r *RegionalClients) ForRegion(region string) (*Client, error) {
+	client, ok := r.clients[strings.ToUpper(region)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRegionNotFound, region)
+	}
+	return client, nil
+}
+
+// ForEachRegion calls fn concurrently with the client of each region and waits for them to
+// complete. When any fail, the returned error is a RegionError.
+func (r *RegionalClients) ForEachRegion(fn func(region string, client *Client) error) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := make(RegionError)
+	for region, client := range r.clients {
+		wg.Add(1)
+		go func(region string, client *Client) {
+			defer wg.Done()
+			if err := fn(region, client); err != nil {
+				mu.Lock()
+				failed[region] = err
+				mu.Unlock()
+			}
+		}(region, client)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+)
+
+func ExampleNewRegionalClients() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key", TenantId: "123456"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"servers": [{"name": "web"}]}`))
+	})))
+	defer ts.Close()
+	identity.ServiceCatalog = []restclient.CatalogService{
+		{Name: "cloudServersOpenStack", Type: "compute", Endpoints: []restclient.CatalogEndpoint{
+			{Region: "DFW", TenantId: "123456", PublicUrl: ts.URL + "/dfw/v2/123456"},
+			{Region: "ORD", TenantId: "123456", PublicUrl: ts.URL + "/ord/v2/123456"},
+			{Region: "LON", TenantId: "123456", PublicUrl: ts.URL + "/lon/v2/123456"},
+		}},
+	}
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "user1", "", "key")
+	if err != nil {
+		log.Fatal(err)
+	}
+	compute, err := restclient.NewRegionalClients(context.Background(), auth, "compute")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(compute.Regions())
+
+	var mu sync.Mutex
+	var names []string
+	err = compute.ForEachRegion(func(region string, client *restclient.Client) error {
+		var resp struct {
+			Servers []struct {
+				Name string
+			}
+		}
+		if err := client.Exchange("GET", "servers", nil, nil, restclient.NewJsonEntity(&resp)); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, server := range resp.Servers {
+			names = append(names, region+"/"+server.Name)
+		}
+		return nil
+	})
+	sort.Strings(names)
+	fmt.Println(names, err)
+
+	_, err = compute.ForRegion("syd")
+	fmt.Println(err)
+	// Output:
+	// [DFW LON ORD]
+	// [DFW/web LON/web ORD/web] <nil>
+	// region not found: syd
+}
+
+func ExampleNewRegionalClients_duplicateRegion() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key", TenantId: "123456"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path)
+	})))
+	defer ts.Close()
+	// A second compute service lists DFW again, in a different case
+	identity.ServiceCatalog = []restclient.CatalogService{
+		{Name: "cloudServersOpenStack", Type: "compute", Endpoints: []restclient.CatalogEndpoint{
+			{Region: "DFW", TenantId: "123456", PublicUrl: ts.URL + "/dfw/v2/123456"},
+		}},
+		{Name: "cloudServersLegacy", Type: "compute", Endpoints: []restclient.CatalogEndpoint{
+			{Region: "dfw", TenantId: "123456", PublicUrl: ts.URL + "/legacy/v1/123456"},
+		}},
+	}
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "user1", "", "key")
+	if err != nil {
+		log.Fatal(err)
+	}
+	compute, err := restclient.NewRegionalClients(context.Background(), auth, "compute")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(compute.Regions())
+
+	client, err := compute.ForRegion("DFW")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(client.Exchange("GET", "servers", nil, nil, nil))
+	// Output:
+	// [DFW]
+	// RECV /dfw/v2/123456/servers
+	// <nil>
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// ExchangeSpec is a serializable specification of the request of an exchange, such as a failed
+// mutation that is persisted as JSON and replayed later by a background worker. It is captured
+// prior to the client's interceptors, so credentials are not retained and are instead applied when
+// the exchange is replayed.
+type ExchangeSpec struct {
+	Method string `json:"method"`
+	// Url is the absolute URL of the request, including its query
+	Url string `json:"url"`
+	// Header holds the headers set by the request options, such as WithHeader
+	Header      http.Header `json:"header,omitempty"`
+	ContentType MimeType    `json:"contentType,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// CaptureExchange specifies the exchange described by the arguments, which are as described for
+// ExchangeWithContext, without sending it. The request content is fully read, so a ContentWriter or
+// io.Reader content is consumed.
+func (c *Client) CaptureExchange(ctx context.Context, method string, urlIn string, query url.Values,
+	reqIn *Entity, opts ...RequestOption) (*ExchangeSpec, error) {
+
+	req, err := c.BuildRequest(ctx, method, urlIn, query, reqIn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &ExchangeSpec{
+		Method:      req.Method,
+		Url:         req.URL.String(),
+		ContentType: MimeType(req.Header.Get(headerContentType)),
+	}
+	if req.Body != nil {
+		spec.Body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request content: %w", err)
+		}
+	}
+
+	// headers set by the client are set again by the replay
+	req.Header.Del(headerContentType)
+	req.Header.Del(headerUserAgent)
+	if len(req.Header) > 0 {
+		spec.Header = req.Header
+	}
+	return spec, nil
+}
+
+// Replay performs the exchange specified by spec, including the client's interceptors. The
+// response and options are as described for ExchangeWithContext, where the options may add to
+// the headers of the spec.
+func (c *Client) Replay(ctx context.Context, spec *ExchangeSpec, respOut *Entity, opts ...RequestOption) error {
+	var reqIn *Entity
+	if spec.Body != nil || spec.ContentType != "" {
+		reqIn = &Entity{ContentType: spec.ContentType, Content: spec.Body}
+		if spec.Body == nil {
+			reqIn.Content = []byte{}
+		}
+	}
+
+	// the spec's headers precede the options, so the options take precedence
+	replayOpts := make([]RequestOption, 0, len(opts)+1)
+	if len(spec.Header) > 0 {
+		replayOpts = append(replayOpts, WithHeaders(spec.Header))
+	}
+	replayOpts = append(replayOpts, opts...)
+
+	return c.ExchangeWithContext(ctx, spec.Method, spec.Url, nil, reqIn, respOut, replayOpts...)
+}
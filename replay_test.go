@@ -0,0 +1,74 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func ExampleClient_Replay() {
+	// Setup a test HTTP server that is unavailable for the first request
+	available := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println("RECV", r.Method, r.URL, r.Header.Get("X-Auth-Token"), r.Header.Get("X-Request-Id"),
+			r.Header.Get("Content-Type"), strings.TrimSpace(string(body)))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		req.Header.Set("X-Auth-Token", "token1")
+		return next(req)
+	})
+
+	reqIn := restclient.NewJsonEntity(map[string]string{"name": "web"})
+	opts := []restclient.RequestOption{restclient.WithHeader("X-Request-Id", "req1")}
+	err := client.Exchange("POST", "/servers", nil, reqIn, nil, opts...)
+	if err != nil {
+		// persist the failed mutation to retry later
+		spec, err := client.CaptureExchange(context.Background(), "POST", "/servers", nil, reqIn, opts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		stored, _ := json.Marshal(spec)
+
+		// ...later, a background worker replays it
+		available = true
+		var replayed restclient.ExchangeSpec
+		_ = json.Unmarshal(stored, &replayed)
+		err = client.Replay(context.Background(), &replayed, nil)
+		fmt.Println(err)
+	}
+	// Output:
+	// RECV POST /servers token1 req1 application/json {"name":"web"}
+	// <nil>
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+func ExampleReadRequestBody() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %s\n", r.ContentLength, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("file content")
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		content, err := restclient.ReadRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("READ %s\n", string(content))
+		return next(req)
+	})
+
+	file.Seek(0, io.SeekStart)
+	err = client.Exchange("PUT", "/objects/file", nil,
+		restclient.NewReaderEntity(file, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a seeker that isn't an io.ReaderAt shares its position with the body being sent
+	file.Seek(0, io.SeekStart)
+	err = client.Exchange("PUT", "/objects/seeker", nil,
+		restclient.NewReaderEntity(struct{ io.ReadSeeker }{file}, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// READ file content
+	// RECV 12 file content
+	// READ file content
+	// RECV 12 file content
+}
@@ -18,19 +18,31 @@ package restclient
 
 import (
 	"bytes"
-	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sync"
 	"time"
 )
 
 const (
-	defaultRestClientTimeout = 60 * time.Second
-	errorMessageLimit        = 1000
+	// DefaultTimeout is the timeout of exchanges when the Timeout of a Client is zero
+	DefaultTimeout = 60 * time.Second
+	// NoTimeout, as the Timeout of a Client, disables the timeout of exchanges, which are then only
+	// bounded by their context and any of the fine-grained timeouts, such as BodyReadTimeout
+	NoTimeout time.Duration = -1
+)
+
+const (
+	errorMessageLimit       = 1000
+	defaultMaxErrorBodySize = 64 * 1024
+	// maxTrailerDrainSize limits the remainder of a body that is read to obtain its trailers
+	maxTrailerDrainSize = 64 * 1024
 )
 
 // Client provides a high-order type wrapping Go's http.Request by incorporating
@@ -40,9 +52,88 @@ const (
 // JSON response decoding,
 // and non-2xx response status handling
 type Client struct {
-	BaseUrl      *url.URL
-	Timeout      time.Duration
-	interceptors *list.List
+	// stats is first, which ensures the alignment of its 64-bit atomic counters
+	stats clientStats
+
+	BaseUrl *url.URL
+	// Timeout bounds each exchange, including the reading of the response body. Zero uses the
+	// DefaultTimeout and NoTimeout, or any negative duration, disables it. See EffectiveTimeout.
+	Timeout time.Duration
+	// DialTimeout, TLSHandshakeTimeout, and ResponseHeaderTimeout, when positive, bound the phases of
+	// sending a request: establishing a connection, the TLS handshake, and waiting for the response
+	// headers once the request has been written. They are applied to a copy of the transport of
+	// HttpClient, which must be an *http.Transport, or of http.DefaultTransport.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	// BodyReadTimeout, when positive, fails the reading of a response body with ErrBodyReadTimeout
+	// when no bytes arrive within it. Unlike Timeout, it allows long downloads that keep progressing.
+	BodyReadTimeout time.Duration
+	// DnsCache, when set, resolves the hosts of new connections. Like the fine-grained timeouts, it
+	// is applied to a copy of the transport of HttpClient or of http.DefaultTransport.
+	DnsCache *DnsCache
+	// HttpClient is used to send requests. When nil, http.DefaultClient is used.
+	HttpClient *http.Client
+	// MaxConcurrentRequests, when positive, limits the number of exchanges in flight at once.
+	// Exchanges beyond the limit wait for their turn, in order of priority given by WithPriority.
+	MaxConcurrentRequests int
+	// FailWhenBusy causes exchanges beyond MaxConcurrentRequests to immediately fail with
+	// ErrTooManyRequests rather than waiting.
+	FailWhenBusy bool
+	// AllowedHosts, when not empty, restricts exchanges, including any redirects, to URLs with
+	// one of these hosts. An entry may include a port, such as "example.com:8443", and an entry
+	// starting with "*." matches any subdomain. Exchanges for other hosts fail with ErrUrlNotAllowed.
+	AllowedHosts []string
+	// RequireHTTPS causes exchanges, including any redirects, for URLs that are not https to fail
+	// with ErrUrlNotAllowed.
+	RequireHTTPS bool
+	// ReadOnly causes exchanges, and requests of interceptors, with methods other than GET and HEAD
+	// to fail with ErrMutationBlocked, such as to safely run tooling against production APIs
+	ReadOnly bool
+	// Policy, when set, permits the exchanges, and requests of interceptors, by method and path.
+	// Requests that are not permitted fail with ErrPolicyViolation.
+	Policy *RequestPolicy
+	// MaxErrorBodySize limits how much of the body of a non-2xx response is captured by
+	// FailedResponseError, which defaults to 64KiB. The remainder is discarded.
+	MaxErrorBodySize int64
+	// UserAgent is the product token of the application, such as "mytool/1.0", that precedes
+	// DefaultUserAgent in the User-Agent header of requests
+	UserAgent string
+	// BaseUrlParams provides the values of placeholders, such as TenantIdPlaceholder, in the path of
+	// the BaseUrl, where the key is the name within the braces. Placeholders without a value are
+	// left for interceptors, such as IdentityV2Auth, to fill in.
+	BaseUrlParams map[string]string
+	// DisableRedirects causes redirection responses to be returned as a RedirectionResponse rather
+	// than followed
+	DisableRedirects bool
+	// IsSuccess determines which responses are successful, which defaults to DefaultSuccess. It can
+	// be overridden per exchange by WithSuccess.
+	IsSuccess SuccessPredicate
+	// ClassifyError, when set, is given each FailedResponseError before it is returned, so that
+	// applications can centrally convert failed responses into domain errors, such as for an exceeded
+	// quota or maintenance mode. A nil return retains the FailedResponseError. The returned error
+	// should wrap the FailedResponseError, so that it can still be matched with errors.As.
+	ClassifyError func(failed *FailedResponseError) error
+	// TimeCodec, when set, encodes and decodes the time.Time values of JSON entities in a format
+	// other than RFC 3339, such as UnixTime
+	TimeCodec *TimeCodec
+
+	interceptorsMu sync.RWMutex
+	interceptors   []Interceptor
+	// chain is composed from interceptors whenever they change rather than per exchange
+	chain NextCallback
+
+	schedulerMu sync.Mutex
+	scheduler   *requestScheduler
+
+	transportMu     sync.Mutex
+	transportClient *transportHttpClient
+
+	lifecycleMu sync.Mutex
+	shutdown    bool
+	inFlight    int
+	// drained is closed when the last in-flight exchange ends while Shutdown is waiting
+	drained chan struct{}
 }
 
 // NextCallback is the callback type that will be provided to implementations of Interceptor to
@@ -62,15 +153,35 @@ type Interceptor func(req *http.Request, next NextCallback) (*http.Response, err
 type FailedResponseError struct {
 	StatusCode int
 	Status     string
+	Header     http.Header
 	Entity     *Entity
+	// RateLimit is populated when the response included rate limit headers, which is typical of
+	// a 429 Too Many Requests response
+	RateLimit *RateLimitInfo
+	// Problem is populated when the response conveyed RFC 7807 problem details, as indicated by
+	// a content type of ProblemType
+	Problem *ProblemDetails
+	// Truncated indicates that the body was larger than the client's MaxErrorBodySize and only
+	// its beginning is the content of Entity
+	Truncated bool
+}
+
+// Is allows for errors.Is to match ErrConflict when the status code is 412 Precondition Failed
+func (r *FailedResponseError) Is(target error) bool {
+	return target == ErrConflict && r.StatusCode == http.StatusPreconditionFailed
 }
 
 func (r *FailedResponseError) Error() string {
 	// if []byte content then truncate and include in error
 	if r.Entity != nil {
 		if b, ok := r.Entity.Content.([]byte); ok {
+			truncated := r.Truncated
 			if len(b) > errorMessageLimit {
 				b = b[:errorMessageLimit]
+				truncated = true
+			}
+			if truncated {
+				return fmt.Sprintf("%s body=[%s...]", r.Status, string(b))
 			}
 			return fmt.Sprintf("%s body=[%s]", r.Status, string(b))
 		}
@@ -84,10 +195,21 @@ func NewClient() *Client {
 }
 
 func (c *Client) AddInterceptor(it Interceptor) {
-	if c.interceptors == nil {
-		c.interceptors = list.New()
-	}
-	c.interceptors.PushBack(it)
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+	// copies rather than appends in place, so that snapshots given by Interceptors are unaffected
+	interceptors := make([]Interceptor, len(c.interceptors), len(c.interceptors)+1)
+	copy(interceptors, c.interceptors)
+	c.interceptors = append(interceptors, it)
+	c.chain = c.composeChain(c.interceptors)
+}
+
+// Interceptors returns a snapshot of the client's interceptors in the order they process requests.
+// Interceptors added later are not reflected in the returned slice.
+func (c *Client) Interceptors() []Interceptor {
+	c.interceptorsMu.RLock()
+	defer c.interceptorsMu.RUnlock()
+	return c.interceptors[:len(c.interceptors):len(c.interceptors)]
 }
 
 func (c *Client) SetBaseUrl(rawurl string) error {
@@ -102,8 +224,9 @@ func (c *Client) SetBaseUrl(rawurl string) error {
 type MimeType string
 
 const (
-	JsonType MimeType = "application/json"
-	TextType MimeType = "text/plain"
+	JsonType   MimeType = "application/json"
+	TextType   MimeType = "text/plain"
+	BinaryType MimeType = "application/octet-stream"
 )
 
 const (
@@ -114,6 +237,18 @@ const (
 type Entity struct {
 	ContentType MimeType
 	Content     interface{}
+	// ContentLength, when positive, declares the length of request content that is an io.Reader,
+	// since the length of an arbitrary reader cannot otherwise be determined.
+	ContentLength int64
+	// Tee, when set on a response entity, receives a copy of the raw response body as it is
+	// processed into the entity's content, such as for an audit file or checksum hash.
+	Tee io.Writer
+	// Schema, when set, validates the content before a request is sent or after a response
+	// is decoded, such as with a *jsonschema.Schema. See also Validator.
+	Schema SchemaValidator
+	// Accept, when set on a response entity, lists the acceptable content types of the response
+	// and the ContentType of the entity is set from the response. See NewNegotiatedEntity.
+	Accept []AcceptedType
 }
 
 func NewJsonEntity(content interface{}) *Entity {
@@ -130,7 +265,54 @@ func NewTextEntity(content string) *Entity {
 	}
 }
 
-// Exchange prepares an HTTP request with optional JSON encoding,
+// NewBinaryEntity creates an entity of BinaryType with the given bytes as content.
+// For a response entity, the content can be nil and will be replaced with the response body.
+func NewBinaryEntity(content []byte) *Entity {
+	return &Entity{
+		ContentType: BinaryType,
+		Content:     content,
+	}
+}
+
+// NewReaderEntity creates a request entity that streams its content from the given reader.
+// If contentType is empty, then BinaryType is used.
+// If length is positive, then it is sent as the Content-Length of the request; otherwise, the request
+// body is sent with chunked transfer encoding.
+func NewReaderEntity(reader io.Reader, contentType MimeType, length int64) *Entity {
+	if contentType == "" {
+		contentType = BinaryType
+	}
+	return &Entity{
+		ContentType:   contentType,
+		Content:       reader,
+		ContentLength: length,
+	}
+}
+
+// ContentWriter can be used as request content to stream a generated payload, such as an export or
+// batch of log entries, without holding it in memory. The function is invoked in its own goroutine and
+// writes the content to w as the request body is transmitted. A returned error aborts the request.
+type ContentWriter func(w io.Writer) error
+
+func pipeContentWriter(writer ContentWriter) io.Reader {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		_ = pipeWriter.CloseWithError(writer(pipeWriter))
+	}()
+	return pipeReader
+}
+
+// Exchange is the same as ExchangeWithContext, using context.Background(). Prefer
+// ExchangeWithContext, so that exchanges are canceled along with the work that initiated them.
+func (c *Client) Exchange(method string,
+	urlIn string, query url.Values,
+	reqIn *Entity,
+	respOut *Entity,
+	opts ...RequestOption) error {
+	return c.ExchangeWithContext(context.Background(), method, urlIn, query, reqIn, respOut, opts...)
+}
+
+// ExchangeWithContext prepares an HTTP request with optional JSON encoding,
 // sends the request, and optionally processes the response with JSON decoding.
 //
 // The urlIn is either parsed relative to the BaseUrl configured on the client instance or parsed as is.
@@ -138,73 +320,162 @@ func NewTextEntity(content string) *Entity {
 // If given, the query values are encoded into the final request URL.
 //
 // If reqIn is non-nil, the entity's content will be used as the request payload.
-// The entity's content can be a string, []byte, io.Reader, or if the entity's content type is
-// JsonType, then referenced value will be JSON encoded.
+// The entity's content can be a string, []byte, io.Reader, ContentWriter, or if a Codec is registered
+// for the entity's content type, such as JsonType, then referenced value will be encoded by that codec.
+// The Content-Length of the request is set when the length of the content can be determined, which
+// includes an io.Reader that is also an io.Seeker, such as an os.File. Seekable content is not closed
+// by the exchange, so that it can be replayed, and remains the responsibility of the caller.
+// Otherwise, the entity's ContentLength can declare the length of io.Reader content.
 //
 // If respOut is non-nil, the response body will be placed in the entity's content and the
 // content type of that entity is set.
-// The response entity's content can be a string, []byte, io.Writer, or if the entity's content type is
-// JsonType, then the response body is JSON decoded into the content reference.
+// The response entity's content can be a string, []byte, io.Writer, or if a Codec is registered for
+// the entity's content type, such as JsonType, then the response body is decoded into the content reference.
+// A *json.RawMessage content captures the response body as is, which allows for deferred or partial
+// decoding, such as when the type of a response is determined by a discriminator field.
+//
+// The content of either entity is validated by the entity's Schema and, if the content implements
+// Validator, by the content itself. A validation failure is returned as an EntityValidationError.
 //
 // If the far-end responded with a non-2xx status code, then the returned error will be a
-// FailedResponseError, which conveys the status code and response body's content.
-func (c *Client) Exchange(method string,
+// FailedResponseError, which conveys the status code and response body's content. The statuses that
+// are successful can be changed by the client's IsSuccess or WithSuccess. The body of a successful
+// response without a 2xx status is discarded rather than placed in respOut. A redirection that was
+// not followed, such as due to the client's DisableRedirects, is returned as a RedirectionResponse.
+//
+// Options, such as WithResponseInfo, can be given to customize the individual exchange.
+//
+// The request is bound by ctx, which is further limited by the client's Timeout. When the exchange
+// fails due to the client's Timeout, the returned error matches ErrClientTimeout with errors.Is.
+// When it fails due to ctx being canceled or reaching its deadline, the error matches ErrCanceled.
+//
+// A nil ctx is deprecated and treated as context.Background() only for compatibility.
+func (c *Client) ExchangeWithContext(ctx context.Context, method string,
 	urlIn string, query url.Values,
 	reqIn *Entity,
-	respOut *Entity) error {
-	return c.ExchangeWithContext(nil, method, urlIn, query, reqIn, respOut)
+	respOut *Entity,
+	opts ...RequestOption) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := c.beginExchange(); err != nil {
+		return err
+	}
+	defer c.endExchange()
+	c.stats.add(&c.stats.requests)
+	err := c.exchange(context.WithValue(ctx, clientStatsKey{}, &c.stats), method, urlIn, query, reqIn, respOut, opts...)
+	c.stats.record(err)
+	return err
 }
 
-// ExchangeWithContext is the same as Exchange, but allows for a context to be provided
-// to derive the request timeout context.
-func (c *Client) ExchangeWithContext(ctx context.Context, method string,
+func (c *Client) exchange(ctx context.Context, method string,
 	urlIn string, query url.Values,
 	reqIn *Entity,
-	respOut *Entity) error {
+	respOut *Entity,
+	opts ...RequestOption) error {
+
+	options := newRequestOptions(opts)
+	ctx = options.applyContext(ctx)
 
 	reqUrl, err := c.buildReqUrl(urlIn, query)
 	if err != nil {
 		return err
 	}
+	err = c.checkUrlAllowed(reqUrl)
+	if err != nil {
+		return err
+	}
+	err = c.checkMutationAllowed(method, reqUrl)
+	if err != nil {
+		return err
+	}
+	err = c.checkPolicy(method, reqUrl)
+	if err != nil {
+		return err
+	}
 
-	bodyReader, err := c.buildBodyReader(reqIn)
+	err = validateEntity(reqIn, "request")
 	if err != nil {
 		return err
 	}
 
-	if ctx == nil {
-		ctx = context.Background()
+	bodyReader, err := c.buildBodyReader(reqIn)
+	if err != nil {
+		return err
+	}
+	if pipeReader, ok := bodyReader.(*io.PipeReader); ok {
+		// unblocks the content writer if the request body wasn't fully consumed
+		defer pipeReader.Close()
 	}
-	timeoutCtx, cancelFunc := context.WithTimeout(ctx, c.timeout())
+
+	timeoutCtx, cancelFunc := c.withTimeout(ctx)
 	defer cancelFunc()
 
 	req, err := c.buildRequest(timeoutCtx, method, reqUrl, bodyReader, reqIn, respOut)
 	if err != nil {
 		return err
 	}
+	options.applyHeaders(req)
+
+	release, err := c.acquireRequestSlot(timeoutCtx)
+	if err != nil {
+		return classifyContextError(ctx, timeoutCtx, err)
+	}
+	defer release()
+
+	var watchdog *responseHeaderWatchdog
+	if options.idleTimeout > 0 {
+		var stopWatchdog context.CancelFunc
+		req, watchdog, stopWatchdog = watchResponseHeaders(req, options.idleTimeout)
+		defer stopWatchdog()
+	}
 
-	var firstInterceptor *list.Element = nil
-	if c.interceptors != nil {
-		firstInterceptor = c.interceptors.Front()
+	resp, err := c.send(req)
+	if watchdog != nil {
+		err = watchdog.classify(err)
 	}
-	resp, err := c.doRequest(req, firstInterceptor)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", classifyContextError(ctx, timeoutCtx, err))
 	}
+	if idleTimeout := c.bodyReadTimeout(options); idleTimeout > 0 {
+		resp.Body = newIdleTimeoutBody(resp.Body, idleTimeout)
+	}
+	options.captureResponse(resp)
 
-	if resp.StatusCode >= 300 {
+	if !c.isSuccess(options, resp.StatusCode) {
 		// also closes the response body
 		return c.buildFailedResponseError(resp)
 	}
+	if resp.StatusCode >= 300 {
+		// the body of a successful non-2xx response, such as 304 Not Modified, is not the content
+		discardBody(resp)
+		return nil
+	}
 
 	if respOut != nil {
 		err := c.processResponseContent(respOut, resp)
+		if err != nil {
+			_ = resp.Body.Close()
+			return classifyContextError(ctx, timeoutCtx, err)
+		}
+		err = validateEntity(respOut, "response")
 		if err != nil {
 			_ = resp.Body.Close()
 			return err
 		}
 	}
 
+	if options.responseInfo != nil && len(resp.Trailer) > 0 {
+		// trailers are only available once the body has been fully consumed
+		_, err = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxTrailerDrainSize))
+		if err != nil {
+			_ = resp.Body.Close()
+			return classifyContextError(ctx, timeoutCtx, fmt.Errorf("failed to read response body: %w", err))
+		}
+		options.responseInfo.Trailer = resp.Trailer
+	}
+
 	err = resp.Body.Close()
 	if err != nil {
 		return fmt.Errorf("failed to close response body: %w", err)
@@ -227,6 +498,7 @@ func (c *Client) buildReqUrl(urlIn string, query url.Values) (*url.URL, error) {
 			return nil, fmt.Errorf("filed to parse given url %s: %w", urlIn, err)
 		}
 	}
+	fillPlaceholders(reqUrl, c.BaseUrlParams)
 	if len(query) > 0 {
 		reqUrl.RawQuery = query.Encode()
 	}
@@ -243,14 +515,28 @@ func (c *Client) buildBodyReader(reqIn *Entity) (io.Reader, error) {
 		bodyReader = bytes.NewBuffer(b)
 	} else if r, ok := reqIn.Content.(io.Reader); ok {
 		bodyReader = r
-	} else if reqIn.ContentType == JsonType && reqIn.Content != nil {
-		var buffer bytes.Buffer
-		encoder := json.NewEncoder(&buffer)
-		err := encoder.Encode(reqIn.Content)
+	} else if w, ok := reqIn.Content.(ContentWriter); ok {
+		bodyReader = pipeContentWriter(w)
+	} else if w, ok := reqIn.Content.(func(io.Writer) error); ok {
+		bodyReader = pipeContentWriter(w)
+	} else if codec := lookupCodec(reqIn.ContentType); codec != nil && reqIn.Content != nil {
+		buffer := getBuffer()
+		defer putBuffer(buffer)
+		err := codec.Encode(buffer, reqIn.Content)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encode body: %w", err)
 		}
-		bodyReader = &buffer
+		if c.TimeCodec != nil && reqIn.ContentType == JsonType {
+			converted, err := c.TimeCodec.encodeTimes(codec, buffer.Bytes(), reflect.TypeOf(reqIn.Content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode times of body: %w", err)
+			}
+			buffer.Reset()
+			buffer.Write(converted)
+		}
+		// the transport may still be reading the body after the exchange, so it can't use the
+		// pooled buffer itself
+		bodyReader = bytes.NewReader(copyBytes(buffer))
 	} else {
 		return nil, fmt.Errorf("unsupported combination of request content and type")
 	}
@@ -263,85 +549,239 @@ func (c *Client) buildRequest(timeoutCtx context.Context, method string, reqUrl
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup request: %w", err)
 	}
+	req.Header.Set(headerUserAgent, c.userAgent())
+	applyContextHeaders(req)
 	if reqIn != nil && reqIn.ContentType != "" {
 		req.Header.Set(headerContentType, string(reqIn.ContentType))
 	}
-	if respOut != nil && respOut.ContentType != "" {
+	if reqIn != nil && reqIn.ContentLength > 0 && req.ContentLength == 0 {
+		req.ContentLength = reqIn.ContentLength
+	} else if seeker, ok := bodyReader.(io.ReadSeeker); ok && req.ContentLength == 0 {
+		err := setSeekableBody(req, seeker)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if respOut != nil && len(respOut.Accept) > 0 {
+		req.Header.Set(headerAccept, acceptHeader(respOut.Accept))
+	} else if respOut != nil && respOut.ContentType != "" {
 		req.Header.Set(headerAccept, string(respOut.ContentType))
 	}
 	return req, nil
 }
 
+// setSeekableBody populates the request's content length and GetBody from the remaining content of
+// the seeker, such as an os.File, so that the body is not sent chunked and can be replayed.
+// The seeker is not closed since it needs to remain open to be replayed. A seeker that can't seek,
+// such as an os.File of a pipe, is left to be streamed.
+//
+// When the seeker is an io.ReaderAt, each body reads an independent section of it. Otherwise, the
+// bodies share the seeker, so a caller reading from GetBody must reset the request's Body from
+// another GetBody before the request is sent, as done by ReadRequestBody.
+func setSeekableBody(req *http.Request, seeker io.ReadSeeker) error {
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset request content position: %w", err)
+	}
+
+	req.ContentLength = end - start
+	if req.ContentLength == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return nil
+	}
+	if readerAt, ok := seeker.(io.ReaderAt); ok {
+		length := req.ContentLength
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(io.NewSectionReader(readerAt, start, length)), nil
+		}
+		req.Body, _ = req.GetBody()
+		return nil
+	}
+	req.Body = ioutil.NopCloser(seeker)
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(seeker), nil
+	}
+	return nil
+}
+
 func (c *Client) processResponseContent(respOut *Entity, resp *http.Response) error {
+	if len(respOut.Accept) > 0 {
+		if err := negotiateContentType(respOut, resp); err != nil {
+			return err
+		}
+	}
+
+	var body io.Reader = resp.Body
+	if respOut.Tee != nil {
+		body = io.TeeReader(resp.Body, respOut.Tee)
+	}
+
 	if _, ok := respOut.Content.(string); ok {
-		var buffer bytes.Buffer
-		_, err := io.Copy(&buffer, resp.Body)
+		buffer := getBuffer()
+		defer putBuffer(buffer)
+		_, err := io.Copy(buffer, body)
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
 		respOut.Content = buffer.String()
 	} else if _, ok := respOut.Content.([]byte); ok {
-		var buffer bytes.Buffer
-		_, err := io.Copy(&buffer, resp.Body)
+		b, err := readAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		respOut.Content = b
+	} else if raw, ok := respOut.Content.(*json.RawMessage); ok {
+		b, err := readAll(body)
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
-		respOut.Content = buffer.Bytes()
+		*raw = bytes.TrimSpace(b)
 	} else if w, ok := respOut.Content.(io.Writer); ok {
-		_, err := io.Copy(w, resp.Body)
+		_, err := io.Copy(w, body)
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
-	} else if respOut.ContentType == JsonType && respOut.Content != nil {
-		decoder := json.NewDecoder(resp.Body)
-		err := decoder.Decode(respOut.Content)
+	} else if codec := lookupCodec(respOut.ContentType); codec != nil && respOut.Content != nil {
+		capture := &bodyCapture{limit: errorMessageLimit}
+		var err error
+		if c.TimeCodec != nil && respOut.ContentType == JsonType {
+			err = c.TimeCodec.decode(codec, io.TeeReader(body, capture), respOut.Content)
+		} else {
+			err = codec.Decode(io.TeeReader(body, capture), respOut.Content)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+			// captures the rest of the body, up to the limit, since decoders may stop short of it
+			remaining := int64(capture.limit - capture.buffer.Len())
+			_, _ = io.Copy(capture, io.LimitReader(body, remaining+1))
+			return newDecodeError(respOut.ContentType, capture, err)
 		}
 	} else {
 		return fmt.Errorf("unsupported combination of request content reference and type")
 	}
+
+	if respOut.Tee != nil {
+		// decoders may stop short of the end of the body, so ensure the tee gets all of it
+		_, err := io.Copy(ioutil.Discard, body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
 	return nil
 }
 
 func (c *Client) buildFailedResponseError(resp *http.Response) error {
-	var buffer bytes.Buffer
-	_, _ = io.Copy(&buffer, resp.Body)
-	_ = resp.Body.Close()
-	return &FailedResponseError{
+	limit := c.maxErrorBodySize()
+	// reads one more than the limit to detect truncation
+	body, _ := readAll(io.LimitReader(resp.Body, limit+1))
+	truncated := int64(len(body)) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	// drains a bounded amount of the remainder, which allows the connection to be reused
+	discardBody(resp)
+	failed := &FailedResponseError{
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
+		Header:     resp.Header,
+		RateLimit:  ParseRateLimit(resp.Header),
+		Problem:    parseProblemDetails(resp.Header.Get(headerContentType), body),
 		Entity: &Entity{
 			ContentType: MimeType(resp.Header.Get(headerContentType)),
-			Content:     buffer.Bytes(),
+			Content:     body,
 		},
+		Truncated: truncated,
 	}
+	if c.ClassifyError != nil {
+		if classified := c.ClassifyError(failed); classified != nil {
+			return classified
+		}
+	}
+	if redirection := newRedirectionResponse(resp, failed); redirection != nil {
+		return redirection
+	}
+	return failed
 }
 
-// doRequest will recursively process the interceptors via the position in the list conveyed by interceptorElem
-// and when interceptorElem is nil the actual request is issued
-func (c *Client) doRequest(req *http.Request, interceptorElem *list.Element) (*http.Response, error) {
+// composeChain nests the interceptors, in order, around the actual sending of the request
+func (c *Client) composeChain(interceptors []Interceptor) NextCallback {
+	return chainInterceptors(interceptors, c.sendRequest)
+}
 
-	if interceptorElem == nil {
-		return http.DefaultClient.Do(req)
-	} else {
-		// use unchecked cast since we force value types via AddInterceptor
-		interceptor := interceptorElem.Value.(Interceptor)
-		response, err := interceptor(req, func(newReq *http.Request) (*http.Response, error) {
-			return c.doRequest(newReq, interceptorElem.Next())
-		})
-		if err != nil {
-			return nil, err
-		} else {
-			return response, err
+// chainInterceptors nests the interceptors, in order, around the terminal callback
+func chainInterceptors(interceptors []Interceptor, terminal NextCallback) NextCallback {
+	chain := terminal
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], chain
+		chain = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, next)
 		}
 	}
+	return chain
 }
 
-func (c *Client) timeout() time.Duration {
-	if c.Timeout != 0 {
+// send processes the request through the interceptors and then sends it
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	c.interceptorsMu.RLock()
+	chain := c.chain
+	c.interceptorsMu.RUnlock()
+	if chain == nil {
+		chain = c.sendRequest
+	}
+
+	resp, err := chain(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	base := c.HttpClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+	if c.customizesTransport() {
+		return c.transportHttpClient(base)
+	}
+	return base
+}
+
+func (c *Client) maxErrorBodySize() int64 {
+	if c.MaxErrorBodySize > 0 {
+		return c.MaxErrorBodySize
+	}
+	return defaultMaxErrorBodySize
+}
+
+// EffectiveTimeout returns the timeout applied to exchanges, which is the DefaultTimeout when the
+// Timeout is zero or NoTimeout when the timeout is disabled
+func (c *Client) EffectiveTimeout() time.Duration {
+	switch {
+	case c.Timeout < 0:
+		return NoTimeout
+	case c.Timeout == 0:
+		return DefaultTimeout
+	default:
 		return c.Timeout
-	} else {
-		return defaultRestClientTimeout
 	}
 }
+
+// withTimeout derives the context of an exchange, which is bounded by the effective timeout
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := c.EffectiveTimeout()
+	if timeout == NoTimeout {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -17,7 +17,11 @@
 package restclient_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/racker/go-restclient"
 	"io"
@@ -26,6 +30,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
 )
 
 func Example_post() {
@@ -252,11 +260,31 @@ func Example_decodeError() {
 
 	err := client.Exchange("GET", "/msg", nil, nil,
 		restclient.NewJsonEntity(&resp))
-	if err != nil {
-		fmt.Println(err)
+	var decodeErr *restclient.DecodeError
+	if errors.As(err, &decodeErr) {
+		fmt.Println(decodeErr.Offset, string(decodeErr.Body))
 	}
 	// Output:
-	// failed to decode response: json: cannot unmarshal object into Go struct field MsgHolder.Msg of type string
+	// 8 {"msg":{"content":4}}
+}
+
+func Example_decodeErrorOfHtml() {
+	// Setup a test HTTP server, such as a misconfigured proxy, that responds with an HTML page
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body>Service Unavailable</body></html>`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var resp map[string]interface{}
+	err := client.Exchange("GET", "/msg", nil, nil,
+		restclient.NewJsonEntity(&resp))
+	fmt.Println(err)
+	// Output:
+	// failed to decode response at offset 1: invalid character '<' looking for beginning of value body=[<html><body>Service Unavailable</body></html>]
 }
 
 func ExampleBasicAuth() {
@@ -291,3 +319,498 @@ func ExampleBasicAuth() {
 	// authenticated
 
 }
+
+func ExampleApiKeyAuth() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s %s\n", r.Header.Get("X-Api-Key"), r.URL.RawQuery)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.ApiKeyAuth("X-Api-Key", "abc123"))
+
+	err := client.Exchange("GET", "/msg", url.Values{"limit": {"5"}}, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// RECV abc123 limit=5
+}
+
+func ExampleTokenAuth() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s\n", r.Header.Get("X-Subject-Token"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.TokenAuth(restclient.SubjectTokenHeader, "abc123"))
+
+	err := client.Exchange("GET", "/msg", nil, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// RECV abc123
+}
+
+func ExampleQueryApiKeyAuth() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s\n", r.URL.RawQuery)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.QueryApiKeyAuth("api_key", "abc123"))
+
+	err := client.Exchange("GET", "/msg", url.Values{"limit": {"5"}}, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// RECV api_key=abc123&limit=5
+}
+
+func ExampleNewReaderEntity() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %s %d %v %s\n",
+			r.Header.Get("Content-Type"), r.ContentLength, r.TransferEncoding, string(bytes))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// a reader of unknown length, such as a file or network stream
+	reader := io.MultiReader(strings.NewReader("part1,"), strings.NewReader("part2"))
+
+	err := client.Exchange("PUT", "/objects/parts", nil,
+		restclient.NewReaderEntity(reader, "", 11), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// RECV application/octet-stream 11 [] part1,part2
+}
+
+func Example_rawJson() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind":"circle","radius":2.5}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	var raw json.RawMessage
+	err := client.Exchange("GET", "/shapes/1", nil, nil,
+		restclient.NewJsonEntity(&raw))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// first decode just the discriminator field...
+	var discriminator struct {
+		Kind string
+	}
+	if err := json.Unmarshal(raw, &discriminator); err != nil {
+		log.Fatal(err)
+	}
+
+	// ...and then decode the full content according to its kind
+	switch discriminator.Kind {
+	case "circle":
+		var circle struct {
+			Radius float64
+		}
+		if err := json.Unmarshal(raw, &circle); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("circle of radius %.1f\n", circle.Radius)
+	}
+	// Output:
+	// circle of radius 2.5
+}
+
+func ExampleEntity_tee() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Msg":"hello"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	type MsgHolder struct {
+		Msg string
+	}
+	var resp MsgHolder
+	hasher := sha256.New()
+
+	respEntity := restclient.NewJsonEntity(&resp)
+	respEntity.Tee = hasher
+	err := client.Exchange("GET", "/msg", nil, nil, respEntity)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp.Msg)
+	fmt.Printf("%x\n", hasher.Sum(nil))
+	// Output:
+	// hello
+	// 17c09ea5516528e6d199c24cf32c76465db832b23a392fd865d0862c14f53ca0
+}
+
+func ExampleContentWriter() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV BODY\n%s", string(bytes))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	producer := restclient.ContentWriter(func(w io.Writer) error {
+		for i := 1; i <= 3; i++ {
+			if _, err := fmt.Fprintf(w, "log entry %d\n", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	err := client.Exchange("POST", "/logs", nil,
+		&restclient.Entity{ContentType: restclient.TextType, Content: producer}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// RECV BODY
+	// log entry 1
+	// log entry 2
+	// log entry 3
+}
+
+func Example_fileContentLength() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %v %s\n", r.ContentLength, r.TransferEncoding, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("file content")
+	file.Seek(0, io.SeekStart)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	defer file.Close()
+	err = client.Exchange("PUT", "/objects/file", nil,
+		restclient.NewReaderEntity(file, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// RECV 12 [] file content
+}
+
+func Example_fileGetBody() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %s\n", r.ContentLength, string(bytes))
+	}))
+	defer ts.Close()
+
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("file content")
+	file.Seek(0, io.SeekStart)
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	// an interceptor that reads a copy of the body doesn't consume the body being sent
+	client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		content, _ := ioutil.ReadAll(body)
+		fmt.Printf("COPY %s\n", string(content))
+		return next(req)
+	})
+
+	defer file.Close()
+	err = client.Exchange("PUT", "/objects/file", nil,
+		restclient.NewReaderEntity(file, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// COPY file content
+	// RECV 12 file content
+}
+
+func Example_pipeContentStreamed() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("RECV %d %v %s\n", r.ContentLength, r.TransferEncoding, string(bytes))
+	}))
+	defer ts.Close()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		writer.WriteString("piped content")
+		writer.Close()
+	}()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// a pipe, such as os.Stdin, can't seek, so it is streamed
+	defer reader.Close()
+	err = client.Exchange("PUT", "/objects/piped", nil,
+		restclient.NewReaderEntity(reader, "", 0), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// RECV -1 [chunked] piped content
+}
+
+// roundTripperFunc responds in memory, so that benchmarks measure the client rather than the network
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func benchmarkClient(status int, body []byte) *restclient.Client {
+	client := restclient.NewClient()
+	_ = client.SetBaseUrl("http://localhost")
+	client.HttpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_, _ = io.Copy(ioutil.Discard, req.Body)
+			_ = req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})}
+	return client
+}
+
+func BenchmarkClient_Exchange(b *testing.B) {
+	type Server struct {
+		Id       string
+		Name     string
+		Metadata map[string]string
+	}
+	server := Server{Id: "abc", Name: "web", Metadata: map[string]string{"role": "web"}}
+	body, _ := json.Marshal(server)
+
+	b.Run("json", func(b *testing.B) {
+		client := benchmarkClient(http.StatusOK, body)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var resp Server
+			err := client.Exchange("PUT", "/servers/abc", nil,
+				restclient.NewJsonEntity(server), restclient.NewJsonEntity(&resp))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("bytes", func(b *testing.B) {
+		client := benchmarkClient(http.StatusOK, body)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			err := client.Exchange("GET", "/servers/abc", nil, nil, restclient.NewBinaryEntity(nil))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("interceptors", func(b *testing.B) {
+		client := benchmarkClient(http.StatusOK, body)
+		for i := 0; i < 5; i++ {
+			client.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+				return next(req)
+			})
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			err := client.Exchange("GET", "/servers/abc", nil, nil, restclient.NewBinaryEntity(nil))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("failed", func(b *testing.B) {
+		client := benchmarkClient(http.StatusNotFound, []byte(`{"itemNotFound":{"message":"not found"}}`))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			err := client.Exchange("GET", "/servers/abc", nil, nil, nil)
+			if err == nil {
+				b.Fatal("expected an error")
+			}
+		}
+	})
+}
+
+func ExampleClient_Interceptors() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path, r.Header.Get("X-Auth-Token"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.TokenAuth(restclient.AuthTokenHeader, "token1"))
+
+	// another client for a different service, but with the same authentication
+	other := restclient.NewClient()
+	other.SetBaseUrl(ts.URL + "/other/")
+	for _, interceptor := range client.Interceptors() {
+		other.AddInterceptor(interceptor)
+	}
+
+	fmt.Println(other.Exchange("GET", "resource", nil, nil, nil))
+	// Output:
+	// RECV /other/resource token1
+	// <nil>
+}
+
+func ExampleClient_MaxErrorBodySize() {
+	// Setup a test HTTP server that responds with a huge error page
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "Internal error: "+strings.Repeat("stack frame\n", 100000))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.MaxErrorBodySize = 20
+
+	err := client.Exchange("GET", "/", nil, nil, nil)
+	var failed *restclient.FailedResponseError
+	if errors.As(err, &failed) {
+		fmt.Printf("%q %t\n", failed.Entity.Content, failed.Truncated)
+	}
+	fmt.Println(err)
+	// Output:
+	// "Internal error: stac" true
+	// 500 Internal Server Error body=[Internal error: stac...]
+}
+
+func ExampleClient_UserAgent() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent := r.Header.Get("User-Agent")
+		fmt.Println("RECV", strings.HasPrefix(userAgent, "mytool/1.0 go-restclient/"),
+			strings.HasSuffix(userAgent, runtime.Version()+")"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.UserAgent = "mytool/1.0"
+
+	err := client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// RECV true true
+	// <nil>
+}
+
+// MaintenanceError is a domain error of the application
+type MaintenanceError struct {
+	Cause error
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("service is in maintenance: %v", e.Cause)
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return e.Cause
+}
+
+func ExampleClient_ClassifyError() {
+	// Setup a test HTTP server that is in maintenance mode
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Maintenance", "true")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.ClassifyError = func(failed *restclient.FailedResponseError) error {
+		if failed.StatusCode == http.StatusServiceUnavailable && failed.Header.Get("X-Maintenance") == "true" {
+			return &MaintenanceError{Cause: failed}
+		}
+		return nil
+	}
+
+	err := client.Exchange("GET", "/", nil, nil, nil)
+	var maintenance *MaintenanceError
+	var failed *restclient.FailedResponseError
+	fmt.Println(errors.As(err, &maintenance), errors.As(err, &failed))
+	fmt.Println(err)
+	// Output:
+	// true true
+	// service is in maintenance: 503 Service Unavailable body=[]
+}
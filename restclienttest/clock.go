@@ -0,0 +1,123 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package restclienttest provides utilities for testing code that uses the restclient package.
+*/
+package restclienttest
+
+import (
+	"github.com/racker/go-restclient"
+	"sync"
+	"time"
+)
+
+// FakeClock is a restclient.Clock whose time only moves when advanced by the test
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+type fakeClockWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at the given time
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time once the clock has been advanced by at least d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.addWaiter(d)
+}
+
+// NewTimer returns a timer that fires once the clock has been advanced by at least d. A stopped
+// timer is no longer counted by Waiters.
+func (c *FakeClock) NewTimer(d time.Duration) restclient.Timer {
+	return &fakeTimer{clock: c, ch: c.addWaiter(d)}
+}
+
+func (c *FakeClock) addWaiter(d time.Duration) chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeClockWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// removeWaiter removes the waiter of ch and reports if it had not yet fired
+func (c *FakeClock) removeWaiter(ch chan time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w.ch == ch {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d and fires any timers that have become due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.ch <- c.now
+		} else {
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+}
+
+// Waiters returns the number of timers that have not yet fired, which allows a test to wait
+// for code under test to start waiting on the clock before advancing it
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	ch    chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.clock.removeWaiter(t.ch)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"time"
+)
+
+func ExampleFakeClock() {
+	// Setup a test HTTP server
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	clock := restclienttest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{
+		InitialBackoff: time.Hour,
+		MaxBackoff:     time.Hour,
+		Clock:          clock,
+	}))
+
+	done := make(chan error)
+	go func() {
+		done <- client.Exchange("GET", "/", nil, nil, nil)
+	}()
+
+	// wait for the retry to start its backoff and then skip past it
+	for clock.Waiters() == 0 {
+		runtime.Gosched()
+	}
+	clock.Advance(time.Hour)
+
+	fmt.Println(<-done, attempts)
+	// Output:
+	// <nil> 2
+}
+
+func ExampleFakeClock_NewTimer() {
+	clock := restclienttest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	// such as the backoff of a retry abandoned by the end of its request's context
+	abandoned := clock.NewTimer(time.Minute)
+	fired := clock.NewTimer(time.Minute)
+	fmt.Println(clock.Waiters(), abandoned.Stop(), clock.Waiters())
+
+	clock.Advance(time.Minute)
+	fmt.Println(<-fired.C(), fired.Stop())
+	// Output:
+	// 2 true 1
+	// 2020-01-01 00:01:00 +0000 UTC false
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fixture is the content of a fixture file
+type fixture struct {
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	// Body holds a JSON body as is, for readability, otherwise Text holds the body
+	Body json.RawMessage `json:"body,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// FixtureRecorder writes the responses received while running against a real API into fixture
+// files, which a FixtureTransport can later serve to consumer tests. Headers, query parameters,
+// and JSON fields are masked according to Redaction.
+//
+// Use the Intercept method as the Interceptor of the client, typically only when recording, such as
+//
+//	if os.Getenv("RECORD_FIXTURES") != "" {
+//		client.AddInterceptor(restclienttest.NewFixtureRecorder("testdata/fixtures").Intercept)
+//	}
+//
+// The fixture of each response is keyed by the method and path of the request, so the latest
+// response replaces any earlier one for the same method and path.
+type FixtureRecorder struct {
+	// Dir is the directory holding the fixture files
+	Dir string
+	// Redaction determines the values masked in the fixtures, which defaults to
+	// restclient.DefaultRedactionPolicy
+	Redaction *restclient.RedactionPolicy
+
+	mu sync.Mutex
+}
+
+// NewFixtureRecorder creates a FixtureRecorder that writes fixture files into dir
+func NewFixtureRecorder(dir string) *FixtureRecorder {
+	return &FixtureRecorder{Dir: dir}
+}
+
+// Intercept is an Interceptor that writes the response to its fixture file and passes it along
+// with the body intact
+func (r *FixtureRecorder) Intercept(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for fixture: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	redaction := r.Redaction
+	if redaction == nil {
+		redaction = &restclient.DefaultRedactionPolicy
+	}
+	header := redaction.RedactHeader(resp.Header)
+	// the body is re-encoded, so the original length no longer applies
+	header.Del("Content-Length")
+	recorded := fixture{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Header:     header,
+	}
+	content = bytes.TrimSpace(redaction.RedactJson(content))
+	if len(content) > 0 && json.Valid(content) {
+		recorded.Body = content
+	} else {
+		recorded.Text = string(content)
+	}
+
+	if err := r.write(fixturePath(r.Dir, req.Method, req.URL.Path), recorded); err != nil {
+		return nil, fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *FixtureRecorder) write(filename string, recorded fixture) error {
+	content, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(content, '\n'), 0644)
+}
+
+// FixtureTransport is an http.RoundTripper that serves the responses recorded by a FixtureRecorder
+// rather than sending requests. A request without a fixture fails with an error naming the
+// missing fixture.
+//
+//	client.HttpClient = &http.Client{Transport: restclienttest.NewFixtureTransport("testdata/fixtures")}
+type FixtureTransport struct {
+	// Dir is the directory holding the fixture files
+	Dir string
+}
+
+// NewFixtureTransport creates a FixtureTransport that serves the fixture files in dir
+func NewFixtureTransport(dir string) *FixtureTransport {
+	return &FixtureTransport{Dir: dir}
+}
+
+// RoundTrip serves the fixture of the request's method and path
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	filename := fixturePath(t.Dir, req.Method, req.URL.Path)
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	var recorded fixture
+	if err := json.Unmarshal(content, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", filename, err)
+	}
+
+	body := []byte(recorded.Text)
+	if len(recorded.Body) > 0 {
+		body = recorded.Body
+	}
+	header := recorded.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixturePath is the file holding the fixture of the method and path, where each path segment is
+// a directory, such that GET /servers/123 is held in servers/123/GET.json
+func fixturePath(dir string, method string, urlPath string) string {
+	// cleaning from the root keeps the fixture within dir
+	cleaned := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	var segments []string
+	if cleaned != "" {
+		for _, segment := range strings.Split(cleaned, "/") {
+			segments = append(segments, sanitizeTestName(segment))
+		}
+	}
+	segments = append([]string{dir}, segments...)
+	return filepath.Join(append(segments, strings.ToUpper(method)+".json")...)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest
+
+import (
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtureRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Auth-Token", "token-from-server")
+		_, _ = w.Write([]byte(`{"server":{"id":"123","name":"web","password":"hunter2"}}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	type Server struct {
+		Id       string `json:"id"`
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	var out struct {
+		Server Server `json:"server"`
+	}
+
+	// recording passes the real response along
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(NewFixtureRecorder(dir).Intercept)
+	if err := client.Exchange("GET", "/servers/123", nil, nil, restclient.NewJsonEntity(&out)); err != nil {
+		t.Fatal(err)
+	}
+	if out.Server.Password != "hunter2" {
+		t.Errorf("expected the real response, got %+v", out.Server)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "servers", "123", "GET.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "hunter2") || strings.Contains(string(content), "token-from-server") {
+		t.Errorf("expected sensitive values to be masked:\n%s", content)
+	}
+
+	// serving the fixture
+	client = restclient.NewClient()
+	client.SetBaseUrl("https://api.example.com")
+	client.HttpClient = &http.Client{Transport: NewFixtureTransport(dir)}
+	out.Server = Server{}
+	if err := client.Exchange("GET", "/servers/123", nil, nil, restclient.NewJsonEntity(&out)); err != nil {
+		t.Fatal(err)
+	}
+	expected := Server{Id: "123", Name: "web", Password: "REDACTED"}
+	if out.Server != expected {
+		t.Errorf("unexpected response from fixture: %+v", out.Server)
+	}
+
+	err = client.Exchange("DELETE", "/servers/123", nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "no fixture for DELETE /servers/123") {
+		t.Errorf("expected missing fixture error, got %v", err)
+	}
+}
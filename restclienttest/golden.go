@@ -0,0 +1,191 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to a non-empty value, causes golden
+// files to be rewritten with the actual content rather than compared
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenRequests snapshots the requests produced by client code into golden files and, on subsequent
+// runs, reports differences as test errors. This catches unintended changes to the serialization of
+// API payloads.
+//
+// Use the Intercept method as the Interceptor of the client under test. Requests are passed along
+// after being checked, so the client can still be exercised against a test server.
+type GoldenRequests struct {
+	// Dir is the directory holding the golden files, which are named after the test and the
+	// sequence of the request within the test
+	Dir string
+	// Headers are the names of the headers included in the snapshot, which defaults to
+	// Content-Type and Accept. Values of other headers, such as authentication tokens, tend to vary.
+	Headers []string
+	// Update rewrites the golden files rather than comparing, which defaults to true when the
+	// UPDATE_GOLDEN environment variable is set
+	Update bool
+
+	t     testing.TB
+	mu    sync.Mutex
+	count int
+}
+
+// NewGoldenRequests creates a GoldenRequests for the given test that uses golden files in dir,
+// typically "testdata"
+func NewGoldenRequests(t testing.TB, dir string) *GoldenRequests {
+	return &GoldenRequests{
+		Dir:     dir,
+		Headers: []string{"Content-Type", "Accept"},
+		Update:  os.Getenv(UpdateGoldenEnv) != "",
+		t:       t,
+	}
+}
+
+// Intercept is an Interceptor that compares the request to its golden file
+func (g *GoldenRequests) Intercept(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+	g.mu.Lock()
+	g.count++
+	seq := g.count
+	g.mu.Unlock()
+
+	recorded := recordRequest(req)
+	actual := g.snapshot(recorded)
+	filename := filepath.Join(g.Dir, fmt.Sprintf("%s_%d.golden", sanitizeTestName(g.t.Name()), seq))
+
+	expected, err := ioutil.ReadFile(filename)
+	if g.Update || os.IsNotExist(err) {
+		if err := os.MkdirAll(g.Dir, 0755); err != nil {
+			g.t.Errorf("failed to create golden directory: %v", err)
+		} else if err := ioutil.WriteFile(filename, []byte(actual), 0644); err != nil {
+			g.t.Errorf("failed to write golden file: %v", err)
+		} else {
+			g.t.Logf("wrote golden file %s", filename)
+		}
+	} else if err != nil {
+		g.t.Errorf("failed to read golden file: %v", err)
+	} else if string(expected) != actual {
+		g.t.Errorf("request %d differs from golden file %s (set %s=1 to update):\n%s",
+			seq, filename, UpdateGoldenEnv, lineDiff(string(expected), actual))
+	}
+
+	return next(req)
+}
+
+// snapshot renders the request in a stable, diff-friendly text form
+func (g *GoldenRequests) snapshot(req RecordedRequest) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%s %s\n", req.Method, stripHost(req.Url))
+
+	headers := append([]string{}, g.Headers...)
+	sort.Strings(headers)
+	for _, name := range headers {
+		if value := req.Header.Get(name); value != "" {
+			fmt.Fprintf(&buffer, "%s: %s\n", http.CanonicalHeaderKey(name), value)
+		}
+	}
+
+	if len(req.Body) > 0 {
+		buffer.WriteString("\n")
+		body := bytes.TrimSpace(req.Body)
+		var indented bytes.Buffer
+		if json.Valid(body) && json.Indent(&indented, body, "", "  ") == nil {
+			buffer.Write(indented.Bytes())
+		} else {
+			buffer.Write(body)
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// stripHost removes the scheme and host since test servers listen on varying ports
+func stripHost(rawurl string) string {
+	if i := strings.Index(rawurl, "://"); i >= 0 {
+		rest := rawurl[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	return rawurl
+}
+
+func sanitizeTestName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ', '*', '?', '"', '<', '>', '|':
+			return '_'
+		default:
+			return r
+		}
+	}, name)
+}
+
+// lineDiff renders the lines removed from expected with "-" and added in actual with "+"
+// based on their longest common subsequence
+func lineDiff(expected, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&buffer, "  %s\n", a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&buffer, "+ %s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&buffer, "- %s\n", a[i])
+			i++
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest
+
+import (
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoldenRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	type Server struct {
+		Name   string `json:"name"`
+		Flavor string `json:"flavor"`
+	}
+	exchange := func(t testing.TB, flavor string) {
+		golden := NewGoldenRequests(t, dir)
+		golden.Update = false
+
+		client := restclient.NewClient()
+		client.SetBaseUrl(ts.URL)
+		client.AddInterceptor(golden.Intercept)
+		err := client.Exchange("POST", "/servers", nil,
+			restclient.NewJsonEntity(&Server{Name: "web", Flavor: flavor}), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// first run writes the golden file
+	exchange(t, "general1-1")
+	content, err := ioutil.ReadFile(filepath.Join(dir, "TestGoldenRequests_1.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "POST /servers\nContent-Type: application/json\n\n{\n  \"name\": \"web\",\n  \"flavor\": \"general1-1\"\n}\n"
+	if string(content) != expected {
+		t.Errorf("unexpected golden content:\n%s", content)
+	}
+
+	// same request matches
+	recorder := &recordingTB{TB: t}
+	exchange(recorder, "general1-1")
+	if len(recorder.errors) != 0 {
+		t.Errorf("expected match, got %v", recorder.errors)
+	}
+
+	// changed request is reported
+	recorder = &recordingTB{TB: t}
+	exchange(recorder, "general1-2")
+	if len(recorder.errors) != 1 {
+		t.Fatalf("expected difference to be reported, got %v", recorder.errors)
+	}
+}
+
+// recordingTB captures errors rather than failing the test
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
@@ -0,0 +1,413 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultTokenLifetime = 24 * time.Hour
+
+// ImpersonatorRoles are the roles of users that are allowed to obtain impersonation tokens
+var ImpersonatorRoles = []string{"Racker", "identity:admin"}
+
+// IdentityUser declares a user known to an IdentityServer
+type IdentityUser struct {
+	Username string
+	// Password enables the password flow for the user, when non-empty
+	Password string
+	// Apikey enables the API key flow for the user, when non-empty
+	Apikey string
+	// Passcode enables multi-factor authentication of the password flow, when non-empty,
+	// where the passcode is required to complete the authentication
+	Passcode string
+	TenantId string
+	Roles    []string
+}
+
+// IdentityServer emulates the token endpoint of Rackspace Cloud Identity v2.0, so that the
+// restclient.IdentityV2Authenticator can be integration tested without the real Identity service.
+//
+// Issued tokens expire after TokenLifetime, as measured by Clock, or can be revoked explicitly.
+// Protected test endpoints can be wrapped with RequireToken to respond with 401 Unauthorized to
+// expired or revoked tokens.
+//
+// The RAX-AUTH impersonation endpoint is also emulated, which issues a token for the requested user
+// when the caller's token belongs to a user having one of the ImpersonatorRoles.
+type IdentityServer struct {
+	*httptest.Server
+	// TokenLifetime is the lifetime of issued tokens, which defaults to 24 hours
+	TokenLifetime time.Duration
+	// Clock determines the issue time of tokens, which defaults to restclient.SystemClock
+	Clock restclient.Clock
+	// ServiceCatalog is included in the responses of issued tokens
+	ServiceCatalog []restclient.CatalogService
+
+	mu           sync.Mutex
+	users        map[string]IdentityUser
+	tokens       map[string]*issuedToken
+	tokensIssued int
+	// mfaSessions maps the session id of a pending multi-factor authentication to the username
+	mfaSessions map[string]string
+}
+
+type issuedToken struct {
+	username string
+	expires  time.Time
+	revoked  bool
+	// impersonator is the username of the admin who obtained an impersonation token
+	impersonator string
+}
+
+// NewIdentityServer creates and starts an IdentityServer. The server's URL is the identityUrl
+// to use with the authenticator. The caller should call Close when finished.
+func NewIdentityServer() *IdentityServer {
+	s := &IdentityServer{
+		TokenLifetime: defaultTokenLifetime,
+		Clock:         restclient.SystemClock,
+		users:         make(map[string]IdentityUser),
+		tokens:        make(map[string]*issuedToken),
+		mfaSessions:   make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2.0/tokens", s.handleTokens)
+	mux.HandleFunc("/v2.0/tokens/", s.handleToken)
+	mux.HandleFunc("/v2.0/RAX-AUTH/impersonation-tokens", s.handleImpersonation)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// AddUser declares a user that can authenticate with the server
+func (s *IdentityServer) AddUser(user IdentityUser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.Username] = user
+}
+
+// RevokeToken revokes the given token, if it was issued by this server
+func (s *IdentityServer) RevokeToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tokens[token]; ok {
+		t.revoked = true
+	}
+}
+
+// RevokeUserTokens revokes all tokens issued to the given user
+func (s *IdentityServer) RevokeUserTokens(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tokens {
+		if t.username == username {
+			t.revoked = true
+		}
+	}
+}
+
+// TokensIssued returns the number of tokens issued by the server
+func (s *IdentityServer) TokensIssued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokensIssued
+}
+
+// ValidToken determines if the token was issued by this server and is neither expired nor revoked
+func (s *IdentityServer) ValidToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[token]
+	return ok && !t.revoked && s.Clock.Now().Before(t.expires)
+}
+
+// RequireToken wraps the handler of a protected test endpoint, responding with 401 Unauthorized
+// unless the request's x-auth-token header carries a valid token
+func (s *IdentityServer) RequireToken(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.ValidToken(r.Header.Get("x-auth-token")) {
+			writeIdentityFault(w, http.StatusUnauthorized, "unauthorized", "No valid token provided")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+type identityTokensReq struct {
+	Auth struct {
+		PasswordCredentials *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"passwordCredentials"`
+		ApikeyCredentials *struct {
+			Username string `json:"username"`
+			Apikey   string `json:"apiKey"`
+		} `json:"RAX-KSKEY:apiKeyCredentials"`
+		PasscodeCredentials *struct {
+			Passcode string `json:"passcode"`
+		} `json:"RAX-AUTH:passcodeCredentials"`
+	} `json:"auth"`
+}
+
+type identityRole struct {
+	Name string `json:"name"`
+}
+
+type identityTokensResp struct {
+	Access struct {
+		Token struct {
+			Id      string    `json:"id"`
+			Expires time.Time `json:"expires"`
+			Tenant  struct {
+				Id   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"tenant"`
+		} `json:"token"`
+		User struct {
+			Id    string         `json:"id"`
+			Name  string         `json:"name"`
+			Roles []identityRole `json:"roles"`
+		} `json:"user"`
+		ServiceCatalog []restclient.CatalogService `json:"serviceCatalog"`
+	} `json:"access"`
+}
+
+func (s *IdentityServer) handleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeIdentityFault(w, http.StatusMethodNotAllowed, "badRequest", "Method not allowed")
+		return
+	}
+
+	var req identityTokensReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeIdentityFault(w, http.StatusBadRequest, "badRequest", "Invalid json request body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var user IdentityUser
+	var ok bool
+	if creds := req.Auth.PasswordCredentials; creds != nil {
+		user, ok = s.users[creds.Username]
+		ok = ok && user.Password != "" && user.Password == creds.Password
+		if ok && user.Passcode != "" {
+			sessionId := newTokenId()
+			s.mfaSessions[sessionId] = user.Username
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("OS-MF sessionId='%s', factor='PASSCODE'", sessionId))
+			writeIdentityFault(w, http.StatusUnauthorized, "unauthorized",
+				"Additional authentication credentials required")
+			return
+		}
+	} else if creds := req.Auth.PasscodeCredentials; creds != nil {
+		sessionId := r.Header.Get("X-SessionId")
+		user, ok = s.users[s.mfaSessions[sessionId]]
+		ok = ok && user.Passcode == creds.Passcode
+		delete(s.mfaSessions, sessionId)
+	} else if creds := req.Auth.ApikeyCredentials; creds != nil {
+		user, ok = s.users[creds.Username]
+		ok = ok && user.Apikey != "" && user.Apikey == creds.Apikey
+	} else {
+		writeIdentityFault(w, http.StatusBadRequest, "badRequest", "Invalid request body: no credentials")
+		return
+	}
+	if !ok {
+		writeIdentityFault(w, http.StatusUnauthorized, "unauthorized",
+			"Error code: 'AUTH-004'; Username or api key/password is invalid.")
+		return
+	}
+
+	tokenId, token := s.issueToken(user.Username, s.TokenLifetime)
+	writeJson(w, http.StatusOK, s.tokenResponse(tokenId, token, user))
+}
+
+// handleToken handles requests for an individual token, where GET validates the token and DELETE
+// revokes the token. A token can be validated by itself or by an admin. A token can be revoked by
+// itself, by the admin that impersonated with it, or by an admin.
+func (s *IdentityServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	callerToken := r.Header.Get("x-auth-token")
+	if !s.ValidToken(callerToken) {
+		writeIdentityFault(w, http.StatusUnauthorized, "unauthorized", "No valid token provided")
+		return
+	}
+	tokenId := strings.TrimPrefix(r.URL.Path, "/v2.0/tokens/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenId]
+	if !ok {
+		writeIdentityFault(w, http.StatusNotFound, "itemNotFound", "Token not found.")
+		return
+	}
+	caller := s.users[s.tokens[callerToken].username]
+
+	switch r.Method {
+	case "GET":
+		if tokenId != callerToken && !hasAnyRole(caller, ImpersonatorRoles) {
+			writeIdentityFault(w, http.StatusForbidden, "forbidden", "Not Authorized")
+			return
+		}
+		if token.revoked || !s.Clock.Now().Before(token.expires) {
+			writeIdentityFault(w, http.StatusNotFound, "itemNotFound", "Token not found.")
+			return
+		}
+		writeJson(w, http.StatusOK, s.tokenResponse(tokenId, token, s.users[token.username]))
+	case "DELETE":
+		if tokenId != callerToken && token.impersonator != caller.Username && !hasAnyRole(caller, ImpersonatorRoles) {
+			writeIdentityFault(w, http.StatusForbidden, "forbidden", "Not Authorized")
+			return
+		}
+		token.revoked = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeIdentityFault(w, http.StatusMethodNotAllowed, "badRequest", "Method not allowed")
+	}
+}
+
+// ImpersonatorOf returns the username of the admin that obtained the given impersonation token,
+// or an empty string if the token is not an impersonation token
+func (s *IdentityServer) ImpersonatorOf(token string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tokens[token]; ok {
+		return t.impersonator
+	}
+	return ""
+}
+
+type identityImpersonationReq struct {
+	Impersonation struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		ExpireInSeconds int `json:"expire-in-seconds"`
+	} `json:"RAX-AUTH:impersonation"`
+}
+
+func (s *IdentityServer) handleImpersonation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeIdentityFault(w, http.StatusMethodNotAllowed, "badRequest", "Method not allowed")
+		return
+	}
+	if !s.ValidToken(r.Header.Get("x-auth-token")) {
+		writeIdentityFault(w, http.StatusUnauthorized, "unauthorized", "No valid token provided")
+		return
+	}
+
+	var req identityImpersonationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeIdentityFault(w, http.StatusBadRequest, "badRequest", "Invalid json request body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	admin := s.users[s.tokens[r.Header.Get("x-auth-token")].username]
+	if !hasAnyRole(admin, ImpersonatorRoles) {
+		writeIdentityFault(w, http.StatusForbidden, "forbidden", "Not Authorized")
+		return
+	}
+	user, ok := s.users[req.Impersonation.User.Username]
+	if !ok {
+		writeIdentityFault(w, http.StatusNotFound, "itemNotFound",
+			fmt.Sprintf("User '%s' not found.", req.Impersonation.User.Username))
+		return
+	}
+
+	lifetime := s.TokenLifetime
+	if req.Impersonation.ExpireInSeconds > 0 {
+		lifetime = time.Duration(req.Impersonation.ExpireInSeconds) * time.Second
+	}
+	tokenId, token := s.issueToken(user.Username, lifetime)
+	token.impersonator = admin.Username
+	writeJson(w, http.StatusOK, s.tokenResponse(tokenId, token, user))
+}
+
+func hasAnyRole(user IdentityUser, roles []string) bool {
+	for _, have := range user.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// issueToken must be called while holding the lock
+func (s *IdentityServer) issueToken(username string, lifetime time.Duration) (string, *issuedToken) {
+	token := &issuedToken{
+		username: username,
+		expires:  s.Clock.Now().Add(lifetime).UTC(),
+	}
+	tokenId := newTokenId()
+	s.tokens[tokenId] = token
+	s.tokensIssued++
+	return tokenId, token
+}
+
+func (s *IdentityServer) tokenResponse(tokenId string, token *issuedToken, user IdentityUser) *identityTokensResp {
+	var resp identityTokensResp
+	resp.Access.Token.Id = tokenId
+	resp.Access.Token.Expires = token.expires
+	resp.Access.Token.Tenant.Id = user.TenantId
+	resp.Access.Token.Tenant.Name = user.TenantId
+	resp.Access.User.Id = user.Username
+	resp.Access.User.Name = user.Username
+	resp.Access.User.Roles = []identityRole{}
+	for _, role := range user.Roles {
+		resp.Access.User.Roles = append(resp.Access.User.Roles, identityRole{Name: role})
+	}
+	resp.Access.ServiceCatalog = s.ServiceCatalog
+	if resp.Access.ServiceCatalog == nil {
+		resp.Access.ServiceCatalog = []restclient.CatalogService{}
+	}
+	return &resp
+}
+
+func writeIdentityFault(w http.ResponseWriter, status int, kind string, message string) {
+	writeJson(w, status, map[string]interface{}{
+		kind: map[string]interface{}{
+			"code":    status,
+			"message": message,
+		},
+	})
+}
+
+func writeJson(w http.ResponseWriter, status int, content interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(content)
+}
+
+func newTokenId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
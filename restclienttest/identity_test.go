@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleIdentityServer() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{
+		Username: "user1",
+		Apikey:   "key1",
+	})
+
+	clock := restclienttest.NewFakeClock(time.Now())
+	identity.Clock = clock
+	identity.TokenLifetime = time.Hour
+
+	// the service being called with the tokens
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secret stuff")
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "user1", "", "key1",
+		restclient.WithClock(clock))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(authenticator)
+
+	for i := 0; i < 2; i++ {
+		err = client.Exchange("GET", "/", nil, nil, nil)
+		fmt.Println(err, identity.TokensIssued())
+	}
+
+	// once the token has expired, a new one is obtained
+	clock.Advance(2 * time.Hour)
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err, identity.TokensIssued())
+	// Output:
+	// <nil> 1
+	// <nil> 1
+	// <nil> 2
+}
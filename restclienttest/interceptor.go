@@ -0,0 +1,163 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest
+
+import (
+	"bytes"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NextScript scripts the outcome of a call to the next callback given to an interceptor
+type NextScript struct {
+	// Delay is waited before producing the outcome, unless the request's context is done first
+	Delay time.Duration
+	// Err, if non-nil, is returned rather than a response
+	Err error
+	// StatusCode of the response, which defaults to 200
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// RecordedRequest is a snapshot of a request passed to the next callback, captured at the time
+// of the call so that mutations made by the interceptor can be asserted
+type RecordedRequest struct {
+	Method string
+	Url    string
+	Header http.Header
+	Body   []byte
+}
+
+// InterceptorResult captures the outcome of RunInterceptor
+type InterceptorResult struct {
+	// NextCalls holds the requests passed to the next callback, in order
+	NextCalls []RecordedRequest
+	// Response is the response returned by the interceptor
+	Response *http.Response
+	// Err is the error returned by the interceptor
+	Err error
+}
+
+// NewRequest creates a synthetic request for use with RunInterceptor. A non-empty body is
+// replayable via GetBody, as with requests prepared by restclient.Client.
+func NewRequest(method string, url string, body string) *http.Request {
+	var req *http.Request
+	var err error
+	if body != "" {
+		req, err = http.NewRequest(method, url, strings.NewReader(body))
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return req
+}
+
+// RunInterceptor invokes the interceptor with the request and a next callback that produces the
+// outcomes of the given scripts, one per call. When called more times than there are scripts, the
+// last script is repeated. With no scripts, next responds with an empty 200 OK.
+func RunInterceptor(interceptor restclient.Interceptor, req *http.Request, scripts ...NextScript) *InterceptorResult {
+	result := &InterceptorResult{}
+
+	next := func(req *http.Request) (*http.Response, error) {
+		call := len(result.NextCalls)
+		result.NextCalls = append(result.NextCalls, recordRequest(req))
+
+		script := NextScript{}
+		if len(scripts) > 0 {
+			if call < len(scripts) {
+				script = scripts[call]
+			} else {
+				script = scripts[len(scripts)-1]
+			}
+		}
+
+		if script.Delay > 0 {
+			timer := time.NewTimer(script.Delay)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+		if script.Err != nil {
+			return nil, script.Err
+		}
+		return scriptedResponse(req, script), nil
+	}
+
+	result.Response, result.Err = interceptor(req, next)
+	return result
+}
+
+// NextCalled determines if the interceptor invoked the next callback at least once
+func (r *InterceptorResult) NextCalled() bool {
+	return len(r.NextCalls) > 0
+}
+
+// LastCall returns the last request passed to the next callback or nil if it was never called
+func (r *InterceptorResult) LastCall() *RecordedRequest {
+	if len(r.NextCalls) == 0 {
+		return nil
+	}
+	return &r.NextCalls[len(r.NextCalls)-1]
+}
+
+func recordRequest(req *http.Request) RecordedRequest {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Url:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, _ := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		recorded.Body = body
+		// leave the body readable for any further processing of the request
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return recorded
+}
+
+func scriptedResponse(req *http.Request, script NextScript) *http.Response {
+	status := script.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := script.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(script.Body)),
+		ContentLength: int64(len(script.Body)),
+		Request:       req,
+	}
+}
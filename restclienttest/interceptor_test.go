@@ -0,0 +1,43 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclienttest_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"time"
+)
+
+func ExampleRunInterceptor() {
+	req := restclienttest.NewRequest("GET", "http://localhost/servers", "")
+
+	result := restclienttest.RunInterceptor(
+		restclient.Retry(restclient.RetryPolicy{InitialBackoff: time.Millisecond}),
+		req,
+		restclienttest.NextScript{StatusCode: 503},
+		restclienttest.NextScript{StatusCode: 200, Body: "ok"},
+	)
+
+	fmt.Println(result.Err, result.Response.StatusCode, len(result.NextCalls))
+
+	result = restclienttest.RunInterceptor(restclient.BasicAuth("user", "pass"), req)
+	fmt.Println(result.LastCall().Header.Get("Authorization"))
+	// Output:
+	// <nil> 200 2
+	// Basic dXNlcjpwYXNz
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Scenario is a sequence of expected requests and the scripted responses to them, which is
+// typically loaded from a YAML or JSON file with LoadScenario, such as
+//
+//	name: retries unavailable
+//	steps:
+//	  - request: {method: GET, path: /servers/123}
+//	    response: {status: 503, delay: 50ms}
+//	  - request: {method: GET, path: /servers/123}
+//	    response:
+//	      status: 200
+//	      body: {name: web}
+type Scenario struct {
+	Name  string         `yaml:"name"`
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep is a single exchange of a Scenario
+type ScenarioStep struct {
+	Request  ScenarioRequest  `yaml:"request"`
+	Response ScenarioResponse `yaml:"response"`
+}
+
+// ScenarioRequest declares the expected request of a step. Fields that are empty are not checked.
+type ScenarioRequest struct {
+	Method string `yaml:"method"`
+	Path   string `yaml:"path"`
+	// Query holds query parameters that must be present with the given values
+	Query map[string]string `yaml:"query"`
+	// Header holds headers that must be present with the given values
+	Header map[string]string `yaml:"header"`
+	// Body is the expected body, which is compared semantically when given as a structure and as
+	// text when given as a string
+	Body interface{} `yaml:"body"`
+}
+
+// ScenarioResponse scripts the response of a step
+type ScenarioResponse struct {
+	// Status defaults to 200
+	Status int               `yaml:"status"`
+	Header map[string]string `yaml:"header"`
+	// Body is sent as JSON when given as a structure and as is when given as a string
+	Body interface{} `yaml:"body"`
+	// Delay, such as "100ms", is waited before responding, unless the request is canceled first
+	Delay string `yaml:"delay"`
+	// Disconnect closes the connection without responding, which simulates a network failure
+	Disconnect bool `yaml:"disconnect"`
+}
+
+// LoadScenario reads a Scenario from a YAML or JSON file
+func LoadScenario(filename string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// ParseScenario parses a Scenario given in YAML or JSON form
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	// YAML is a superset of JSON
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	for i, step := range scenario.Steps {
+		if step.Response.Delay != "" {
+			if _, err := time.ParseDuration(step.Response.Delay); err != nil {
+				return nil, fmt.Errorf("invalid delay of step %d: %w", i+1, err)
+			}
+		}
+		if _, err := scenarioBody(step.Response.Body); err != nil {
+			return nil, fmt.Errorf("invalid response body of step %d: %w", i+1, err)
+		}
+	}
+	return &scenario, nil
+}
+
+// ScenarioServer is a test server that plays a Scenario, responding to each request with the next
+// step's response when the request matches the step's expectations. Requests that don't match, or
+// arrive after all steps have been played, are responded to with 500 Internal Server Error and
+// are reported by AssertComplete.
+type ScenarioServer struct {
+	*httptest.Server
+
+	scenario   *Scenario
+	mu         sync.Mutex
+	next       int
+	mismatches []string
+}
+
+// NewScenarioServer creates and starts a ScenarioServer. The caller should call Close when finished.
+func NewScenarioServer(scenario *Scenario) *ScenarioServer {
+	s := &ScenarioServer{scenario: scenario}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Played returns the number of steps that have been played
+func (s *ScenarioServer) Played() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+// AssertComplete reports a test error for each mismatched request and if any steps have not
+// been played
+func (s *ScenarioServer) AssertComplete(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, mismatch := range s.mismatches {
+		t.Errorf("scenario %q: %s", s.scenario.Name, mismatch)
+	}
+	if s.next < len(s.scenario.Steps) {
+		t.Errorf("scenario %q: played %d of %d steps", s.scenario.Name, s.next, len(s.scenario.Steps))
+	}
+}
+
+func (s *ScenarioServer) handle(w http.ResponseWriter, r *http.Request) {
+	recorded := recordRequest(r)
+
+	s.mu.Lock()
+	if s.next >= len(s.scenario.Steps) {
+		mismatch := fmt.Sprintf("unexpected request %s %s after the last step", r.Method, r.URL)
+		s.mismatches = append(s.mismatches, mismatch)
+		s.mu.Unlock()
+		http.Error(w, mismatch, http.StatusInternalServerError)
+		return
+	}
+	step := s.scenario.Steps[s.next]
+	if problem := step.Request.check(recorded, r); problem != "" {
+		mismatch := fmt.Sprintf("step %d: %s", s.next+1, problem)
+		s.mismatches = append(s.mismatches, mismatch)
+		s.mu.Unlock()
+		http.Error(w, mismatch, http.StatusInternalServerError)
+		return
+	}
+	s.next++
+	s.mu.Unlock()
+
+	resp := step.Response
+	if resp.Delay != "" {
+		delay, _ := time.ParseDuration(resp.Delay)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if resp.Disconnect {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				_ = conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	for name, value := range resp.Header {
+		w.Header().Set(name, value)
+	}
+	body, _ := scenarioBody(resp.Body)
+	if _, isText := resp.Body.(string); !isText && body != nil && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// check describes how the request differs from the expectations, if at all
+func (e ScenarioRequest) check(recorded RecordedRequest, r *http.Request) string {
+	if e.Method != "" && e.Method != r.Method {
+		return fmt.Sprintf("expected method %s, got %s %s", e.Method, r.Method, r.URL)
+	}
+	if e.Path != "" && e.Path != r.URL.Path {
+		return fmt.Sprintf("expected path %s, got %s %s", e.Path, r.Method, r.URL)
+	}
+	query := r.URL.Query()
+	for name, value := range e.Query {
+		if !containsValue(query[name], value) {
+			return fmt.Sprintf("expected query parameter %s=%s, got %s %s", name, value, r.Method, r.URL)
+		}
+	}
+	for name, value := range e.Header {
+		if !containsValue(r.Header[http.CanonicalHeaderKey(name)], value) {
+			return fmt.Sprintf("expected header %s: %s, got %q", name, value, r.Header.Get(name))
+		}
+	}
+	if e.Body != nil {
+		expected, _ := scenarioBody(e.Body)
+		if !sameBody(expected, recorded.Body) {
+			return fmt.Sprintf("expected body %s, got %s", expected, recorded.Body)
+		}
+	}
+	return ""
+}
+
+// scenarioBody encodes a body given as a structure as JSON and a string as is
+func scenarioBody(body interface{}) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(b), nil
+	default:
+		return json.Marshal(body)
+	}
+}
+
+// sameBody compares the bodies semantically when both are JSON and otherwise as text
+func sameBody(expected []byte, actual []byte) bool {
+	var expectedValue, actualValue interface{}
+	if json.Unmarshal(expected, &expectedValue) == nil && json.Unmarshal(actual, &actualValue) == nil {
+		expectedJson, _ := json.Marshal(expectedValue)
+		actualJson, _ := json.Marshal(actualValue)
+		return bytes.Equal(expectedJson, actualJson)
+	}
+	return bytes.Equal(bytes.TrimSpace(expected), bytes.TrimSpace(actual))
+}
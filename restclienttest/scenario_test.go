@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"testing"
+	"time"
+)
+
+func ExampleScenarioServer() {
+	scenario, err := restclienttest.ParseScenario([]byte(`
+name: retries unavailable
+steps:
+  - request: {method: POST, path: /servers, body: {name: web}}
+    response: {status: 503, delay: 10ms}
+  - request: {method: POST, path: /servers, body: {name: web}}
+    response:
+      status: 201
+      body: {id: "123"}
+`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := restclienttest.NewScenarioServer(scenario)
+	defer server.Close()
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{
+		InitialBackoff:     time.Millisecond,
+		RetryNonIdempotent: true,
+	}))
+
+	var out struct {
+		Id string `json:"id"`
+	}
+	err = client.Exchange("POST", "/servers", nil,
+		restclient.NewJsonEntity(map[string]string{"name": "web"}), restclient.NewJsonEntity(&out))
+	fmt.Println(err, out.Id, server.Played())
+	// Output:
+	// <nil> 123 2
+}
+
+func TestScenarioServer_disconnect(t *testing.T) {
+	scenario, err := restclienttest.ParseScenario([]byte(`{
+		"name": "network failure",
+		"steps": [
+			{"request": {"method": "GET", "path": "/servers"}, "response": {"disconnect": true}},
+			{"request": {"method": "GET", "path": "/servers"}, "response": {"body": "[]"}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := restclienttest.NewScenarioServer(scenario)
+	defer server.Close()
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(server.URL)
+	if err := client.Exchange("GET", "/servers", nil, nil, nil); err == nil {
+		t.Error("expected the disconnect to fail the request")
+	}
+	if err := client.Exchange("GET", "/servers", nil, nil, nil); err != nil {
+		t.Error(err)
+	}
+	server.AssertComplete(t)
+
+	// requests beyond the last step are rejected
+	err = client.Exchange("GET", "/servers", nil, nil, nil)
+	if failed, ok := err.(*restclient.FailedResponseError); !ok || failed.StatusCode != 500 {
+		t.Errorf("expected the extra request to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// StubServer is a test server whose responses are declared with stubs, which is less verbose than
+// writing httptest handlers for each test case, such as
+//
+//	server := restclienttest.NewStubServer()
+//	defer server.Close()
+//	servers := server.When("GET", "/servers/*").WithQuery("details", "true").
+//		Reply(200).JSON(map[string]string{"name": "web"})
+//	...
+//	servers.AssertCalls(t, 1)
+//
+// Each request is served by the first stub, in the order declared, that matches the request and
+// has not reached its limit of Times. Requests without a matching stub are responded to with
+// 501 Not Implemented and can be retrieved with Unmatched.
+type StubServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	stubs     []*Stub
+	unmatched []RecordedRequest
+}
+
+// Stub declares the requests it matches and the response to them. The methods of a Stub return
+// it to allow for chaining.
+type Stub struct {
+	server  *StubServer
+	method  string
+	pattern string
+	query   map[string]string
+	headers map[string]string
+
+	status     int
+	respHeader http.Header
+	body       []byte
+	limit      int
+
+	requests []RecordedRequest
+}
+
+// NewStubServer creates and starts a StubServer. The caller should call Close when finished.
+func NewStubServer() *StubServer {
+	s := &StubServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// When declares a stub for requests with the given method and a path matching pathPattern, as
+// by path.Match, where "*" matches a single path segment. The stub responds with 200 OK and no
+// body until its response is declared with Reply.
+func (s *StubServer) When(method string, pathPattern string) *Stub {
+	if _, err := path.Match(pathPattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid path pattern %q: %v", pathPattern, err))
+	}
+	stub := &Stub{
+		server:     s,
+		method:     strings.ToUpper(method),
+		pattern:    pathPattern,
+		query:      make(map[string]string),
+		headers:    make(map[string]string),
+		status:     http.StatusOK,
+		respHeader: make(http.Header),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs = append(s.stubs, stub)
+	return stub
+}
+
+// Unmatched returns the requests that did not match any stub
+func (s *StubServer) Unmatched() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest{}, s.unmatched...)
+}
+
+// AssertAllCalled reports a test error for each stub that has not been called and for each request
+// that did not match any stub
+func (s *StubServer) AssertAllCalled(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stub := range s.stubs {
+		if len(stub.requests) == 0 {
+			t.Errorf("stub %s was not called", stub)
+		}
+	}
+	for _, req := range s.unmatched {
+		t.Errorf("request %s %s did not match any stub", req.Method, req.Url)
+	}
+}
+
+func (s *StubServer) handle(w http.ResponseWriter, r *http.Request) {
+	recorded := recordRequest(r)
+
+	s.mu.Lock()
+	var matched *Stub
+	for _, stub := range s.stubs {
+		if stub.matches(r) {
+			matched = stub
+			break
+		}
+	}
+	if matched == nil {
+		s.unmatched = append(s.unmatched, recorded)
+		s.mu.Unlock()
+		http.Error(w, fmt.Sprintf("no stub matches %s %s", r.Method, r.URL), http.StatusNotImplemented)
+		return
+	}
+	matched.requests = append(matched.requests, recorded)
+	status, header, body := matched.status, matched.respHeader, matched.body
+	s.mu.Unlock()
+
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// WithQuery restricts the stub to requests having the query parameter with the given value
+func (st *Stub) WithQuery(name string, value string) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.query[name] = value
+	return st
+}
+
+// WithHeader restricts the stub to requests having the header with the given value
+func (st *Stub) WithHeader(name string, value string) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.headers[name] = value
+	return st
+}
+
+// Times limits the stub to matching the first n requests, after which later stubs may match. This
+// allows for declaring a sequence of responses, such as a failure followed by a success.
+func (st *Stub) Times(n int) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.limit = n
+	return st
+}
+
+// Reply sets the status code of the response
+func (st *Stub) Reply(status int) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.status = status
+	return st
+}
+
+// Header sets a header of the response
+func (st *Stub) Header(name string, value string) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.respHeader.Set(name, value)
+	return st
+}
+
+// JSON sets the body of the response to the JSON encoding of body, along with the Content-Type.
+// A string or []byte body is used as is.
+func (st *Stub) JSON(body interface{}) *Stub {
+	var content []byte
+	switch v := body.(type) {
+	case string:
+		content = []byte(v)
+	case []byte:
+		content = v
+	default:
+		var err error
+		content, err = json.Marshal(body)
+		if err != nil {
+			panic(fmt.Sprintf("failed to encode stub body: %v", err))
+		}
+	}
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.respHeader.Set("Content-Type", "application/json")
+	st.body = content
+	return st
+}
+
+// Text sets the body of the response to the given text, along with the Content-Type
+func (st *Stub) Text(body string) *Stub {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	st.respHeader.Set("Content-Type", "text/plain")
+	st.body = []byte(body)
+	return st
+}
+
+// Calls returns the number of requests served by the stub
+func (st *Stub) Calls() int {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	return len(st.requests)
+}
+
+// Requests returns the requests served by the stub, in order
+func (st *Stub) Requests() []RecordedRequest {
+	st.server.mu.Lock()
+	defer st.server.mu.Unlock()
+	return append([]RecordedRequest{}, st.requests...)
+}
+
+// AssertCalls reports a test error if the stub has not served exactly n requests
+func (st *Stub) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	if calls := st.Calls(); calls != n {
+		t.Errorf("expected stub %s to be called %d times, was called %d times", st, n, calls)
+	}
+}
+
+// String describes the requests matched by the stub, such as "GET /servers/*?details=true"
+func (st *Stub) String() string {
+	var query []string
+	for name, value := range st.query {
+		query = append(query, name+"="+value)
+	}
+	description := st.method + " " + st.pattern
+	if len(query) > 0 {
+		sort.Strings(query)
+		description += "?" + strings.Join(query, "&")
+	}
+	return description
+}
+
+// matches determines if the stub serves the request, given the server's lock is held
+func (st *Stub) matches(r *http.Request) bool {
+	if st.limit > 0 && len(st.requests) >= st.limit {
+		return false
+	}
+	if st.method != r.Method {
+		return false
+	}
+	if matched, _ := path.Match(st.pattern, r.URL.Path); !matched {
+		return false
+	}
+	query := r.URL.Query()
+	for name, value := range st.query {
+		if !containsValue(query[name], value) {
+			return false
+		}
+	}
+	for name, value := range st.headers {
+		if !containsValue(r.Header[http.CanonicalHeaderKey(name)], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclienttest_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"testing"
+	"time"
+)
+
+func ExampleStubServer() {
+	server := restclienttest.NewStubServer()
+	defer server.Close()
+
+	// the first request fails and the retry succeeds
+	server.When("GET", "/servers/*").WithQuery("details", "true").Times(1).
+		Reply(503)
+	servers := server.When("GET", "/servers/*").WithQuery("details", "true").
+		Reply(200).JSON(map[string]string{"name": "web"})
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{InitialBackoff: time.Millisecond}))
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	err := client.Exchange("GET", "/servers/123", map[string][]string{"details": {"true"}},
+		nil, restclient.NewJsonEntity(&out))
+	fmt.Println(err, out.Name, servers.Calls())
+
+	// requests that don't match any stub are reported
+	err = client.Exchange("GET", "/servers/123", nil, nil, nil)
+	fmt.Println(err != nil, len(server.Unmatched()))
+	// Output:
+	// <nil> web 1
+	// true 1
+}
+
+func TestStubServer_tableDriven(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{name: "found", status: 200, expected: "<nil>"},
+		{name: "missing", status: 404, expected: "404 Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := restclienttest.NewStubServer()
+			defer server.Close()
+			stub := server.When("DELETE", "/servers/123").WithHeader("X-Auth-Token", "token1").
+				Reply(tt.status)
+
+			client := restclient.NewClient()
+			client.SetBaseUrl(server.URL)
+			client.AddInterceptor(restclient.TokenAuth(restclient.AuthTokenHeader, "token1"))
+			err := client.Exchange("DELETE", "/servers/123", nil, nil, nil)
+
+			actual := fmt.Sprint(err)
+			if err != nil {
+				actual = err.(*restclient.FailedResponseError).Status
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+			stub.AssertCalls(t, 1)
+			server.AssertAllCalled(t)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrDeadlineWouldExceed is returned by the Retry interceptor when the backoff before the next attempt
+// would exceed the deadline of the request's context. Rather than sleeping through the remaining time
+// only to fail, the retries are abandoned early.
+var ErrDeadlineWouldExceed = errors.New("retry backoff would exceed the request deadline")
+
+// RetryPolicy configures the Retry interceptor. Zero values are replaced with the defaults noted
+// on each field.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first, which defaults to 3
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, which defaults to 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, which defaults to 10s
+	MaxBackoff time.Duration
+	// Multiplier increases the backoff after each retry, which defaults to 2
+	Multiplier float64
+	// RetryNonIdempotent enables retries of methods other than GET, HEAD, OPTIONS, TRACE, PUT, and DELETE
+	RetryNonIdempotent bool
+	// ShouldRetry decides if an attempt should be retried given either its response or error. The default
+	// retries errors sending the request and the statuses 429, 502, 503, and 504.
+	ShouldRetry func(resp *http.Response, err error) bool
+	// Clock is used for the backoff delays and deadline budgeting, which defaults to SystemClock
+	Clock Clock
+	// OnRetry, if set, is called after an attempt has been deemed retryable and before the backoff.
+	// It is not called when the retry is abandoned with ErrDeadlineWouldExceed.
+	OnRetry func(event RetryEvent)
+	// OnBackoff, if set, is called after the backoff has elapsed and just before the next attempt
+	OnBackoff func(event RetryEvent)
+}
+
+// RetryEvent conveys the state of a retry to the observer hooks of RetryPolicy, such as for logging
+// and metering retry behavior or detecting retry storms
+type RetryEvent struct {
+	Request *http.Request
+	// Attempt is the number of the attempt that failed, starting from 1
+	Attempt int
+	// StatusCode is the status of the failed attempt or zero if Err is set
+	StatusCode int
+	// Err is the error sending the failed attempt, if any
+	Err error
+	// Delay is the computed backoff before the next attempt
+	Delay time.Duration
+}
+
+// Retry creates an Interceptor that retries failed attempts with exponential backoff.
+// A Retry-After header, given either in seconds or as an HTTP date, is used as the backoff, when
+// present. Either way, the backoff is capped by MaxBackoff.
+//
+// The request's context deadline, such as the client's Timeout, bounds the retries: when the backoff
+// would exceed the deadline, ErrDeadlineWouldExceed is returned immediately.
+//
+// Requests with a body are only retried when the body can be replayed via the request's GetBody.
+// That is the case for encoded content, strings, byte slices, and io.Reader content that is a
+// bytes.Reader, bytes.Buffer, strings.Reader, or, when the entity's ContentLength is not given, an
+// io.ReadSeeker, such as an os.File. Other io.Reader content and ContentWriter content is only
+// attempted once, unless an earlier interceptor buffered it, such as with ReadRequestBody.
+func Retry(policy RetryPolicy) Interceptor {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	if policy.ShouldRetry == nil {
+		policy.ShouldRetry = defaultShouldRetry
+	}
+	if policy.Clock == nil {
+		policy.Clock = SystemClock
+	}
+	clock := policy.Clock
+
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		maxAttempts := policy.MaxAttempts
+		if !policy.RetryNonIdempotent && !isIdempotent(req.Method) {
+			maxAttempts = 1
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			maxAttempts = 1
+		}
+
+		backoff := policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if attempt >= maxAttempts || !policy.ShouldRetry(resp, err) {
+				return resp, err
+			}
+
+			delay := backoff
+			if resp != nil {
+				if retryAfter, ok := parseRetryAfter(resp.Header, clock.Now()); ok {
+					delay = retryAfter
+				}
+			}
+			if delay > policy.MaxBackoff {
+				delay = policy.MaxBackoff
+			}
+
+			event := RetryEvent{
+				Request: req,
+				Attempt: attempt,
+				Err:     err,
+				Delay:   delay,
+			}
+			if resp != nil {
+				event.StatusCode = resp.StatusCode
+			}
+			lastOutcome := describeAttempt(resp, err)
+			if resp != nil {
+				discardBody(resp)
+			}
+
+			if deadline, ok := req.Context().Deadline(); ok && clock.Now().Add(delay).After(deadline) {
+				return nil, fmt.Errorf("%w after attempt %d: %s", ErrDeadlineWouldExceed, attempt, lastOutcome)
+			}
+
+			if policy.OnRetry != nil {
+				policy.OnRetry(event)
+			}
+			if stats := statsFrom(req.Context()); stats != nil {
+				stats.add(&stats.retries)
+			}
+
+			wait, stopWait := startTimer(clock, delay)
+			select {
+			case <-wait:
+			case <-req.Context().Done():
+				stopWait()
+				return nil, req.Context().Err()
+			}
+
+			if policy.OnBackoff != nil {
+				policy.OnBackoff(event)
+			}
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to replay request body: %w", err)
+				}
+				req.Body = body
+			}
+
+			backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		}
+	}
+}
+
+func defaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// no point in retrying when the request's context is already done
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter supports the delay-seconds and HTTP-date forms of the Retry-After header
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		delay := at.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func describeAttempt(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// discardBody drains and closes the body of a response that won't be processed, which allows
+// the connection to be reused
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, errorMessageLimit))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleRetry() {
+	// Setup a test HTTP server
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fmt.Printf("ATTEMPT %d\n", attempts)
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"Msg":"finally"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+	}))
+
+	type MsgHolder struct {
+		Msg string
+	}
+	var resp MsgHolder
+
+	err := client.Exchange("GET", "/flaky", nil, nil, restclient.NewJsonEntity(&resp))
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(resp.Msg)
+	// Output:
+	// ATTEMPT 1
+	// ATTEMPT 2
+	// ATTEMPT 3
+	// finally
+}
+
+func ExampleRetry_deadlineBudget() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.Timeout = time.Second
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{
+		// isn't called, since the retry is abandoned
+		OnRetry: func(event restclient.RetryEvent) {
+			fmt.Println("RETRY", event.Attempt)
+		},
+	}))
+
+	started := time.Now()
+	err := client.Exchange("GET", "/unavailable", nil, nil, nil)
+
+	fmt.Println(errors.Is(err, restclient.ErrDeadlineWouldExceed))
+	fmt.Println(time.Since(started) < time.Second)
+	fmt.Println(err)
+	// Output:
+	// true
+	// true
+	// failed to send request: retry backoff would exceed the request deadline after attempt 1: 503 Service Unavailable
+}
+
+func ExampleRetryPolicy_hooks() {
+	// Setup a test HTTP server
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		OnRetry: func(event restclient.RetryEvent) {
+			fmt.Printf("attempt %d got %d, retrying %s in %s\n",
+				event.Attempt, event.StatusCode, event.Request.URL.Path, event.Delay)
+		},
+	}))
+
+	err := client.Exchange("GET", "/gateway", nil, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// attempt 1 got 502, retrying /gateway in 10ms
+	// attempt 2 got 502, retrying /gateway in 20ms
+}
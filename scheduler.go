@@ -0,0 +1,233 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Priority conveys the relative importance of an exchange to a PriorityScheduler.
+// Higher values are scheduled first.
+type Priority int
+
+const (
+	PriorityBackground  Priority = -10
+	PriorityNormal      Priority = 0
+	PriorityInteractive Priority = 10
+)
+
+type priorityContextKey struct{}
+
+// WithPriority returns a context, for use with ExchangeWithContext, that conveys the priority of the
+// exchange to a PriorityScheduler. Exchanges without a priority are PriorityNormal.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, priority)
+}
+
+// PriorityFromContext returns the priority conveyed by the context or PriorityNormal if none
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityContextKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// PriorityScheduler creates an Interceptor that allows at most maxConcurrent requests to be in flight.
+// When that limit is reached, additional requests wait and are admitted in order of their priority,
+// given by WithPriority, rather than their arrival. Requests of equal priority are admitted in the
+// order they arrived.
+//
+// A request occupies its slot until its response body has been closed, which Exchange does
+// automatically. A waiting request is abandoned when its context is done.
+func PriorityScheduler(maxConcurrent int) Interceptor {
+	scheduler := newRequestScheduler(maxConcurrent)
+
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		if err := scheduler.acquire(req.Context(), PriorityFromContext(req.Context())); err != nil {
+			return nil, err
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			scheduler.release()
+			return nil, err
+		}
+		resp.Body = &releasingBody{ReadCloser: resp.Body, release: scheduler.release}
+		return resp, nil
+	}
+}
+
+// ErrTooManyRequests is returned by an exchange when the client's MaxConcurrentRequests has been
+// reached and FailWhenBusy is enabled
+var ErrTooManyRequests = errors.New("too many concurrent requests")
+
+// acquireRequestSlot enforces MaxConcurrentRequests and returns the function to call when the
+// exchange has completed
+func (c *Client) acquireRequestSlot(ctx context.Context) (func(), error) {
+	if c.MaxConcurrentRequests <= 0 {
+		return func() {}, nil
+	}
+
+	c.schedulerMu.Lock()
+	if c.scheduler == nil || c.scheduler.limit != c.MaxConcurrentRequests {
+		c.scheduler = newRequestScheduler(c.MaxConcurrentRequests)
+	}
+	scheduler := c.scheduler
+	c.schedulerMu.Unlock()
+
+	if c.FailWhenBusy {
+		if !scheduler.tryAcquire() {
+			return nil, ErrTooManyRequests
+		}
+	} else if err := scheduler.acquire(ctx, PriorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	return scheduler.release, nil
+}
+
+// requestScheduler is a counting semaphore whose waiters are admitted by priority
+type requestScheduler struct {
+	limit int
+
+	mu       sync.Mutex
+	inFlight int
+	waiters  waiterQueue
+	seq      uint64
+}
+
+func newRequestScheduler(limit int) *requestScheduler {
+	if limit < 1 {
+		limit = 1
+	}
+	return &requestScheduler{limit: limit}
+}
+
+// tryAcquire takes a slot, if one is available without waiting
+func (s *requestScheduler) tryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight < s.limit && len(s.waiters) == 0 {
+		s.inFlight++
+		return true
+	}
+	return false
+}
+
+// acquire takes a slot, waiting until one is granted or the context is done
+func (s *requestScheduler) acquire(ctx context.Context, priority Priority) error {
+	s.mu.Lock()
+	if s.inFlight < s.limit && len(s.waiters) == 0 {
+		s.inFlight++
+		s.mu.Unlock()
+		return nil
+	}
+	s.seq++
+	w := &waiter{priority: priority, seq: s.seq, granted: make(chan struct{})}
+	heap.Push(&s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.index >= 0 {
+			heap.Remove(&s.waiters, w.index)
+			return ctx.Err()
+		}
+		// granted concurrently with the context being done, so pass along the slot
+		s.releaseLocked()
+		return ctx.Err()
+	}
+}
+
+func (s *requestScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+func (s *requestScheduler) releaseLocked() {
+	if len(s.waiters) > 0 {
+		// hand the slot directly to the next waiter
+		w := heap.Pop(&s.waiters).(*waiter)
+		close(w.granted)
+	} else {
+		s.inFlight--
+	}
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	granted  chan struct{}
+	// index is the position in the waiterQueue or -1 once removed
+	index int
+}
+
+// waiterQueue implements heap.Interface ordered by highest priority and then earliest arrival
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// releasingBody invokes release once when the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+func ExamplePriorityScheduler() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s\n", r.URL.Path)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.PriorityScheduler(1))
+
+	var wg sync.WaitGroup
+	exchange := func(path string, priority restclient.Priority) {
+		defer wg.Done()
+		ctx := restclient.WithPriority(context.Background(), priority)
+		if err := client.ExchangeWithContext(ctx, "GET", path, nil, nil, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// occupy the only slot and then queue up a bulk call followed by an interactive one
+	wg.Add(3)
+	go exchange("/first", restclient.PriorityNormal)
+	time.Sleep(10 * time.Millisecond)
+	go exchange("/bulk", restclient.PriorityBackground)
+	time.Sleep(10 * time.Millisecond)
+	go exchange("/interactive", restclient.PriorityInteractive)
+	wg.Wait()
+
+	// Output:
+	// RECV /first
+	// RECV /interactive
+	// RECV /bulk
+}
+
+func ExampleClient_maxConcurrentRequests() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.MaxConcurrentRequests = 1
+	client.FailWhenBusy = true
+
+	go client.Exchange("GET", "/slow", nil, nil, nil)
+	time.Sleep(20 * time.Millisecond)
+
+	err := client.Exchange("GET", "/another", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// too many concurrent requests
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ScopedToBaseUrl wraps an interceptor, typically one that injects credentials, so that it only
+// applies to requests whose host is that of the client's BaseUrl. Requests with absolute URLs for
+// other hosts skip the interceptor, which prevents leaking credentials to third parties. If a request
+// is redirected to another host, the headers set by the interceptor are removed from the redirected
+// request.
+//
+//	client.AddInterceptor(client.ScopedToBaseUrl(authenticator))
+func (c *Client) ScopedToBaseUrl(interceptor Interceptor) Interceptor {
+	return scopedInterceptor(func(u *url.URL) bool {
+		return c.BaseUrl != nil && sameHost(u, c.BaseUrl)
+	}, interceptor)
+}
+
+// ScopedToHost is the same as Client.ScopedToBaseUrl, but scopes the interceptor to the host of
+// the given URL
+func ScopedToHost(rawurl string, interceptor Interceptor) (Interceptor, error) {
+	scope, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return scopedInterceptor(func(u *url.URL) bool {
+		return sameHost(u, scope)
+	}, interceptor), nil
+}
+
+type scopedHeadersKey struct{}
+
+// scopedHeaders are the names of headers that are only to be sent to the host of the url
+type scopedHeaders struct {
+	url   *url.URL
+	names []string
+}
+
+func scopedInterceptor(inScope func(u *url.URL) bool, interceptor Interceptor) Interceptor {
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		if !inScope(req.URL) {
+			return next(req)
+		}
+		before := req.Header.Clone()
+		return interceptor(req, func(newReq *http.Request) (*http.Response, error) {
+			names := changedHeaders(before, newReq.Header)
+			if len(names) > 0 {
+				scopes, _ := newReq.Context().Value(scopedHeadersKey{}).([]scopedHeaders)
+				scopes = append(scopes[:len(scopes):len(scopes)], scopedHeaders{url: newReq.URL, names: names})
+				newReq = newReq.WithContext(context.WithValue(newReq.Context(), scopedHeadersKey{}, scopes))
+			}
+			return next(newReq)
+		})
+	}
+}
+
+func changedHeaders(before http.Header, after http.Header) []string {
+	var names []string
+	for name, values := range after {
+		if strings.Join(before[name], "\n") != strings.Join(values, "\n") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// sendRequest sends the request with the client's http.Client, where redirects are also checked
+// against the client's allowed URLs and redirects to other hosts remove any scoped headers
+func (c *Client) sendRequest(req *http.Request) (*http.Response, error) {
+	// interceptors may have replaced the URL
+	if err := c.checkUrlAllowed(req.URL); err != nil {
+		return nil, err
+	}
+	if err := c.checkMutationAllowed(req.Method, req.URL); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicy(req.Method, req.URL); err != nil {
+		return nil, err
+	}
+	httpClient := c.httpClient()
+	scopes, _ := req.Context().Value(scopedHeadersKey{}).([]scopedHeaders)
+	if len(scopes) > 0 || c.restrictsUrls() {
+		httpClient = c.checkedHttpClient(httpClient, scopes)
+	}
+	if c.DisableRedirects {
+		httpClient = withoutRedirects(httpClient)
+	}
+	resp, err := httpClient.Do(req)
+	if err == nil {
+		captureUpgrade(req, resp)
+	}
+	return resp, err
+}
+
+// checkedHttpClient returns a copy of httpClient whose redirects are checked against the allowed URLs
+// and remove scoped headers
+func (c *Client) checkedHttpClient(httpClient *http.Client, scopes []scopedHeaders) *http.Client {
+	checkedClient := *httpClient
+	checkRedirect := httpClient.CheckRedirect
+	checkedClient.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+		if err := c.checkUrlAllowed(redirect.URL); err != nil {
+			return err
+		}
+		for _, scope := range scopes {
+			if !sameHost(redirect.URL, scope.url) {
+				for _, name := range scope.names {
+					redirect.Header.Del(name)
+				}
+			}
+		}
+		if checkRedirect != nil {
+			return checkRedirect(redirect, via)
+		}
+		// the default policy of http.Client
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &checkedClient
+}
+
+func sameHost(a *url.URL, b *url.URL) bool {
+	return strings.EqualFold(a.Hostname(), b.Hostname()) && effectivePort(a) == effectivePort(b)
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleClient_ScopedToBaseUrl() {
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV third party token=%q\n", r.Header.Get("X-Auth-Token"))
+	}))
+	defer thirdParty.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV %s token=%q\n", r.URL.Path, r.Header.Get("X-Auth-Token"))
+		if r.URL.Path == "/download" {
+			http.Redirect(w, r, thirdParty.URL+"/object", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(client.ScopedToBaseUrl(restclient.TokenAuth(restclient.AuthTokenHeader, "token1")))
+
+	fmt.Println(client.Exchange("GET", "/servers", nil, nil, nil))
+	fmt.Println(client.Exchange("GET", "/download", nil, nil, nil))
+	fmt.Println(client.Exchange("GET", thirdParty.URL+"/other", nil, nil, nil))
+	// Output:
+	// RECV /servers token="token1"
+	// <nil>
+	// RECV /download token="token1"
+	// RECV third party token=""
+	// <nil>
+	// RECV third party token=""
+	// <nil>
+}
+
+func ExampleScopedToHost() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("RECV token=%q\n", r.Header.Get("X-Auth-Token"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	scoped, err := restclient.ScopedToHost("https://identity.example.com",
+		restclient.TokenAuth(restclient.AuthTokenHeader, "token1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(scoped)
+
+	fmt.Println(client.Exchange("GET", "/", nil, nil, nil))
+	// Output:
+	// RECV token=""
+	// <nil>
+}
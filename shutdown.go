@@ -0,0 +1,81 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientShutdown is returned by exchanges started after the client's Shutdown
+var ErrClientShutdown = errors.New("client is shut down")
+
+// Shutdown gracefully stops the client, such as for a clean restart of a service. Exchanges
+// started afterwards fail with ErrClientShutdown while those in flight, including the reading of
+// their response bodies, are given until ctx is done to finish. The idle connections of the
+// client's HttpClient are then closed. If ctx ends before the in-flight exchanges finish, then
+// Shutdown returns the context's error, which leaves those exchanges to complete on their own.
+//
+// Only the handshake of DialWebSocket is tracked, so an established WebSocketConn must be closed
+// by its owner.
+// Calling Shutdown again waits for the remaining exchanges.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.lifecycleMu.Lock()
+	c.shutdown = true
+	var drained chan struct{}
+	if c.inFlight > 0 {
+		if c.drained == nil {
+			c.drained = make(chan struct{})
+		}
+		drained = c.drained
+	}
+	c.lifecycleMu.Unlock()
+
+	var err error
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	c.httpClient().CloseIdleConnections()
+	return err
+}
+
+// beginExchange tracks an exchange as in flight unless the client has been shut down
+func (c *Client) beginExchange() error {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	if c.shutdown {
+		return ErrClientShutdown
+	}
+	c.inFlight++
+	return nil
+}
+
+// endExchange completes an exchange started by beginExchange and releases a waiting Shutdown
+// once none remain in flight
+func (c *Client) endExchange() {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	c.inFlight--
+	if c.inFlight == 0 && c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+}
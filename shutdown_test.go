@@ -0,0 +1,98 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func ExampleClient_Shutdown() {
+	// Setup a test HTTP server that is slow to respond
+	started := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "done")
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	result := make(chan string)
+	go func() {
+		var content strings.Builder
+		err := client.Exchange("GET", "/report", nil, nil, &restclient.Entity{Content: &content})
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		result <- content.String()
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := client.Shutdown(ctx)
+	fmt.Println(err)
+	// the in-flight exchange completed before Shutdown returned
+	fmt.Println(<-result)
+
+	err = client.Exchange("GET", "/report", nil, nil, nil)
+	fmt.Println(errors.Is(err, restclient.ErrClientShutdown))
+	// Output:
+	// <nil>
+	// done
+	// true
+}
+
+func ExampleClient_Shutdown_expired() {
+	// Setup a test HTTP server that is slow to respond
+	started := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	go func() {
+		_ = client.Exchange("GET", "/report", nil, nil, nil)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := client.Shutdown(ctx)
+	fmt.Println(err)
+	// Output:
+	// context deadline exceeded
+}
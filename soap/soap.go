@@ -0,0 +1,235 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package soap calls SOAP 1.1 and 1.2 services with a restclient.Client, so that the client's
+transport, authentication, and other interceptors apply to SOAP calls as they do to REST calls.
+
+Requests and responses are the contents of the SOAP body, which are encoded with encoding/xml:
+
+	type GetQuote struct {
+		XMLName xml.Name `xml:"http://example.com/stock GetQuote"`
+		Symbol  string
+	}
+	type GetQuoteResponse struct {
+		Price float64
+	}
+
+	caller := soap.New(client)
+	var resp GetQuoteResponse
+	err := caller.Call(ctx, "/StockService", "http://example.com/stock/GetQuote",
+		&GetQuote{Symbol: "RAX"}, &resp)
+
+A SOAP fault is returned as a *Fault.
+*/
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+)
+
+// Version is the version of the SOAP protocol
+type Version int
+
+const (
+	V11 Version = iota
+	V12
+)
+
+const (
+	// Soap11Type is the content type of SOAP 1.1 messages
+	Soap11Type restclient.MimeType = "text/xml"
+	// Soap12Type is the content type of SOAP 1.2 messages
+	Soap12Type restclient.MimeType = "application/soap+xml"
+
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Client calls the operations of SOAP services
+type Client struct {
+	restClient *restclient.Client
+	version    Version
+}
+
+// Option customizes the Client created by New
+type Option func(c *Client)
+
+// WithVersion sets the version of SOAP, which defaults to V11
+func WithVersion(version Version) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// New creates a Client that sends its calls with the given restclient.Client
+func New(restClient *restclient.Client, opts ...Option) *Client {
+	c := &Client{restClient: restClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Fault is the error returned when the service responded with a SOAP fault
+type Fault struct {
+	// Code is the faultcode of SOAP 1.1 or the Code value of SOAP 1.2, such as soap:Server
+	Code string
+	// Reason is the faultstring of SOAP 1.1 or the Reason text of SOAP 1.2
+	Reason string
+	// Actor is the faultactor of SOAP 1.1 or the Role of SOAP 1.2
+	Actor string
+	// Detail is the raw XML content of the fault's detail, which may be decoded with xml.Unmarshal
+	Detail []byte
+	// Response is the failed response that conveyed the fault, if the fault was not given with a
+	// successful status
+	Response *restclient.FailedResponseError
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("SOAP fault %s: %s", f.Code, f.Reason)
+}
+
+func (f *Fault) Unwrap() error {
+	if f.Response == nil {
+		return nil
+	}
+	return f.Response
+}
+
+type requestEnvelope struct {
+	XMLName   xml.Name `xml:"soap:Envelope"`
+	Namespace string   `xml:"xmlns:soap,attr"`
+	Body      struct {
+		Content interface{}
+	} `xml:"soap:Body"`
+}
+
+type responseEnvelope struct {
+	Body struct {
+		Fault   *rawFault `xml:"Fault"`
+		Content []byte    `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+// rawFault decodes the fault of either version
+type rawFault struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	FaultActor  string `xml:"faultactor"`
+	CodeValue   string `xml:"Code>Value"`
+	ReasonText  string `xml:"Reason>Text"`
+	Role        string `xml:"Role"`
+	Detail11    struct {
+		Content []byte `xml:",innerxml"`
+	} `xml:"detail"`
+	Detail12 struct {
+		Content []byte `xml:",innerxml"`
+	} `xml:"Detail"`
+}
+
+func (r *rawFault) fault() *Fault {
+	f := &Fault{
+		Code:   firstNonEmpty(r.FaultCode, r.CodeValue),
+		Reason: firstNonEmpty(r.FaultString, r.ReasonText),
+		Actor:  firstNonEmpty(r.FaultActor, r.Role),
+		Detail: r.Detail11.Content,
+	}
+	if len(f.Detail) == 0 {
+		f.Detail = r.Detail12.Content
+	}
+	return f
+}
+
+// Call invokes the operation at urlIn, which is resolved like that of restclient.Client.Exchange.
+// The action is given as the SOAPAction of SOAP 1.1 or the action parameter of SOAP 1.2. The
+// request is encoded as the content of the SOAP body and the content of the response's SOAP body
+// is decoded into response, if non-nil. The options apply to the underlying exchange.
+func (c *Client) Call(ctx context.Context, urlIn string, action string, request interface{}, response interface{},
+	opts ...restclient.RequestOption) error {
+
+	envelope := requestEnvelope{Namespace: soap11Namespace}
+	contentType := Soap11Type
+	if c.version == V12 {
+		envelope.Namespace = soap12Namespace
+		contentType = Soap12Type
+	}
+	envelope.Body.Content = request
+
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	err := xml.NewEncoder(&body).Encode(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode SOAP request: %w", err)
+	}
+
+	reqIn := &restclient.Entity{ContentType: contentType + "; charset=utf-8", Content: body.Bytes()}
+	if c.version == V12 {
+		if action != "" {
+			reqIn.ContentType += restclient.MimeType(fmt.Sprintf("; action=%q", action))
+		}
+	} else {
+		// limits the capacity, so that appending options doesn't modify the caller's slice
+		opts = append(opts[:len(opts):len(opts)], restclient.WithHeader("SOAPAction", fmt.Sprintf("%q", action)))
+	}
+	respOut := &restclient.Entity{ContentType: contentType, Content: []byte(nil)}
+
+	err = c.restClient.ExchangeWithContext(ctx, "POST", urlIn, nil, reqIn, respOut, opts...)
+	if err != nil {
+		var failed *restclient.FailedResponseError
+		if errors.As(err, &failed) {
+			if content, ok := failed.Entity.Content.([]byte); ok {
+				var env responseEnvelope
+				if xml.Unmarshal(content, &env) == nil && env.Body.Fault != nil {
+					fault := env.Body.Fault.fault()
+					fault.Response = failed
+					return fault
+				}
+			}
+		}
+		return err
+	}
+
+	var env responseEnvelope
+	err = xml.Unmarshal(respOut.Content.([]byte), &env)
+	if err != nil {
+		return fmt.Errorf("failed to decode SOAP response: %w", err)
+	}
+	if env.Body.Fault != nil {
+		return env.Body.Fault.fault()
+	}
+	if response != nil {
+		err = xml.Unmarshal(env.Body.Content, response)
+		if err != nil {
+			return fmt.Errorf("failed to decode SOAP response body: %w", err)
+		}
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package soap_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/soap"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type GetQuote struct {
+	XMLName xml.Name `xml:"http://example.com/stock GetQuote"`
+	Symbol  string
+}
+
+type GetQuoteResponse struct {
+	Price float64
+}
+
+func ExampleClient_Call() {
+	// Setup a test HTTP server that implements a SOAP 1.1 service
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println(r.Header.Get("SOAPAction"))
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if !strings.Contains(string(body), "<Symbol>RAX</Symbol>") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+<soap:Body><soap:Fault>
+  <faultcode>soap:Client</faultcode>
+  <faultstring>Unknown symbol</faultstring>
+</soap:Fault></soap:Body></soap:Envelope>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+<soap:Body><GetQuoteResponse xmlns="http://example.com/stock">
+  <Price>12.5</Price>
+</GetQuoteResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	caller := soap.New(client)
+
+	var resp GetQuoteResponse
+	err := caller.Call(context.Background(), "/StockService", "http://example.com/stock/GetQuote",
+		&GetQuote{Symbol: "RAX"}, &resp)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.Price)
+
+	err = caller.Call(context.Background(), "/StockService", "http://example.com/stock/GetQuote",
+		&GetQuote{Symbol: "XYZ"}, &resp)
+	var fault *soap.Fault
+	var failed *restclient.FailedResponseError
+	fmt.Println(errors.As(err, &fault), fault.Code, errors.As(err, &failed), failed.StatusCode)
+	fmt.Println(err)
+	// Output:
+	// "http://example.com/stock/GetQuote"
+	// 12.5
+	// "http://example.com/stock/GetQuote"
+	// true soap:Client true 500
+	// SOAP fault soap:Client: Unknown symbol
+}
+
+func ExampleWithVersion() {
+	// Setup a test HTTP server that implements a SOAP 1.2 service
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println(r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<env:Body><env:Fault>
+  <env:Code><env:Value>env:Receiver</env:Value></env:Code>
+  <env:Reason><env:Text xml:lang="en">Quotes are unavailable</env:Text></env:Reason>
+  <env:Detail><RetryAfter xmlns="http://example.com/stock">60</RetryAfter></env:Detail>
+</env:Fault></env:Body></env:Envelope>`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	caller := soap.New(client, soap.WithVersion(soap.V12))
+
+	err := caller.Call(context.Background(), "/StockService", "http://example.com/stock/GetQuote",
+		&GetQuote{Symbol: "RAX"}, &GetQuoteResponse{})
+	var fault *soap.Fault
+	if errors.As(err, &fault) {
+		var retryAfter int
+		_ = xml.Unmarshal(fault.Detail, &retryAfter)
+		fmt.Println(fault.Code, fault.Reason, retryAfter)
+	}
+	// Output:
+	// application/soap+xml; charset=utf-8; action="http://example.com/stock/GetQuote"
+	// env:Receiver Quotes are unavailable 60
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync/atomic"
+)
+
+// ClientStats is a snapshot of the counters of a Client, such as for dashboards. The counters
+// accumulate from the creation of the client.
+type ClientStats struct {
+	// Requests is the number of exchanges started
+	Requests int64
+	// Succeeded is the number of exchanges that completed successfully
+	Succeeded int64
+	// ClientErrors is the number of exchanges that failed with a status other than 5xx, such as
+	// 404 Not Found
+	ClientErrors int64
+	// ServerErrors is the number of exchanges that failed with a 5xx status
+	ServerErrors int64
+	// Timeouts is the number of exchanges that failed due to the client's Timeout
+	Timeouts int64
+	// Canceled is the number of exchanges whose context was canceled or reached its deadline
+	Canceled int64
+	// DecodeErrors is the number of exchanges whose response could not be decoded
+	DecodeErrors int64
+	// ValidationErrors is the number of exchanges whose request or response content was invalid
+	ValidationErrors int64
+	// OtherErrors is the number of exchanges that failed otherwise, such as being unable to connect
+	OtherErrors int64
+	// Retries is the number of attempts retried by the Retry interceptor
+	Retries int64
+	// TokenRefreshes is the number of tokens obtained by the IdentityV2Auth interceptor
+	TokenRefreshes int64
+}
+
+// clientStats holds the counters of a client, which are updated atomically
+type clientStats struct {
+	requests         int64
+	succeeded        int64
+	clientErrors     int64
+	serverErrors     int64
+	timeouts         int64
+	canceled         int64
+	decodeErrors     int64
+	validationErrors int64
+	otherErrors      int64
+	retries          int64
+	tokenRefreshes   int64
+}
+
+// clientStatsKey conveys the counters of the client to the interceptors of its exchanges
+type clientStatsKey struct{}
+
+func statsFrom(ctx context.Context) *clientStats {
+	stats, _ := ctx.Value(clientStatsKey{}).(*clientStats)
+	return stats
+}
+
+func (s *clientStats) add(counter *int64) {
+	atomic.AddInt64(counter, 1)
+}
+
+// record counts the outcome of an exchange
+func (s *clientStats) record(err error) {
+	var failed *FailedResponseError
+	var decode *DecodeError
+	var validation *EntityValidationError
+	switch {
+	case err == nil:
+		s.add(&s.succeeded)
+	case errors.As(err, &failed):
+		if failed.StatusCode >= 500 {
+			s.add(&s.serverErrors)
+		} else {
+			s.add(&s.clientErrors)
+		}
+	case errors.Is(err, ErrClientTimeout):
+		s.add(&s.timeouts)
+	case errors.Is(err, ErrCanceled):
+		s.add(&s.canceled)
+	case errors.As(err, &decode):
+		s.add(&s.decodeErrors)
+	case errors.As(err, &validation):
+		s.add(&s.validationErrors)
+	default:
+		s.add(&s.otherErrors)
+	}
+}
+
+// Stats returns a snapshot of the client's counters
+func (c *Client) Stats() ClientStats {
+	s := &c.stats
+	return ClientStats{
+		Requests:         atomic.LoadInt64(&s.requests),
+		Succeeded:        atomic.LoadInt64(&s.succeeded),
+		ClientErrors:     atomic.LoadInt64(&s.clientErrors),
+		ServerErrors:     atomic.LoadInt64(&s.serverErrors),
+		Timeouts:         atomic.LoadInt64(&s.timeouts),
+		Canceled:         atomic.LoadInt64(&s.canceled),
+		DecodeErrors:     atomic.LoadInt64(&s.decodeErrors),
+		ValidationErrors: atomic.LoadInt64(&s.validationErrors),
+		OtherErrors:      atomic.LoadInt64(&s.otherErrors),
+		Retries:          atomic.LoadInt64(&s.retries),
+		TokenRefreshes:   atomic.LoadInt64(&s.tokenRefreshes),
+	}
+}
+
+// PublishExpvar publishes the client's Stats as the named expvar variable, which is served as
+// JSON at /debug/vars by the expvar handler. Like expvar.Publish, it panics if the name is
+// already in use.
+func (c *Client) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return c.Stats()
+	}))
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleClient_Stats() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key"})
+
+	// Setup a test HTTP server that is briefly unavailable
+	attempts := 0
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case attempts == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "user1", "", "key")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.Retry(restclient.RetryPolicy{InitialBackoff: time.Millisecond}))
+	client.AddInterceptor(authenticator)
+	client.PublishExpvar("example_client")
+
+	_ = client.Exchange("GET", "/servers", nil, nil, nil)
+	_ = client.Exchange("GET", "/missing", nil, nil, nil)
+
+	stats := client.Stats()
+	fmt.Println(stats.Requests, stats.Succeeded, stats.ClientErrors, stats.Retries, stats.TokenRefreshes)
+
+	var published restclient.ClientStats
+	_ = json.Unmarshal([]byte(expvar.Get("example_client").String()), &published)
+	fmt.Println(published == stats)
+	// Output:
+	// 2 1 1 1 1
+	// true
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+// SuccessPredicate determines if the status code of a response indicates a successful exchange.
+// The response of an unsuccessful exchange is returned as a FailedResponseError.
+type SuccessPredicate func(statusCode int) bool
+
+// DefaultSuccess is the SuccessPredicate used by default, which accepts 2xx status codes
+func DefaultSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// AlsoSuccessful creates a SuccessPredicate that accepts 2xx status codes along with the given
+// status codes, such as http.StatusNotModified
+func AlsoSuccessful(statusCodes ...int) SuccessPredicate {
+	return func(statusCode int) bool {
+		if DefaultSuccess(statusCode) {
+			return true
+		}
+		for _, code := range statusCodes {
+			if statusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithSuccess sets the SuccessPredicate of the exchange, which takes precedence over the client's
+// IsSuccess
+func WithSuccess(isSuccess SuccessPredicate) RequestOption {
+	return func(o *requestOptions) {
+		o.isSuccess = isSuccess
+	}
+}
+
+func (c *Client) isSuccess(options *requestOptions, statusCode int) bool {
+	switch {
+	case options.isSuccess != nil:
+		return options.isSuccess(statusCode)
+	case c.IsSuccess != nil:
+		return c.IsSuccess(statusCode)
+	default:
+		return DefaultSuccess(statusCode)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleAlsoSuccessful() {
+	// Setup a test HTTP server that supports conditional retrieval
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "web"}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.IsSuccess = restclient.AlsoSuccessful(http.StatusNotModified)
+
+	var server map[string]interface{}
+	var etag string
+	err := client.Exchange("GET", "/servers/1", nil, nil, restclient.NewJsonEntity(&server),
+		restclient.WithETag(&etag))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var info restclient.ResponseInfo
+	err = client.Exchange("GET", "/servers/1", nil, nil, restclient.NewJsonEntity(&server),
+		restclient.WithHeader("If-None-Match", etag), restclient.WithResponseInfo(&info))
+	fmt.Println(info.StatusCode, server["name"], err)
+
+	// the client's predicate can be overridden for an exchange
+	err = client.Exchange("GET", "/servers/1", nil, nil, restclient.NewJsonEntity(&server),
+		restclient.WithHeader("If-None-Match", etag), restclient.WithSuccess(restclient.DefaultSuccess))
+	fmt.Println(err)
+	// Output:
+	// 304 web <nil>
+	// 304 Not Modified body=[]
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultTenantIdHeader is the header injected by IdentityV2Auth.TenantIdInjector by default
+const DefaultTenantIdHeader = "X-Tenant-Id"
+
+// TenantIdPlaceholder may be included in the BaseUrl of a Client, such as
+// "https://dns.api.rackspacecloud.com/v1.0/{tenantId}", to be replaced by the tenant of the
+// authenticated user once it is known. The tenant is provided by the IdentityV2Auth interceptor or
+// by the "tenantId" entry of the client's BaseUrlParams.
+const TenantIdPlaceholder = "{tenantId}"
+
+// fillPlaceholders replaces the "{name}" placeholders in the path of u with the corresponding params
+func fillPlaceholders(u *url.URL, params map[string]string) {
+	if !strings.Contains(u.Path, "{") {
+		return
+	}
+	for name, value := range params {
+		placeholder := "{" + name + "}"
+		u.Path = strings.Replace(u.Path, placeholder, value, -1)
+		if u.RawPath != "" {
+			u.RawPath = strings.Replace(u.RawPath, placeholder, url.PathEscape(value), -1)
+		}
+	}
+}
+
+// TenantIdInjector creates an Interceptor that injects the tenant of the token, as returned by
+// TenantId, into requests with the given header, which defaults to DefaultTenantIdHeader when empty.
+// This is needed by services that authorize upon the header in addition to the token.
+func (a *IdentityV2Auth) TenantIdInjector(header string) Interceptor {
+	if header == "" {
+		header = DefaultTenantIdHeader
+	}
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		tenantId, err := a.TenantId(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		if tenantId == "" {
+			return nil, fmt.Errorf("token has no tenant for the %s header", header)
+		}
+		req.Header.Set(header, tenantId)
+		return next(req)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"log"
+	"net/http"
+	"net/http/httptest"
+)
+
+func ExampleTenantIdPlaceholder() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key", TenantId: "123456"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path)
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	authenticator, err := restclient.IdentityV2Authenticator(identity.URL, "user1", "", "key")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL + "/v1.0/" + restclient.TenantIdPlaceholder + "/")
+	client.AddInterceptor(authenticator)
+
+	err = client.Exchange("GET", "domains", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// or when the tenant is known up front
+	static := restclient.NewClient()
+	static.SetBaseUrl(ts.URL + "/v1.0/{tenantId}/")
+	static.BaseUrlParams = map[string]string{"tenantId": "654321"}
+	static.AddInterceptor(authenticator)
+
+	err = static.Exchange("GET", "domains", nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// RECV /v1.0/123456/domains
+	// RECV /v1.0/654321/domains
+}
+
+func ExampleIdentityV2Auth_TenantIdInjector() {
+	identity := restclienttest.NewIdentityServer()
+	defer identity.Close()
+	identity.AddUser(restclienttest.IdentityUser{Username: "user1", Apikey: "key", TenantId: "123456"})
+
+	ts := httptest.NewServer(identity.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV tenant", r.Header.Get("X-Tenant-Id"))
+	})))
+	defer ts.Close()
+
+	// Real example starts here
+	auth, err := restclient.NewIdentityV2Auth(identity.URL, "user1", "", "key")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(auth.Intercept)
+	client.AddInterceptor(auth.TenantIdInjector(""))
+
+	err = client.Exchange("GET", "/", nil, nil, nil)
+	fmt.Println(err)
+	// Output:
+	// RECV tenant 123456
+	// <nil>
+}
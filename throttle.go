@@ -0,0 +1,135 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Throttle creates an Interceptor that limits the rate, in bytes per second, at which request bodies
+// are sent and response bodies are received. A limit of zero or less disables throttling in that
+// direction.
+//
+// The limits are shared by all requests processed by the interceptor, so the combined bandwidth of
+// concurrent exchanges stays within the limits, such as for background sync jobs that shouldn't
+// saturate a link shared with production traffic.
+func Throttle(uploadBytesPerSec, downloadBytesPerSec int64) Interceptor {
+	upload := newBandwidthLimiter(uploadBytesPerSec)
+	download := newBandwidthLimiter(downloadBytesPerSec)
+
+	return func(req *http.Request, next NextCallback) (*http.Response, error) {
+		ctx := req.Context()
+		if upload != nil && req.Body != nil && req.Body != http.NoBody {
+			req.Body = upload.wrap(ctx, req.Body)
+			if getBody := req.GetBody; getBody != nil {
+				req.GetBody = func() (io.ReadCloser, error) {
+					body, err := getBody()
+					if err != nil {
+						return nil, err
+					}
+					return upload.wrap(ctx, body), nil
+				}
+			}
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if download != nil {
+			resp.Body = download.wrap(ctx, resp.Body)
+		}
+		return resp, nil
+	}
+}
+
+// bandwidthLimiter paces reads by reserving time slots proportional to the bytes read
+type bandwidthLimiter struct {
+	bytesPerSec int64
+	chunkSize   int
+
+	mu sync.Mutex
+	// available is the time at which the next reservation can start
+	available time.Time
+}
+
+func newBandwidthLimiter(bytesPerSec int64) *bandwidthLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	// reading in chunks of a tenth of the rate keeps the pacing smooth
+	chunkSize := int(bytesPerSec / 10)
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	return &bandwidthLimiter{
+		bytesPerSec: bytesPerSec,
+		chunkSize:   chunkSize,
+	}
+}
+
+// wait blocks until n bytes worth of bandwidth has been consumed or the context is done
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	duration := time.Duration(int64(n) * int64(time.Second) / l.bytesPerSec)
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.available
+	if start.Before(now) {
+		start = now
+	}
+	l.available = start.Add(duration)
+	delay := l.available.Sub(now)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *bandwidthLimiter) wrap(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	return &throttledReadCloser{ReadCloser: body, limiter: l, ctx: ctx}
+}
+
+type throttledReadCloser struct {
+	io.ReadCloser
+	limiter *bandwidthLimiter
+	ctx     context.Context
+}
+
+func (t *throttledReadCloser) Read(p []byte) (int, error) {
+	if len(p) > t.limiter.chunkSize {
+		p = p[:t.limiter.chunkSize]
+	}
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.wait(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func ExampleThrottle() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 300))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	// limit downloads to 1000 bytes per second
+	client.AddInterceptor(restclient.Throttle(0, 1000))
+
+	started := time.Now()
+	resp := restclient.NewTextEntity("")
+	err := client.Exchange("GET", "/content", nil, nil, resp)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(len(resp.Content.(string)))
+	fmt.Println(time.Since(started) >= 250*time.Millisecond)
+	// Output:
+	// 300
+	// true
+}
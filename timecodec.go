@@ -0,0 +1,347 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeCodec configures the encoding of time.Time values within JSON entities for APIs whose
+// timestamps are not RFC 3339, such as those using Unix epoch seconds or a custom layout. It is
+// set as the TimeCodec of a Client, so that structs can use time.Time fields as is, such as
+//
+//	client.TimeCodec = restclient.UnixTime
+//
+// The time.Time values, including pointers and those within slices and maps, are located by the
+// type of the entity's content, so content of interface{} type is not converted. Fields are named
+// as by the FieldNaming of the registered JsonCodec. Since content is re-encoded after conversion,
+// object keys of request bodies are sorted. Content that is a JsonArrayItemHandler is not converted.
+type TimeCodec struct {
+	// Layout is the layout of times encoded as strings, as given to time.Time.Format
+	Layout string
+	// Epoch, when positive, encodes times as the number of these units since the Unix epoch, such
+	// as time.Second or time.Millisecond, rather than by Layout
+	Epoch time.Duration
+	// Location is the location of decoded times, which defaults to UTC, and of times formatted
+	// by Layout, which otherwise retain their location
+	Location *time.Location
+}
+
+var (
+	// UnixTime encodes times as the number of seconds since the Unix epoch
+	UnixTime = &TimeCodec{Epoch: time.Second}
+	// UnixMilliTime encodes times as the number of milliseconds since the Unix epoch
+	UnixMilliTime = &TimeCodec{Epoch: time.Millisecond}
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Format returns the JSON value of the time, which is a json.Number or string
+func (tc *TimeCodec) Format(t time.Time) interface{} {
+	if tc.Epoch > 0 {
+		return json.Number(strconv.FormatInt(tc.toEpoch(t), 10))
+	}
+	if tc.Location != nil {
+		t = t.In(tc.Location)
+	}
+	return t.Format(tc.Layout)
+}
+
+// Parse converts the JSON value of a time, as decoded with json.Decoder.UseNumber, into the time
+func (tc *TimeCodec) Parse(value interface{}) (time.Time, error) {
+	location := tc.Location
+	if location == nil {
+		location = time.UTC
+	}
+	if tc.Epoch > 0 {
+		var text string
+		switch v := value.(type) {
+		case json.Number:
+			text = string(v)
+		case string:
+			text = v
+		default:
+			return time.Time{}, fmt.Errorf("expected epoch time but got %v", value)
+		}
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return tc.fromEpoch(n).In(location), nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch time %q: %w", text, err)
+		}
+		return time.Unix(0, int64(math.Round(f*float64(tc.Epoch)))).In(location), nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected time string but got %v", value)
+	}
+	return time.ParseInLocation(tc.Layout, text, location)
+}
+
+// toEpoch converts the time into units of Epoch, avoiding the limited range of UnixNano where possible
+func (tc *TimeCodec) toEpoch(t time.Time) int64 {
+	switch {
+	case tc.Epoch%time.Second == 0:
+		return t.Unix() / int64(tc.Epoch/time.Second)
+	case time.Second%tc.Epoch == 0:
+		return t.Unix()*int64(time.Second/tc.Epoch) + int64(t.Nanosecond())/int64(tc.Epoch)
+	default:
+		return t.UnixNano() / int64(tc.Epoch)
+	}
+}
+
+func (tc *TimeCodec) fromEpoch(n int64) time.Time {
+	switch {
+	case tc.Epoch%time.Second == 0:
+		return time.Unix(n*int64(tc.Epoch/time.Second), 0)
+	case time.Second%tc.Epoch == 0:
+		perSecond := int64(time.Second / tc.Epoch)
+		return time.Unix(n/perSecond, n%perSecond*int64(tc.Epoch))
+	default:
+		return time.Unix(0, n*int64(tc.Epoch))
+	}
+}
+
+// encodeTimes converts the times of the content, as encoded by codec, which encoding/json renders
+// in RFC 3339, into the codec's format
+func (tc *TimeCodec) encodeTimes(codec Codec, data []byte, t reflect.Type) ([]byte, error) {
+	if !containsTime(t, map[reflect.Type]bool{}) {
+		return data, nil
+	}
+	return convertJsonTimes(data, t, jsonFieldNaming(codec), func(value interface{}) (interface{}, error) {
+		text, _ := value.(string)
+		parsed, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return nil, err
+		}
+		return tc.Format(parsed), nil
+	})
+}
+
+// decode decodes the content with codec after converting the times of the JSON in r from the
+// codec's format into RFC 3339, as expected by encoding/json
+func (tc *TimeCodec) decode(codec Codec, r io.Reader, content interface{}) error {
+	if _, isHandler := content.(JsonArrayItemHandler); isHandler || !containsTime(reflect.TypeOf(content), map[reflect.Type]bool{}) {
+		return codec.Decode(r, content)
+	}
+	data, err := readAll(r)
+	if err != nil {
+		return err
+	}
+	converted, err := convertJsonTimes(data, reflect.TypeOf(content), jsonFieldNaming(codec), func(value interface{}) (interface{}, error) {
+		parsed, err := tc.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.Format(time.RFC3339Nano), nil
+	})
+	if err != nil {
+		return err
+	}
+	return codec.Decode(bytes.NewReader(converted), content)
+}
+
+// convertJsonTimes applies convert to the values of the JSON data that correspond to the
+// time.Time values of type t, where the untagged fields of structs are named by naming when set
+func convertJsonTimes(data []byte, t reflect.Type, naming FieldNaming,
+	convert func(value interface{}) (interface{}, error)) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	value, err := convertTimes(value, t, naming, convert)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func convertTimes(value interface{}, t reflect.Type, naming FieldNaming,
+	convert func(value interface{}) (interface{}, error)) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if value == nil {
+		return nil, nil
+	}
+	if t == timeType {
+		return convert(value)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		for name, field := range jsonFields(t) {
+			if naming != nil && !field.Tagged {
+				name = naming(name)
+			}
+			key := findJsonKey(object, name)
+			if key == "" {
+				continue
+			}
+			converted, err := convertTimes(object[key], field.Type, naming, convert)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			object[key] = converted
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return value, nil
+		}
+		for i, item := range items {
+			converted, err := convertTimes(item, t.Elem(), naming, convert)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		for key, item := range object {
+			converted, err := convertTimes(item, t.Elem(), naming, convert)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			object[key] = converted
+		}
+	}
+	return value, nil
+}
+
+// jsonFieldNaming returns the FieldNaming of the codec when it is a JsonCodec, since the times are
+// located within the JSON as named by the codec
+func jsonFieldNaming(codec Codec) FieldNaming {
+	switch c := codec.(type) {
+	case JsonCodec:
+		return c.FieldNaming
+	case *JsonCodec:
+		if c != nil {
+			return c.FieldNaming
+		}
+	}
+	return nil
+}
+
+// findJsonKey finds the key of the field in the object, which, like encoding/json, prefers an
+// exact match but otherwise matches case-insensitively
+func findJsonKey(object map[string]interface{}, name string) string {
+	if _, ok := object[name]; ok {
+		return name
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
+
+// jsonField describes a struct field as encoded by encoding/json
+type jsonField struct {
+	Type reflect.Type
+	// Tagged indicates the JSON name is given by the field's json tag rather than its Go name
+	Tagged bool
+}
+
+// jsonFields maps the JSON names of the struct's fields to their descriptions, including the
+// promoted fields of embedded structs
+func jsonFields(t reflect.Type) map[string]jsonField {
+	fields := make(map[string]jsonField)
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+				embedded = append(embedded, fieldType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		fields[name] = jsonField{Type: field.Type, Tagged: tagged}
+	}
+	// fields of the outer struct take precedence over promoted fields
+	for _, embeddedType := range embedded {
+		for name, embeddedField := range jsonFields(embeddedType) {
+			if _, exists := fields[name]; !exists {
+				fields[name] = embeddedField
+			}
+		}
+	}
+	return fields
+}
+
+// containsTime determines if values of the type may hold a time.Time that is to be converted
+func containsTime(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, field := range jsonFields(t) {
+			if containsTime(field.Type, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return containsTime(t.Elem(), seen)
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleTimeCodec() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println(string(body))
+		fmt.Fprint(w, `{"id":"1","created":1600000000123,"expires":null,"events":[{"at":1600000060000}]}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	type Event struct {
+		At time.Time `json:"at"`
+	}
+	type Backup struct {
+		Id      string     `json:"id,omitempty"`
+		Created time.Time  `json:"created"`
+		Expires *time.Time `json:"expires"`
+		Events  []Event    `json:"events"`
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.TimeCodec = restclient.UnixMilliTime
+
+	var created Backup
+	err := client.Exchange("POST", "/backups", nil,
+		restclient.NewJsonEntity(&Backup{Created: time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)}),
+		restclient.NewJsonEntity(&created))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(created.Created.Format(time.RFC3339Nano), created.Expires, created.Events[0].At)
+	// Output:
+	// {"created":1600000000000,"events":null,"expires":null}
+	// 2020-09-13T12:26:40.123Z <nil> 2020-09-13 12:27:40 +0000 UTC
+}
+
+func ExampleTimeCodec_layout() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"updated":"13/09/2020 12:26"}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.TimeCodec = &restclient.TimeCodec{Layout: "02/01/2006 15:04"}
+
+	var resp struct {
+		Updated time.Time
+	}
+	err := client.Exchange("GET", "/status", nil, nil, restclient.NewJsonEntity(&resp))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(resp.Updated)
+	// Output:
+	// 2020-09-13 12:26:00 +0000 UTC
+}
+
+func ExampleTimeCodec_fieldNaming() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Println(string(body))
+		fmt.Fprint(w, `{"server_id":"123","created_at":1600000000}`)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{FieldNaming: restclient.SnakeCase})
+	// restores the default for the other examples
+	defer restclient.RegisterCodec(restclient.JsonType, restclient.JsonCodec{})
+
+	type Server struct {
+		ServerID  string
+		CreatedAt time.Time
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.TimeCodec = restclient.UnixTime
+
+	// the times are located by the names of the fields in the JSON
+	var created Server
+	err := client.Exchange("POST", "/servers", nil,
+		restclient.NewJsonEntity(&Server{CreatedAt: time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)}),
+		restclient.NewJsonEntity(&created))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(created.ServerID, created.CreatedAt)
+	// Output:
+	// {"created_at":1600000000,"server_id":""}
+	// 123 2020-09-13 12:26:40 +0000 UTC
+}
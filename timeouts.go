@@ -0,0 +1,212 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBodyReadTimeout is returned when reading a response body waited longer than the BodyReadTimeout
+	// of the Client for bytes to arrive
+	ErrBodyReadTimeout = errors.New("response body read timed out")
+	// ErrResponseHeaderTimeout is matched, via errors.Is, by errors of exchanges whose response headers
+	// didn't arrive within the timeout of WithIdleTimeout after the request was written
+	ErrResponseHeaderTimeout = errors.New("response headers timed out")
+)
+
+// the keep-alive and timeout of the dialer of http.DefaultTransport
+const (
+	defaultDialKeepAlive = 30 * time.Second
+	defaultDialTimeout   = 30 * time.Second
+)
+
+// transportHttpClient is an http.Client whose transport applies the fine-grained timeouts and
+// DnsCache of a Client
+type transportHttpClient struct {
+	base                  *http.Client
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	dnsCache              *DnsCache
+	client                *http.Client
+}
+
+func (c *Client) customizesTransport() bool {
+	return c.DialTimeout > 0 || c.TLSHandshakeTimeout > 0 || c.ResponseHeaderTimeout > 0 || c.DnsCache != nil
+}
+
+// bodyReadTimeout returns the idle timeout of reading the response body, which is disabled when not positive
+func (c *Client) bodyReadTimeout(options *requestOptions) time.Duration {
+	if options.idleTimeout != 0 {
+		return options.idleTimeout
+	}
+	return c.BodyReadTimeout
+}
+
+// transportHttpClient returns a copy of base whose transport applies the fine-grained timeouts and
+// DnsCache. The copy is retained while they are unchanged, so that its connections are reused.
+func (c *Client) transportHttpClient(base *http.Client) *http.Client {
+	c.transportMu.Lock()
+	defer c.transportMu.Unlock()
+
+	cached := c.transportClient
+	if cached != nil && cached.base == base && cached.dialTimeout == c.DialTimeout &&
+		cached.tlsHandshakeTimeout == c.TLSHandshakeTimeout && cached.responseHeaderTimeout == c.ResponseHeaderTimeout &&
+		cached.dnsCache == c.DnsCache {
+		return cached.client
+	}
+
+	var transport *http.Transport
+	switch t := base.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		// the timeouts can't be applied to other round trippers
+		return base
+	}
+	if c.DialTimeout > 0 || c.DnsCache != nil {
+		dialer := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: defaultDialKeepAlive}
+		if c.DialTimeout > 0 {
+			dialer.Timeout = c.DialTimeout
+		}
+		transport.DialContext = dialer.DialContext
+		if c.DnsCache != nil {
+			transport.DialContext = c.DnsCache.dialContext(dialer)
+		}
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	}
+
+	client := *base
+	client.Transport = transport
+	c.transportClient = &transportHttpClient{
+		base:                  base,
+		dialTimeout:           c.DialTimeout,
+		tlsHandshakeTimeout:   c.TLSHandshakeTimeout,
+		responseHeaderTimeout: c.ResponseHeaderTimeout,
+		dnsCache:              c.DnsCache,
+		client:                &client,
+	}
+	return &client
+}
+
+// responseHeaderWatchdog cancels a request whose response headers don't arrive within the timeout
+// of the request being written, such as on a silently half-open connection. Each attempt of the
+// request, such as by Retry, restarts the watchdog.
+type responseHeaderWatchdog struct {
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	timer    *time.Timer
+	timedOut int32
+}
+
+// watchResponseHeaders returns the request bound to a watchdog of its response headers. The
+// returned cancel must be called once the exchange is complete.
+func watchResponseHeaders(req *http.Request, timeout time.Duration) (*http.Request, *responseHeaderWatchdog, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(req.Context())
+	w := &responseHeaderWatchdog{timeout: timeout, cancel: cancel}
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if w.timer == nil {
+				w.timer = time.AfterFunc(w.timeout, w.expire)
+			} else {
+				w.timer.Reset(w.timeout)
+			}
+		},
+		GotFirstResponseByte: w.stop,
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+	return req, w, func() {
+		w.stop()
+		cancel()
+	}
+}
+
+func (w *responseHeaderWatchdog) expire() {
+	atomic.StoreInt32(&w.timedOut, 1)
+	w.cancel()
+}
+
+func (w *responseHeaderWatchdog) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// classify marks the error of sending the request with ErrResponseHeaderTimeout when the watchdog expired
+func (w *responseHeaderWatchdog) classify(err error) error {
+	if err != nil && atomic.LoadInt32(&w.timedOut) == 1 {
+		return &contextError{kind: ErrResponseHeaderTimeout, cause: err}
+	}
+	return err
+}
+
+// idleTimeoutBody fails a read of the body that waits longer than the timeout for bytes to arrive.
+// Only the time spent within Read counts, so a slow consumer doesn't cause a timeout.
+type idleTimeoutBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut int32
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	b := &idleTimeoutBody{body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&b.timedOut, 1)
+		// unblocks the pending read
+		_ = body.Close()
+	})
+	b.timer.Stop()
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	if atomic.LoadInt32(&b.timedOut) == 1 {
+		return 0, ErrBodyReadTimeout
+	}
+	b.timer.Reset(b.timeout)
+	n, err := b.body.Read(p)
+	if !b.timer.Stop() && atomic.LoadInt32(&b.timedOut) == 1 {
+		return n, ErrBodyReadTimeout
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.body.Close()
+}
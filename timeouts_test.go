@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func ExampleClient_BodyReadTimeout() {
+	// Setup a test HTTP server whose download stalls
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "first chunk")
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.Timeout = time.Hour
+	client.BodyReadTimeout = 50 * time.Millisecond
+
+	var content string
+	err := client.Exchange("GET", "/backup.tar", nil, nil, restclient.NewTextEntity(content))
+	fmt.Println(errors.Is(err, restclient.ErrBodyReadTimeout))
+	// Output:
+	// true
+}
+
+func ExampleClient_ResponseHeaderTimeout() {
+	// Setup a test HTTP server that is slow to respond
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.DialTimeout = time.Second
+	client.ResponseHeaderTimeout = 50 * time.Millisecond
+
+	err := client.Exchange("GET", "/servers", nil, nil, nil)
+	fmt.Println(strings.Contains(err.Error(), "timeout awaiting response headers"))
+	// Output:
+	// true
+}
+
+func ExampleClient_EffectiveTimeout() {
+	client := restclient.NewClient()
+	fmt.Println(client.EffectiveTimeout())
+
+	client.Timeout = 5 * time.Minute
+	fmt.Println(client.EffectiveTimeout())
+
+	// such as for a long download, bounded instead by BodyReadTimeout
+	client.Timeout = restclient.NoTimeout
+	client.BodyReadTimeout = 30 * time.Second
+	fmt.Println(client.EffectiveTimeout() == restclient.NoTimeout)
+	// Output:
+	// 1m0s
+	// 5m0s
+	// true
+}
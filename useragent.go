@@ -0,0 +1,67 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+const (
+	headerUserAgent  = "User-Agent"
+	userAgentProduct = "go-restclient"
+	modulePath       = "github.com/racker/go-restclient"
+)
+
+// DefaultUserAgent identifies this library in the User-Agent of requests, such as
+// "go-restclient/1.2.0 (example.com/mytool go1.15.2)", where the version of the library and the
+// path of the application's module are determined from the build information, when available.
+var DefaultUserAgent = buildUserAgent()
+
+func buildUserAgent() string {
+	version := "devel"
+	var mainPath string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mainPath = info.Main.Path
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				if dep.Replace != nil {
+					dep = dep.Replace
+				}
+				if dep.Version != "" {
+					version = strings.TrimPrefix(dep.Version, "v")
+				}
+			}
+		}
+	}
+
+	comment := runtime.Version()
+	if mainPath != "" && mainPath != modulePath {
+		comment = mainPath + " " + comment
+	}
+	return fmt.Sprintf("%s/%s (%s)", userAgentProduct, version, comment)
+}
+
+// userAgent is the client's UserAgent, if any, followed by DefaultUserAgent
+func (c *Client) userAgent() string {
+	if c.UserAgent == "" {
+		return DefaultUserAgent
+	}
+	return c.UserAgent + " " + DefaultUserAgent
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Validator is implemented by entity content that can check its own validity. Request content is
+// validated before it is encoded and sent, and response content is validated after it is decoded.
+// Note that request content given by value is only validated when Validate has a value receiver.
+type Validator interface {
+	Validate() error
+}
+
+// SchemaValidator validates entity content against a schema. The jsonschema package's Schema
+// implements this interface, which allows it to be set as the Schema of an Entity.
+type SchemaValidator interface {
+	Validate(content interface{}) error
+}
+
+// EntityValidationError is returned by an exchange when request or response content failed
+// validation. The cause, such as a *jsonschema.ValidationError with field-level details, can be
+// obtained via errors.As.
+type EntityValidationError struct {
+	// Direction is either "request" or "response"
+	Direction string
+	Err       error
+}
+
+func (e *EntityValidationError) Error() string {
+	return fmt.Sprintf("invalid %s content: %s", e.Direction, e.Err)
+}
+
+func (e *EntityValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateEntity applies the entity's schema and the content's own validation, if any. Streamed
+// content, such as readers and writers, can't be validated and is skipped.
+func validateEntity(entity *Entity, direction string) error {
+	if entity == nil || entity.Content == nil {
+		return nil
+	}
+	content := entity.Content
+	switch c := content.(type) {
+	case io.Reader, io.Writer, ContentWriter, JsonArrayItemHandler, CsvRowHandler:
+		return nil
+	case string:
+		content = rawJsonContent(entity.ContentType, []byte(c))
+	case []byte:
+		content = rawJsonContent(entity.ContentType, c)
+	}
+
+	if entity.Schema != nil && content != nil {
+		if err := entity.Schema.Validate(content); err != nil {
+			return &EntityValidationError{Direction: direction, Err: err}
+		}
+	}
+	if validator, ok := entity.Content.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return &EntityValidationError{Direction: direction, Err: err}
+		}
+	}
+	return nil
+}
+
+// rawJsonContent allows a schema to validate already encoded JSON content
+func rawJsonContent(contentType MimeType, data []byte) interface{} {
+	if !strings.Contains(string(contentType), "json") {
+		return nil
+	}
+	return json.RawMessage(data)
+}
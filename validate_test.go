@@ -0,0 +1,69 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"net/http/httptest"
+)
+
+type Volume struct {
+	Name   string `json:"name"`
+	SizeGb int    `json:"sizeGb"`
+}
+
+func (v *Volume) Validate() error {
+	if v.SizeGb <= 0 {
+		return fmt.Errorf("sizeGb must be positive, but was %d", v.SizeGb)
+	}
+	return nil
+}
+
+func ExampleValidator() {
+	// Setup a test HTTP server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.Method, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "data", "sizeGb": 0}`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+
+	// invalid request content is never sent
+	err := client.Exchange("POST", "/volumes", nil,
+		restclient.NewJsonEntity(&Volume{Name: "data"}), nil)
+	fmt.Println(err)
+
+	var volume Volume
+	err = client.Exchange("GET", "/volumes/data", nil, nil,
+		restclient.NewJsonEntity(&volume))
+	var validationErr *restclient.EntityValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Println(validationErr.Direction, validationErr.Err)
+	}
+
+	// Output:
+	// invalid request content: sizeGb must be positive, but was 0
+	// RECV GET /volumes/data
+	// response sizeGb must be positive, but was 0
+}
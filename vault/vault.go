@@ -0,0 +1,263 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package vault sources credentials and tokens from HashiCorp Vault for use with the authentication
+interceptors of the restclient package.
+
+The secrets are read via Vault's HTTP API using a restclient.Client. A secret with a lease, such as
+dynamic database credentials, is renewed once half of its lease has elapsed and is read again when
+the lease can't be renewed. A secret without a lease, such as one in a KV secrets engine, is read
+again after the refresh interval.
+
+	source, err := vault.New("https://vault.example.com:8200",
+		restclient.FileToken("/home/vault/.vault-token"))
+	...
+	client.AddInterceptor(restclient.BearerTokenFrom(source.Token("secret/data/service", "token")))
+*/
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval is how often secrets without a lease are read again
+const DefaultRefreshInterval = 5 * time.Minute
+
+// Client reads secrets from a Vault server
+type Client struct {
+	restClient      *restclient.Client
+	namespace       string
+	clock           restclient.Clock
+	refreshInterval time.Duration
+}
+
+// Option customizes the Client created by New
+type Option func(c *Client)
+
+// WithNamespace sets the Vault Enterprise namespace of the requests
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		c.namespace = namespace
+	}
+}
+
+// WithRefreshInterval sets how often secrets without a lease are read again, which defaults to
+// DefaultRefreshInterval
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.refreshInterval = interval
+	}
+}
+
+// WithClock sets the clock used to track leases
+func WithClock(clock restclient.Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// New creates a client of the Vault server at the given address that authenticates with the token
+// supplied by the provider, such as restclient.FileToken of a Vault agent's token sink
+func New(address string, token restclient.TokenProvider, opts ...Option) (*Client, error) {
+	restClient := restclient.NewClient()
+	if err := restClient.SetBaseUrl(address); err != nil {
+		return nil, fmt.Errorf("invalid Vault address: %w", err)
+	}
+
+	c := &Client{
+		restClient:      restClient,
+		clock:           restclient.SystemClock,
+		refreshInterval: DefaultRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	restClient.AddInterceptor(func(req *http.Request, next restclient.NextCallback) (*http.Response, error) {
+		vaultToken, err := token.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain Vault token: %w", err)
+		}
+		req.Header.Set("X-Vault-Token", vaultToken)
+		if c.namespace != "" {
+			req.Header.Set("X-Vault-Namespace", c.namespace)
+		}
+		return next(req)
+	})
+	return c, nil
+}
+
+// Secret is a secret read from Vault
+type Secret struct {
+	LeaseId       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// Field returns the string value of the named field of the secret. The fields of a KV version 2
+// secret, which are nested within "data", are also located.
+func (s *Secret) Field(name string) (string, bool) {
+	if value, ok := s.Data[name].(string); ok {
+		return value, true
+	}
+	if nested, ok := s.Data["data"].(map[string]interface{}); ok {
+		value, ok := nested[name].(string)
+		return value, ok
+	}
+	return "", false
+}
+
+// Read reads the secret at the given path, such as "secret/data/service" or "database/creds/readonly"
+func (c *Client) Read(ctx context.Context, path string) (*Secret, error) {
+	var secret Secret
+	err := c.restClient.ExchangeWithContext(ctx, "GET", "/v1/"+strings.TrimPrefix(path, "/"), nil, nil,
+		restclient.NewJsonEntity(&secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", path, err)
+	}
+	return &secret, nil
+}
+
+// Renew extends the lease of a secret by the given increment, where zero requests the default
+// increment of the lease
+func (c *Client) Renew(ctx context.Context, leaseId string, increment time.Duration) (*Secret, error) {
+	request := map[string]interface{}{"lease_id": leaseId}
+	if increment > 0 {
+		request["increment"] = int(increment.Seconds())
+	}
+	var secret Secret
+	err := c.restClient.ExchangeWithContext(ctx, "PUT", "/v1/sys/leases/renew", nil,
+		restclient.NewJsonEntity(request), restclient.NewJsonEntity(&secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return &secret, nil
+}
+
+// CredentialFields names the fields of a secret that hold each of the credentials, where empty names
+// are not used
+type CredentialFields struct {
+	Username string
+	Password string
+	Apikey   string
+}
+
+// Credentials creates a restclient.CredentialsProvider that supplies the credentials held by the
+// secret at the given path, such as for restclient.BasicAuthFrom or restclient.WithCredentialsProvider
+func (c *Client) Credentials(path string, fields CredentialFields) restclient.CredentialsProvider {
+	secret := &leasedSecret{client: c, path: path}
+	return restclient.CredentialsProviderFunc(func(ctx context.Context) (restclient.Credentials, error) {
+		current, err := secret.get(ctx)
+		if err != nil {
+			return restclient.Credentials{}, err
+		}
+		var credentials restclient.Credentials
+		for _, f := range []struct {
+			name   string
+			target *string
+		}{
+			{fields.Username, &credentials.Username},
+			{fields.Password, &credentials.Password},
+			{fields.Apikey, &credentials.Apikey},
+		} {
+			if f.name == "" {
+				continue
+			}
+			value, ok := current.Field(f.name)
+			if !ok {
+				return restclient.Credentials{}, fmt.Errorf("secret %s is missing field %s", path, f.name)
+			}
+			*f.target = value
+		}
+		return credentials, nil
+	})
+}
+
+// Token creates a restclient.TokenProvider that supplies the named field of the secret at the given
+// path, such as for restclient.BearerTokenFrom
+func (c *Client) Token(path string, field string) restclient.TokenProvider {
+	secret := &leasedSecret{client: c, path: path}
+	return restclient.TokenProviderFunc(func(ctx context.Context) (string, error) {
+		current, err := secret.get(ctx)
+		if err != nil {
+			return "", err
+		}
+		value, ok := current.Field(field)
+		if !ok {
+			return "", fmt.Errorf("secret %s is missing field %s", path, field)
+		}
+		return value, nil
+	})
+}
+
+// leasedSecret keeps a secret current by renewing its lease or reading it again
+type leasedSecret struct {
+	client *Client
+	path   string
+
+	mu       sync.Mutex
+	secret   *Secret
+	obtained time.Time
+}
+
+func (l *leasedSecret) get(ctx context.Context) (*Secret, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.client.clock.Now()
+	if l.secret != nil && now.Before(l.refreshAt()) {
+		return l.secret, nil
+	}
+
+	if l.secret != nil && l.secret.Renewable && l.secret.LeaseId != "" {
+		renewed, err := l.client.Renew(ctx, l.secret.LeaseId, 0)
+		if err == nil && renewed.LeaseDuration > 0 {
+			// a renewal only conveys the lease, so the data remains that of the original secret
+			l.secret.LeaseDuration = renewed.LeaseDuration
+			l.obtained = now
+			return l.secret, nil
+		}
+		// the lease has reached its maximum TTL or was revoked, so read a new secret
+	}
+
+	secret, err := l.client.Read(ctx, l.path)
+	if err != nil {
+		return nil, err
+	}
+	if secret.Data == nil {
+		return nil, errors.New("secret has no data: " + l.path)
+	}
+	l.secret = secret
+	l.obtained = now
+	return secret, nil
+}
+
+// refreshAt is when the secret should be renewed or read again, which is half way through its lease
+func (l *leasedSecret) refreshAt() time.Time {
+	if l.secret.LeaseDuration > 0 {
+		return l.obtained.Add(time.Duration(l.secret.LeaseDuration) * time.Second / 2)
+	}
+	return l.obtained.Add(l.client.refreshInterval)
+}
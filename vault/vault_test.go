@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/restclienttest"
+	"github.com/racker/go-restclient/vault"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+var vaultToken = restclient.TokenProviderFunc(func(ctx context.Context) (string, error) {
+	return "s.vault", nil
+})
+
+func ExampleClient_Token() {
+	// Setup a test Vault server with a KV version 2 secret
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("VAULT", r.Method, r.URL.Path, r.Header.Get("X-Vault-Token"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]string{"token": "service-token"},
+				"metadata": map[string]int{"version": 3},
+			},
+		})
+	}))
+	defer vaultServer.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	source, err := vault.New(vaultServer.URL, vaultToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.BearerTokenFrom(source.Token("secret/data/service", "token")))
+
+	// the secret is only read again after the refresh interval
+	for i := 0; i < 2; i++ {
+		err = client.Exchange("GET", "/", nil, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Output:
+	// VAULT GET /v1/secret/data/service s.vault
+	// RECV Bearer service-token
+	// RECV Bearer service-token
+}
+
+func ExampleClient_Credentials() {
+	// Setup a test Vault server issuing leased credentials
+	issued, renewed := 0, 0
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("VAULT", r.Method, r.URL.Path)
+		switch r.URL.Path {
+		case "/v1/database/creds/readonly":
+			issued++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"lease_id":       fmt.Sprintf("database/creds/readonly/lease%d", issued),
+				"lease_duration": 3600,
+				"renewable":      true,
+				"data": map[string]string{
+					"username": fmt.Sprintf("v-user%d", issued),
+					"password": "generated",
+				},
+			})
+		case "/v1/sys/leases/renew":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			renewed++
+			if renewed == 1 {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"lease_id": req["lease_id"], "lease_duration": 3600, "renewable": true,
+				})
+			} else {
+				// the lease reached its maximum TTL
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+	}))
+	defer vaultServer.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, _, _ := r.BasicAuth()
+		fmt.Println("RECV", username)
+	}))
+	defer ts.Close()
+
+	clock := restclienttest.NewFakeClock(time.Now())
+
+	// Real example starts here
+	source, err := vault.New(vaultServer.URL, vaultToken,
+		vault.WithClock(clock))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.BasicAuthFrom(
+		source.Credentials("database/creds/readonly", vault.CredentialFields{
+			Username: "username",
+			Password: "password",
+		})))
+
+	for i := 0; i < 3; i++ {
+		err = client.Exchange("GET", "/", nil, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// beyond half of the lease
+		clock.Advance(40 * time.Minute)
+	}
+
+	// Output:
+	// VAULT GET /v1/database/creds/readonly
+	// RECV v-user1
+	// VAULT PUT /v1/sys/leases/renew
+	// RECV v-user1
+	// VAULT PUT /v1/sys/leases/renew
+	// VAULT GET /v1/database/creds/readonly
+	// RECV v-user2
+}
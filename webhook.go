@@ -0,0 +1,154 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidSignature is returned by Webhook.Verify when an incoming callback is not signed with
+// the webhook's secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// defaultMaxCallbackSize bounds the body read by Webhook.Verify
+const defaultMaxCallbackSize = 10 << 20
+
+// WebhookConfig describes how an API signs the callbacks it delivers. Zero values are replaced
+// with the defaults noted on each field.
+type WebhookConfig struct {
+	// SignatureHeader is the header of a callback that carries the signature. It defaults to
+	// X-Signature.
+	SignatureHeader string
+	// SignaturePrefix precedes the hex-encoded signature in the header, such as "sha256="
+	SignaturePrefix string
+	// Hash creates the hash of the HMAC, which defaults to sha256.New
+	Hash func() hash.Hash
+	// MaxBodySize bounds the size of a callback's body that is read for verification. It defaults
+	// to 10MiB.
+	MaxBodySize int64
+}
+
+// Webhook holds both halves of the contract with an API that delivers signed callbacks: the
+// callback URL and secret given at registration and the verification of incoming callbacks.
+// A Webhook can be persisted and reconstructed with the same fields to verify callbacks after
+// a restart.
+type Webhook struct {
+	WebhookConfig
+	// CallbackUrl is where the API delivers the callbacks
+	CallbackUrl string
+	// Secret is the HMAC key shared with the API
+	Secret string
+}
+
+// NewWebhookSecret generates a random, hex-encoded secret suitable for signing callbacks
+func NewWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// RegisterWebhook generates a secret and registers the callbackUrl with the API by sending a POST
+// to urlIn. The content of the registration request is created by newRequest, which is given the
+// callback URL and secret to populate the fields the API expects. The response is decoded into
+// respOut, when non-nil, such as to obtain the identifier of the registration.
+//
+// The returned Webhook verifies the callbacks that are subsequently delivered.
+func (c *Client) RegisterWebhook(ctx context.Context, urlIn string, callbackUrl string, config WebhookConfig,
+	newRequest func(callbackUrl string, secret string) *Entity, respOut *Entity, opts ...RequestOption) (*Webhook, error) {
+
+	secret, err := NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.ExchangeWithContext(ctx, "POST", urlIn, nil, newRequest(callbackUrl, secret), respOut, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register webhook: %w", err)
+	}
+
+	return &Webhook{
+		WebhookConfig: config,
+		CallbackUrl:   callbackUrl,
+		Secret:        secret,
+	}, nil
+}
+
+// Sign computes the value of the signature header for the given body
+func (w *Webhook) Sign(body []byte) string {
+	mac := hmac.New(w.hash(), []byte(w.Secret))
+	_, _ = mac.Write(body)
+	return w.SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reads the body of an incoming callback and checks its signature. The body is returned
+// only when the signature is valid; otherwise, the error matches ErrInvalidSignature.
+func (w *Webhook) Verify(r *http.Request) ([]byte, error) {
+	maxSize := w.MaxBodySize
+	if maxSize <= 0 {
+		maxSize = defaultMaxCallbackSize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read callback body: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("callback body exceeds %d bytes", maxSize)
+	}
+
+	header := w.SignatureHeader
+	if header == "" {
+		header = "X-Signature"
+	}
+	if !w.VerifySignature(body, r.Header.Get(header)) {
+		return nil, ErrInvalidSignature
+	}
+	return body, nil
+}
+
+// VerifySignature checks the value of the signature header for the given body
+func (w *Webhook) VerifySignature(body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, w.SignaturePrefix) {
+		return false
+	}
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, w.SignaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(w.hash(), []byte(w.Secret))
+	_, _ = mac.Write(body)
+	return hmac.Equal(given, mac.Sum(nil))
+}
+
+func (w *Webhook) hash() func() hash.Hash {
+	if w.Hash != nil {
+		return w.Hash
+	}
+	return sha256.New
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func ExampleClient_RegisterWebhook() {
+	// Setup a test HTTP server that accepts registrations and signs a test callback
+	var registration struct {
+		Url    string `json:"url"`
+		Secret string `json:"secret"`
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&registration)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "hook-1"}`))
+	}))
+	defer api.Close()
+	deliver := func(body string, signature string) *http.Request {
+		req := httptest.NewRequest("POST", registration.Url, strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", signature)
+		return req
+	}
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(api.URL)
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	webhook, err := client.RegisterWebhook(context.Background(), "/hooks", "https://example.com/callback",
+		restclient.WebhookConfig{SignatureHeader: "X-Hub-Signature-256", SignaturePrefix: "sha256="},
+		func(callbackUrl string, secret string) *restclient.Entity {
+			return restclient.NewJsonEntity(map[string]string{"url": callbackUrl, "secret": secret})
+		},
+		restclient.NewJsonEntity(&created))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(created.Id, registration.Secret == webhook.Secret)
+
+	// the API signs each callback with the shared secret
+	body := `{"event": "server.created"}`
+	verified, err := webhook.Verify(deliver(body, webhook.Sign([]byte(body))))
+	fmt.Println(string(verified), err)
+
+	_, err = webhook.Verify(deliver(`{"event": "forged"}`, "sha256=00"))
+	fmt.Println(errors.Is(err, restclient.ErrInvalidSignature))
+	// Output:
+	// hook-1 true
+	// {"event": "server.created"} <nil>
+	// true
+}
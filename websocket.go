@@ -0,0 +1,333 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// The WebSocket message types that may be given to and returned from a WebSocketConn
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+)
+
+const (
+	wsOpContinuation = 0
+	wsOpClose        = 8
+	wsOpPing         = 9
+	wsOpPong         = 10
+
+	wsCloseNormal = 1000
+	wsGuid        = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxWebSocketControlPayload is the limit of control frames given by RFC 6455
+	maxWebSocketControlPayload = 125
+)
+
+// WebSocketCloseError is returned by WebSocketConn.ReadMessage when the server closed the connection
+type WebSocketCloseError struct {
+	Code int
+	Text string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Text)
+}
+
+type upgradedConnKey struct{}
+
+// upgradedConn captures the connection of a protocol switch before interceptors may wrap the body
+type upgradedConn struct {
+	rwc io.ReadWriteCloser
+}
+
+// DialWebSocket establishes a WebSocket connection to the path, which is resolved against the
+// client's BaseUrl like that of Exchange. The handshake is sent through the client's interceptors
+// and HttpClient, so authentication headers, TLS configuration, and the client's AllowedHosts apply
+// as they do to other exchanges. The given header, which may be nil, is added to the handshake.
+//
+// The ctx only bounds the handshake. The returned connection must be closed by the caller.
+func (c *Client) DialWebSocket(ctx context.Context, path string, header http.Header) (*WebSocketConn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := c.beginExchange(); err != nil {
+		return nil, err
+	}
+	defer c.endExchange()
+	reqUrl, err := c.buildReqUrl(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(reqUrl.Scheme) {
+	case "ws":
+		reqUrl.Scheme = "http"
+	case "wss":
+		reqUrl.Scheme = "https"
+	}
+	err = c.checkUrlAllowed(reqUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate websocket key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	conn := &upgradedConn{}
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, upgradedConnKey{}, conn),
+		"GET", reqUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup request: %w", err)
+	}
+	req.Header.Set(headerUserAgent, c.userAgent())
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		if resp.StatusCode < 300 {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("server did not upgrade to websocket, responded with %s", resp.Status)
+		}
+		return nil, c.buildFailedResponseError(resp)
+	}
+	if conn.rwc == nil {
+		_ = resp.Body.Close()
+		return nil, errors.New("server did not provide an upgraded connection")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		_ = conn.rwc.Close()
+		return nil, errors.New("server responded with an invalid Sec-WebSocket-Accept")
+	}
+
+	return &WebSocketConn{
+		Response: resp,
+		rwc:      conn.rwc,
+		br:       bufio.NewReader(conn.rwc),
+	}, nil
+}
+
+// captureUpgrade retains the connection of a protocol switch response for DialWebSocket
+func captureUpgrade(req *http.Request, resp *http.Response) {
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return
+	}
+	if conn, ok := req.Context().Value(upgradedConnKey{}).(*upgradedConn); ok {
+		conn.rwc, _ = resp.Body.(io.ReadWriteCloser)
+	}
+}
+
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGuid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WebSocketConn is a client connection established by Client.DialWebSocket. One goroutine may
+// read while others write.
+type WebSocketConn struct {
+	// Response is the handshake response, such as to access headers given by the server
+	Response *http.Response
+
+	rwc io.ReadWriteCloser
+	br  *bufio.Reader
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+// ReadMessage reads the next text or binary message, reassembling fragmented messages. Pings from
+// the server are answered while reading. When the server closes the connection, a
+// *WebSocketCloseError is returned.
+func (c *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			closeErr := &WebSocketCloseError{Code: 1005}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Text = string(payload[2:])
+			}
+			// echo the close, as required by the protocol, unless we initiated it
+			_ = c.writeClose(closeErr.Code, "")
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, errors.New("websocket message started before the previous one finished")
+			}
+			messageType = opcode
+		case wsOpContinuation:
+			if messageType == 0 {
+				return 0, nil, errors.New("websocket continuation frame without a message")
+			}
+		default:
+			return 0, nil, fmt.Errorf("unsupported websocket opcode %d", opcode)
+		}
+
+		data = append(data, payload...)
+		if fin {
+			return messageType, data, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single message of the given type, TextMessage or BinaryMessage
+func (c *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("unsupported websocket message type %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// Close sends a normal closure to the server and closes the underlying connection
+func (c *WebSocketConn) Close() error {
+	_ = c.writeClose(wsCloseNormal, "")
+	return c.rwc.Close()
+}
+
+func (c *WebSocketConn) writeClose(code int, text string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+	payload := make([]byte, 2, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return c.writeFrameLocked(wsOpClose, append(payload, text...))
+}
+
+func (c *WebSocketConn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= wsOpClose && (length > maxWebSocketControlPayload || !fin) {
+		return false, 0, nil, errors.New("invalid websocket control frame")
+	}
+	if length > 1<<31 {
+		return false, 0, nil, fmt.Errorf("websocket frame of %d bytes is too large", length)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *WebSocketConn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.writeFrameLocked(opcode, payload)
+}
+
+// writeFrameLocked writes a single, final frame. Frames sent by a client are always masked.
+func (c *WebSocketConn) writeFrameLocked(opcode int, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+	length := len(payload)
+	switch {
+	case length <= 125:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126, byte(length>>8), byte(length))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		frame = append(frame, 0x80|127)
+		frame = append(frame, ext[:]...)
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return fmt.Errorf("failed to generate websocket mask: %w", err)
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := c.rwc.Write(frame)
+	return err
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package restclient_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func ExampleClient_DialWebSocket() {
+	// Setup a test WebSocket server that echoes text messages
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("RECV", r.URL.Path, r.Header.Get("X-Auth-Token"))
+		echoWebSocket(w, r)
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	client.AddInterceptor(restclient.TokenAuth(restclient.AuthTokenHeader, "token1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := client.DialWebSocket(ctx, "/v1/events", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = conn.WriteMessage(restclient.TextMessage, []byte("hello"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(messageType == restclient.TextMessage, string(data))
+	// Output:
+	// RECV /v1/events token1
+	// true echo: hello
+}
+
+// echoWebSocket is a minimal WebSocket server, sufficient for the examples, that echoes a single
+// text message
+func echoWebSocket(w http.ResponseWriter, r *http.Request) {
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, _ = fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	_ = rw.Flush()
+
+	payload, err := readClientFrame(rw.Reader)
+	if err != nil {
+		return
+	}
+	reply := append([]byte("echo: "), payload...)
+	_, _ = rw.Write(append([]byte{0x81, byte(len(reply))}, reply...))
+	_ = rw.Flush()
+	// wait for the client's close
+	_, _ = readClientFrame(rw.Reader)
+}
+
+// readClientFrame reads a small, masked frame
+func readClientFrame(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	for i := range payload {
+		payload[i] ^= header[2+i%4]
+	}
+	return payload, nil
+}
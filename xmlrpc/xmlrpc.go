@@ -0,0 +1,487 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package xmlrpc calls XML-RPC endpoints with a restclient.Client, so that the client's transport,
+authentication, and other interceptors apply to the calls.
+
+The arguments and result are converted between Go values and XML-RPC values as follows:
+
+	bool                 boolean
+	int, int8...int32    int
+	int64                i8, if beyond the range of int
+	float32, float64     double
+	string               string
+	time.Time            dateTime.iso8601
+	[]byte               base64
+	slice, array         array
+	map[string]T, struct struct
+
+The members of a struct are named by the field name or by an xmlrpc field tag, where a tag of "-"
+skips the field. A result may also be decoded into an interface{}, which receives the types above,
+with []interface{} for an array and map[string]interface{} for a struct.
+
+	caller := xmlrpc.New(client, "/RPC2")
+	var sum int
+	err := caller.Call(ctx, "math.add", []interface{}{2, 3}, &sum)
+
+A fault response is returned as a *Fault.
+*/
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XmlRpcType is the content type of XML-RPC messages
+const XmlRpcType restclient.MimeType = "text/xml"
+
+// dateTimeLayout is the layout of dateTime.iso8601 values, which carry no time zone
+const dateTimeLayout = "20060102T15:04:05"
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// Client calls the methods of an XML-RPC endpoint
+type Client struct {
+	restClient *restclient.Client
+	url        string
+}
+
+// New creates a Client of the endpoint at urlIn, which is resolved like that of
+// restclient.Client.Exchange, such as "/RPC2"
+func New(restClient *restclient.Client, urlIn string) *Client {
+	return &Client{restClient: restClient, url: urlIn}
+}
+
+// Fault is the error returned when the endpoint responded with an XML-RPC fault
+type Fault struct {
+	Code   int
+	String string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("XML-RPC fault %d: %s", f.Code, f.String)
+}
+
+// Call invokes the named method with the given arguments and decodes the returned value into
+// result, which should be a pointer, or nil to discard the value. The options apply to the
+// underlying exchange.
+func (c *Client) Call(ctx context.Context, method string, args []interface{}, result interface{},
+	opts ...restclient.RequestOption) error {
+
+	body, err := EncodeMethodCall(method, args)
+	if err != nil {
+		return err
+	}
+
+	respOut := &restclient.Entity{ContentType: XmlRpcType, Content: []byte(nil)}
+	err = c.restClient.ExchangeWithContext(ctx, "POST", c.url, nil,
+		&restclient.Entity{ContentType: XmlRpcType, Content: body}, respOut, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	return DecodeMethodResponse(respOut.Content.([]byte), result)
+}
+
+// EncodeMethodCall encodes the methodCall payload of the named method with the given arguments
+func EncodeMethodCall(method string, args []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<methodCall><methodName>")
+	_ = xml.EscapeText(&buf, []byte(method))
+	buf.WriteString("</methodName><params>")
+	for i, arg := range args {
+		buf.WriteString("<param>")
+		if err := encodeValue(&buf, reflect.ValueOf(arg)); err != nil {
+			return nil, fmt.Errorf("failed to encode argument %d of %s: %w", i, method, err)
+		}
+		buf.WriteString("</param>")
+	}
+	buf.WriteString("</params></methodCall>")
+	return buf.Bytes(), nil
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return fmt.Errorf("nil is not an XML-RPC value")
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return fmt.Errorf("nil is not an XML-RPC value")
+	}
+
+	buf.WriteString("<value>")
+	switch {
+	case v.Type() == timeType:
+		fmt.Fprintf(buf, "<dateTime.iso8601>%s</dateTime.iso8601>",
+			v.Interface().(time.Time).Format(dateTimeLayout))
+	case v.Type() == bytesType:
+		fmt.Fprintf(buf, "<base64>%s</base64>", base64.StdEncoding.EncodeToString(v.Bytes()))
+	default:
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				buf.WriteString("<boolean>1</boolean>")
+			} else {
+				buf.WriteString("<boolean>0</boolean>")
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			writeInt(buf, v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Uint() > math.MaxInt64 {
+				return fmt.Errorf("%d exceeds the range of XML-RPC integers", v.Uint())
+			}
+			writeInt(buf, int64(v.Uint()))
+		case reflect.Float32, reflect.Float64:
+			fmt.Fprintf(buf, "<double>%s</double>", strconv.FormatFloat(v.Float(), 'f', -1, 64))
+		case reflect.String:
+			buf.WriteString("<string>")
+			_ = xml.EscapeText(buf, []byte(v.String()))
+			buf.WriteString("</string>")
+		case reflect.Slice, reflect.Array:
+			buf.WriteString("<array><data>")
+			for i := 0; i < v.Len(); i++ {
+				if err := encodeValue(buf, v.Index(i)); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</data></array>")
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return fmt.Errorf("map keys of %s must be strings", v.Type())
+			}
+			keys := v.MapKeys()
+			// sorts the members for a consistent encoding
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			buf.WriteString("<struct>")
+			for _, key := range keys {
+				if err := encodeMember(buf, key.String(), v.MapIndex(key)); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</struct>")
+		case reflect.Struct:
+			buf.WriteString("<struct>")
+			for i := 0; i < v.NumField(); i++ {
+				name, ok := memberName(v.Type().Field(i))
+				if !ok {
+					continue
+				}
+				if err := encodeMember(buf, name, v.Field(i)); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</struct>")
+		default:
+			return fmt.Errorf("%s is not supported by XML-RPC", v.Type())
+		}
+	}
+	buf.WriteString("</value>")
+	return nil
+}
+
+func writeInt(buf *bytes.Buffer, i int64) {
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		fmt.Fprintf(buf, "<i8>%d</i8>", i)
+	} else {
+		fmt.Fprintf(buf, "<int>%d</int>", i)
+	}
+}
+
+func encodeMember(buf *bytes.Buffer, name string, v reflect.Value) error {
+	buf.WriteString("<member><name>")
+	_ = xml.EscapeText(buf, []byte(name))
+	buf.WriteString("</name>")
+	if err := encodeValue(buf, v); err != nil {
+		return fmt.Errorf("member %s: %w", name, err)
+	}
+	buf.WriteString("</member>")
+	return nil
+}
+
+// memberName is the name of the struct member of the field, if the field is encoded
+func memberName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		// unexported
+		return "", false
+	}
+	tag := field.Tag.Get("xmlrpc")
+	if tag == "-" {
+		return "", false
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return field.Name, true
+}
+
+type methodResponse struct {
+	Params []value `xml:"params>param>value"`
+	Fault  *value  `xml:"fault>value"`
+}
+
+type value struct {
+	Int      *string `xml:"int"`
+	I4       *string `xml:"i4"`
+	I8       *string `xml:"i8"`
+	Boolean  *string `xml:"boolean"`
+	String   *string `xml:"string"`
+	Double   *string `xml:"double"`
+	DateTime *string `xml:"dateTime.iso8601"`
+	Base64   *string `xml:"base64"`
+	Array    *struct {
+		Values []value `xml:"data>value"`
+	} `xml:"array"`
+	Struct *struct {
+		Members []struct {
+			Name  string `xml:"name"`
+			Value value  `xml:"value"`
+		} `xml:"member"`
+	} `xml:"struct"`
+	Nil *struct{} `xml:"nil"`
+	// Text is the content of a value without a type, which is a string
+	Text string `xml:",chardata"`
+}
+
+// DecodeMethodResponse decodes the value of the methodResponse payload into result, which should be
+// a pointer, or nil to discard the value. A fault response is returned as a *Fault.
+func DecodeMethodResponse(content []byte, result interface{}) error {
+	var resp methodResponse
+	if err := xml.Unmarshal(content, &resp); err != nil {
+		return fmt.Errorf("failed to decode XML-RPC response: %w", err)
+	}
+
+	if resp.Fault != nil {
+		var fault struct {
+			FaultCode   int    `xmlrpc:"faultCode"`
+			FaultString string `xmlrpc:"faultString"`
+		}
+		if err := decodeValue(resp.Fault, reflect.ValueOf(&fault).Elem()); err != nil {
+			return fmt.Errorf("failed to decode XML-RPC fault: %w", err)
+		}
+		return &Fault{Code: fault.FaultCode, String: fault.FaultString}
+	}
+
+	if result == nil {
+		return nil
+	}
+	if len(resp.Params) != 1 {
+		return fmt.Errorf("XML-RPC response has %d values rather than one", len(resp.Params))
+	}
+	dst := reflect.ValueOf(result)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("result must be a non-nil pointer, but was %T", result)
+	}
+	if err := decodeValue(&resp.Params[0], dst.Elem()); err != nil {
+		return fmt.Errorf("failed to decode XML-RPC response: %w", err)
+	}
+	return nil
+}
+
+// generic converts the value into the Go type it naturally corresponds to
+func (v *value) generic() (interface{}, error) {
+	switch {
+	case v.Int != nil:
+		return parseInt(*v.Int)
+	case v.I4 != nil:
+		return parseInt(*v.I4)
+	case v.I8 != nil:
+		return strconv.ParseInt(strings.TrimSpace(*v.I8), 10, 64)
+	case v.Boolean != nil:
+		switch strings.TrimSpace(*v.Boolean) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", *v.Boolean)
+	case v.String != nil:
+		return *v.String, nil
+	case v.Double != nil:
+		return strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+	case v.DateTime != nil:
+		return parseDateTime(strings.TrimSpace(*v.DateTime))
+	case v.Base64 != nil:
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(*v.Base64), ""))
+	case v.Array != nil:
+		values := make([]interface{}, 0, len(v.Array.Values))
+		for i := range v.Array.Values {
+			element, err := v.Array.Values[i].generic()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, element)
+		}
+		return values, nil
+	case v.Struct != nil:
+		members := make(map[string]interface{}, len(v.Struct.Members))
+		for i := range v.Struct.Members {
+			member, err := v.Struct.Members[i].Value.generic()
+			if err != nil {
+				return nil, err
+			}
+			members[v.Struct.Members[i].Name] = member
+		}
+		return members, nil
+	case v.Nil != nil:
+		return nil, nil
+	default:
+		return v.Text, nil
+	}
+}
+
+func parseInt(s string) (int, error) {
+	i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	return int(i), err
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	for _, layout := range []string{dateTimeLayout, "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid dateTime.iso8601 %q", s)
+}
+
+func decodeValue(v *value, dst reflect.Value) error {
+	src, err := v.generic()
+	if err != nil {
+		return err
+	}
+	return assign(dst, src)
+}
+
+// assign sets dst from the generic value src, converting between compatible types
+func assign(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), src)
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Type().AssignableTo(dst.Type()) {
+		dst.Set(srcValue)
+		return nil
+	}
+
+	mismatch := fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch srcValue.Kind() {
+		case reflect.Int, reflect.Int64:
+			if dst.OverflowInt(srcValue.Int()) {
+				return fmt.Errorf("%d overflows %s", srcValue.Int(), dst.Type())
+			}
+			dst.SetInt(srcValue.Int())
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch srcValue.Kind() {
+		case reflect.Int, reflect.Int64:
+			if srcValue.Int() < 0 || dst.OverflowUint(uint64(srcValue.Int())) {
+				return fmt.Errorf("%d overflows %s", srcValue.Int(), dst.Type())
+			}
+			dst.SetUint(uint64(srcValue.Int()))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch srcValue.Kind() {
+		case reflect.Int, reflect.Int64:
+			dst.SetFloat(float64(srcValue.Int()))
+			return nil
+		case reflect.Float64:
+			dst.SetFloat(srcValue.Float())
+			return nil
+		}
+	case reflect.Slice:
+		elements, ok := src.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := assign(slice.Index(i), element); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Map:
+		members, ok := src.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(members))
+		for name, member := range members {
+			element := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(element, member); err != nil {
+				return fmt.Errorf("member %s: %w", name, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(name).Convert(dst.Type().Key()), element)
+		}
+		dst.Set(m)
+		return nil
+	case reflect.Struct:
+		members, ok := src.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			name, ok := memberName(dst.Type().Field(i))
+			if !ok {
+				continue
+			}
+			member, ok := members[name]
+			if !ok {
+				continue
+			}
+			if err := assign(dst.Field(i), member); err != nil {
+				return fmt.Errorf("member %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+	if srcValue.Type().ConvertibleTo(dst.Type()) && srcValue.Kind() == dst.Kind() {
+		// such as a string into a named string type
+		dst.Set(srcValue.Convert(dst.Type()))
+		return nil
+	}
+	return mismatch
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 Rackspace US, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xmlrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/racker/go-restclient"
+	"github.com/racker/go-restclient/xmlrpc"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func ExampleClient_Call() {
+	// Setup a test HTTP server that implements an XML-RPC endpoint
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml")
+		if strings.Contains(string(body), "<methodName>system.reboot</methodName>") {
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<methodResponse><fault><value><struct>
+  <member><name>faultCode</name><value><int>4</int></value></member>
+  <member><name>faultString</name><value>Permission denied</value></member>
+</struct></value></fault></methodResponse>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<methodResponse><params><param><value><struct>
+  <member><name>hostname</name><value><string>web1</string></value></member>
+  <member><name>uptime</name><value><i4>86400</i4></value></member>
+  <member><name>booted</name><value><dateTime.iso8601>20201103T08:00:00</dateTime.iso8601></value></member>
+  <member><name>roles</name><value><array><data>
+    <value>web</value><value>cache</value>
+  </data></array></value></member>
+</struct></value></param></params></methodResponse>`))
+	}))
+	defer ts.Close()
+
+	// Real example starts here
+	client := restclient.NewClient()
+	client.SetBaseUrl(ts.URL)
+	caller := xmlrpc.New(client, "/RPC2")
+
+	var status struct {
+		Hostname string        `xmlrpc:"hostname"`
+		Uptime   time.Duration `xmlrpc:"-"`
+		Seconds  int           `xmlrpc:"uptime"`
+		Booted   time.Time     `xmlrpc:"booted"`
+		Roles    []string      `xmlrpc:"roles"`
+	}
+	err := caller.Call(context.Background(), "host.status", []interface{}{"web1"}, &status)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(status.Hostname, status.Seconds, status.Booted.Format(time.RFC3339), status.Roles)
+
+	err = caller.Call(context.Background(), "system.reboot", nil, nil)
+	var fault *xmlrpc.Fault
+	fmt.Println(errors.As(err, &fault), fault.Code, err)
+	// Output:
+	// web1 86400 2020-11-03T08:00:00Z [web cache]
+	// true 4 XML-RPC fault 4: Permission denied
+}
+
+func ExampleEncodeMethodCall() {
+	content, err := xmlrpc.EncodeMethodCall("host.update", []interface{}{
+		"web1",
+		map[string]interface{}{"enabled": true, "weight": 1.5, "tags": []string{"a&b"}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.TrimPrefix(string(content), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"))
+	// Output:
+	// <methodCall><methodName>host.update</methodName><params><param><value><string>web1</string></value></param><param><value><struct><member><name>enabled</name><value><boolean>1</boolean></value></member><member><name>tags</name><value><array><data><value><string>a&amp;b</string></value></data></array></value></member><member><name>weight</name><value><double>1.5</double></value></member></struct></value></param></params></methodCall>
+}
+
+func ExampleDecodeMethodResponse() {
+	var result interface{}
+	err := xmlrpc.DecodeMethodResponse([]byte(`<methodResponse><params><param><value><array><data>
+<value><int>1</int></value><value><boolean>0</boolean></value><value><base64>aGk=</base64></value>
+</data></array></value></param></params></methodResponse>`), &result)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%#v\n", result)
+	// Output:
+	// []interface {}{1, false, []uint8{0x68, 0x69}}
+}